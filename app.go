@@ -3,9 +3,12 @@ package kratos
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -32,6 +35,8 @@ type App struct {
 	ctx      context.Context
 	cancel   func()
 	instance *registry.ServiceInstance
+	// ready is 1 once the app can serve, the process is alive regardless of it.
+	ready int32
 }
 
 // New create an application lifecycle manager.
@@ -48,6 +53,9 @@ func New(opts ...Option) *App {
 	for _, o := range opts {
 		o(&options)
 	}
+	if options.debugAddr != "" {
+		options.servers = append(options.servers[:len(options.servers):len(options.servers)], newDebugServer(options.debugAddr))
+	}
 	ctx, cancel := context.WithCancel(options.ctx)
 	return &App{
 		ctx:    ctx,
@@ -82,22 +90,43 @@ func (a *App) Run() error {
 	if err != nil {
 		return err
 	}
+	levels, err := a.startOrder()
+	if err != nil {
+		return err
+	}
 	ctx := NewContext(a.ctx, a)
 	eg, ctx := errgroup.WithContext(ctx)
-	wg := sync.WaitGroup{}
-	for _, srv := range a.opts.servers {
-		srv := srv
-		eg.Go(func() error {
-			<-ctx.Done() // wait for stop signal
-			return srv.Stop(ctx)
-		})
-		wg.Add(1)
-		eg.Go(func() error {
-			wg.Done()
-			return srv.Start(ctx)
-		})
-	}
-	wg.Wait()
+	var started int32
+	eg.Go(func() error {
+		<-ctx.Done() // wait for stop signal
+		return stopServers(ctx, levels[:atomic.LoadInt32(&started)])
+	})
+	for _, level := range levels {
+		if ctx.Err() != nil {
+			// an earlier server failed to start.
+			return eg.Wait()
+		}
+		atomic.AddInt32(&started, 1)
+		wg := sync.WaitGroup{}
+		for _, srv := range level {
+			srv := srv
+			wg.Add(1)
+			eg.Go(func() error {
+				wg.Done()
+				return srv.Start(ctx)
+			})
+		}
+		wg.Wait()
+		if len(levels) == 1 {
+			break
+		}
+		// the next level starts only once this level is up.
+		if err := waitListening(level); err != nil {
+			a.cancel()
+			_ = eg.Wait()
+			return err
+		}
+	}
 	if a.opts.registrar != nil {
 		ctx, cancel := context.WithTimeout(a.opts.ctx, a.opts.registrarTimeout)
 		defer cancel()
@@ -108,12 +137,30 @@ func (a *App) Run() error {
 	}
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, a.opts.sigs...)
+	for sig := range a.opts.reloads {
+		signal.Notify(c, sig)
+	}
+	defer signal.Stop(c)
+	if err := a.setReady(ctx); err != nil {
+		// the servers are running and the instance is registered.
+		if e := a.Stop(); e != nil {
+			a.opts.logger.Errorf("failed to app stop: %v", e)
+		}
+		_ = eg.Wait()
+		return err
+	}
 	eg.Go(func() error {
 		for {
 			select {
 			case <-ctx.Done():
 				return ctx.Err()
-			case <-c:
+			case sig := <-c:
+				if reload, ok := a.opts.reloads[sig]; ok {
+					if err := reload(); err != nil {
+						a.opts.logger.Errorf("failed to app reload: %v", err)
+					}
+					continue
+				}
 				err := a.Stop()
 				if err != nil {
 					a.opts.logger.Errorf("failed to app stop: %v", err)
@@ -127,8 +174,133 @@ func (a *App) Run() error {
 	return nil
 }
 
+// startOrder returns the servers grouped in the start order by DependsOn,
+// it is a single group of all the servers without any dependency.
+func (a *App) startOrder() ([][]transport.Server, error) {
+	if len(a.opts.dependencies) == 0 {
+		return [][]transport.Server{a.opts.servers}, nil
+	}
+	pending := make(map[transport.Server]int, len(a.opts.servers))
+	for _, srv := range a.opts.servers {
+		pending[srv] = 0
+	}
+	for srv, deps := range a.opts.dependencies {
+		if _, ok := pending[srv]; !ok {
+			return nil, fmt.Errorf("kratos: dependent server %T is not an app server", srv)
+		}
+		for _, dep := range deps {
+			if _, ok := pending[dep]; !ok {
+				return nil, fmt.Errorf("kratos: dependency server %T is not an app server", dep)
+			}
+		}
+		pending[srv] = len(deps)
+	}
+	var (
+		levels  [][]transport.Server
+		started = make(map[transport.Server]bool, len(a.opts.servers))
+	)
+	for len(started) < len(a.opts.servers) {
+		var level []transport.Server
+		for _, srv := range a.opts.servers {
+			if !started[srv] && pending[srv] == 0 {
+				level = append(level, srv)
+			}
+		}
+		if len(level) == 0 {
+			return nil, errors.New("kratos: circular server dependencies")
+		}
+		for _, srv := range level {
+			started[srv] = true
+		}
+		for srv, deps := range a.opts.dependencies {
+			for _, dep := range deps {
+				for _, s := range level {
+					if dep == s {
+						pending[srv]--
+					}
+				}
+			}
+		}
+		levels = append(levels, level)
+	}
+	return levels, nil
+}
+
+// waitListening waits until the endpoints of the servers are listening.
+func waitListening(servers []transport.Server) error {
+	for _, srv := range servers {
+		if e, ok := srv.(transport.Endpointer); ok {
+			if _, err := e.Endpoint(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// stopServers stops the servers in the reverse start order, the servers of a group are stopped concurrently.
+func stopServers(ctx context.Context, levels [][]transport.Server) error {
+	var err error
+	for i := len(levels) - 1; i >= 0; i-- {
+		eg := errgroup.Group{}
+		for _, srv := range levels[i] {
+			srv := srv
+			eg.Go(func() error {
+				return srv.Stop(ctx)
+			})
+		}
+		if e := eg.Wait(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// setReady marks the app ready once the servers are listening, and runs the ready hooks.
+func (a *App) setReady(ctx context.Context) error {
+	if err := waitListening(a.opts.servers); err != nil {
+		return err
+	}
+	for _, fn := range a.opts.beforeReady {
+		if err := fn(ctx); err != nil {
+			return err
+		}
+	}
+	atomic.StoreInt32(&a.ready, 1)
+	for _, fn := range a.opts.afterReady {
+		if err := fn(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Ready reports whether the servers are listening and the service is registered,
+// it is false again once the app is stopping.
+func (a *App) Ready() bool { return atomic.LoadInt32(&a.ready) == 1 }
+
+// ReadyHandler returns the handler of the readiness probes, which responds
+// 200 if the app is ready, otherwise 503.
+func (a *App) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.Ready() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// LiveHandler returns the handler of the liveness probes, which responds 200 as long as the process serves it.
+func (a *App) LiveHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
 // Stop gracefully stops the application.
 func (a *App) Stop() error {
+	atomic.StoreInt32(&a.ready, 0)
 	if a.opts.registrar != nil && a.instance != nil {
 		ctx, cancel := context.WithTimeout(a.opts.ctx, a.opts.registrarTimeout)
 		defer cancel()
@@ -2,7 +2,15 @@ package kratos
 
 import (
 	"context"
+	"errors"
+	nethttp "net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
 	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
 
@@ -150,3 +158,175 @@ func TestApp_Endpoint(t *testing.T) {
 		})
 	}
 }
+
+func TestApp_Ready(t *testing.T) {
+	var before, after bool
+	app := New(
+		Name("kratos"),
+		Server(http.NewServer()),
+		BeforeReady(func(context.Context) error {
+			before = true
+			return nil
+		}),
+	)
+	app.opts.afterReady = append(app.opts.afterReady, func(context.Context) error {
+		after = before && app.Ready()
+		return nil
+	})
+	assert.False(t, app.Ready())
+	probe := func() int {
+		w := httptest.NewRecorder()
+		app.ReadyHandler().ServeHTTP(w, httptest.NewRequest(nethttp.MethodGet, "/ready", nil))
+		return w.Code
+	}
+	assert.Equal(t, nethttp.StatusServiceUnavailable, probe())
+
+	done := make(chan error)
+	go func() { done <- app.Run() }()
+	assert.Eventually(t, app.Ready, time.Second, 10*time.Millisecond)
+	assert.Equal(t, nethttp.StatusOK, probe())
+
+	assert.NoError(t, app.Stop())
+	assert.False(t, app.Ready())
+	assert.NoError(t, <-done)
+	assert.True(t, after)
+
+	w := httptest.NewRecorder()
+	app.LiveHandler().ServeHTTP(w, httptest.NewRequest(nethttp.MethodGet, "/live", nil))
+	assert.Equal(t, nethttp.StatusOK, w.Code)
+}
+
+func TestApp_BeforeReadyError(t *testing.T) {
+	r := &countingRegistrar{}
+	srv := http.NewServer(http.Address("127.0.0.1:0"))
+	app := New(
+		Server(srv),
+		Registrar(r),
+		BeforeReady(func(context.Context) error {
+			return errors.New("not ready")
+		}),
+	)
+	assert.Error(t, app.Run())
+	assert.False(t, app.Ready())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&r.deregistered))
+	// the server is stopped.
+	e, err := srv.Endpoint()
+	assert.NoError(t, err)
+	_, err = nethttp.Get("http://" + e.Host)
+	assert.Error(t, err)
+}
+
+// countingRegistrar counts the deregistrations.
+type countingRegistrar struct {
+	deregistered int32
+}
+
+func (r *countingRegistrar) Register(ctx context.Context, service *registry.ServiceInstance) error {
+	return nil
+}
+
+func (r *countingRegistrar) Deregister(ctx context.Context, service *registry.ServiceInstance) error {
+	atomic.AddInt32(&r.deregistered, 1)
+	return nil
+}
+
+type orderedServer struct {
+	name    string
+	err     error
+	events  *[]string
+	lock    *sync.Mutex
+	started chan struct{}
+}
+
+func newOrderedServer(name string, events *[]string, lock *sync.Mutex) *orderedServer {
+	return &orderedServer{name: name, events: events, lock: lock, started: make(chan struct{})}
+}
+
+func (s *orderedServer) record(event string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	*s.events = append(*s.events, event+" "+s.name)
+}
+
+func (s *orderedServer) Start(ctx context.Context) error {
+	s.record("start")
+	close(s.started)
+	if s.err != nil {
+		return s.err
+	}
+	<-ctx.Done()
+	return nil
+}
+
+func (s *orderedServer) Stop(ctx context.Context) error {
+	s.record("stop")
+	return nil
+}
+
+func (s *orderedServer) Endpoint() (*url.URL, error) {
+	<-s.started
+	return &url.URL{Scheme: "test", Host: s.name}, nil
+}
+
+func TestApp_DependsOn(t *testing.T) {
+	var (
+		events []string
+		lock   sync.Mutex
+	)
+	grpcSrv := newOrderedServer("grpc", &events, &lock)
+	worker := newOrderedServer("worker", &events, &lock)
+	var app *App
+	app = New(
+		// the explicit endpoint makes the servers resolve their endpoints once started.
+		Endpoint(&url.URL{Scheme: "test", Host: "app"}),
+		Server(worker, grpcSrv),
+		DependsOn(worker, grpcSrv),
+		AfterReady(func(context.Context) error {
+			go func() { _ = app.Stop() }()
+			return nil
+		}),
+	)
+	assert.NoError(t, app.Run())
+	assert.Equal(t, []string{"start grpc", "start worker", "stop worker", "stop grpc"}, events)
+}
+
+func TestApp_DependsOnError(t *testing.T) {
+	var (
+		events []string
+		lock   sync.Mutex
+	)
+	grpcSrv := newOrderedServer("grpc", &events, &lock)
+	grpcSrv.err = errors.New("listen failed")
+	worker := newOrderedServer("worker", &events, &lock)
+	app := New(Endpoint(&url.URL{Scheme: "test", Host: "app"}), Server(worker, grpcSrv), DependsOn(worker, grpcSrv))
+	assert.Equal(t, grpcSrv.err, app.Run())
+	assert.Equal(t, []string{"start grpc", "stop grpc"}, events)
+
+	app = New(Server(worker, grpcSrv), DependsOn(worker, grpcSrv), DependsOn(grpcSrv, worker))
+	_, err := app.startOrder()
+	assert.Error(t, err)
+}
+
+func TestApp_ReloadSignal(t *testing.T) {
+	reloaded := make(chan struct{}, 1)
+	app := New(
+		Signal(syscall.SIGUSR2),
+		ReloadSignal(syscall.SIGHUP, func() error {
+			reloaded <- struct{}{}
+			return nil
+		}),
+		AfterReady(func(context.Context) error {
+			return syscall.Kill(os.Getpid(), syscall.SIGHUP)
+		}),
+	)
+	done := make(chan error)
+	go func() { done <- app.Run() }()
+	select {
+	case <-reloaded:
+	case <-time.After(time.Second):
+		t.Fatal("no reload on the signal")
+	}
+	assert.True(t, app.Ready())
+	assert.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR2))
+	assert.NoError(t, <-done)
+}
@@ -2,7 +2,9 @@ package config
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"reflect"
 	"sync"
 	"time"
@@ -21,13 +23,22 @@ var (
 	ErrNotFound = errors.New("key not found")
 	// ErrTypeAssert is type assert error.
 	ErrTypeAssert = errors.New("type assert error")
+	// ErrUnsupported is the config does not support the feature.
+	ErrUnsupported = errors.New("unsupported config")
 
-	_ Config = (*config)(nil)
+	_ Config         = (*config)(nil)
+	_ PrefixWatcher  = (*config)(nil)
+	_ Snapshotter    = (*config)(nil)
+	_ OriginReporter = (*config)(nil)
+	_ Reloader       = (*config)(nil)
 )
 
 // Observer is config observer.
 type Observer func(string, Value)
 
+// ChangeObserver is config observer of a key prefix, it receives the old and new value of the prefix.
+type ChangeObserver func(prefix string, old, new Value)
+
 // Config is a config interface.
 type Config interface {
 	Load() error
@@ -37,13 +48,59 @@ type Config interface {
 	Close() error
 }
 
+// PrefixWatcher is implemented by the configs which observe the changes of a key prefix.
+type PrefixWatcher interface {
+	WatchPrefix(prefix string, o ChangeObserver) error
+}
+
+// WatchPrefix registers the change observer of the prefix on the config,
+// it returns ErrUnsupported unless the config is a PrefixWatcher.
+func WatchPrefix(c Config, prefix string, o ChangeObserver) error {
+	if w, ok := c.(PrefixWatcher); ok {
+		return w.WatchPrefix(prefix, o)
+	}
+	return ErrUnsupported
+}
+
+// Snapshot is an immutable view of the config values,
+// the values read from a snapshot are consistent across reloads.
+type Snapshot interface {
+	Value(key string) Value
+	Scan(v interface{}) error
+}
+
+// Snapshotter is implemented by the configs which take the snapshots of their values.
+type Snapshotter interface {
+	Snapshot() Snapshot
+}
+
+// SnapshotOf returns the snapshot of the current config values,
+// it returns ErrUnsupported unless the config is a Snapshotter.
+func SnapshotOf(c Config) (Snapshot, error) {
+	if s, ok := c.(Snapshotter); ok {
+		return s.Snapshot(), nil
+	}
+	return nil, ErrUnsupported
+}
+
 type config struct {
 	opts      options
 	reader    Reader
 	cached    sync.Map
 	observers sync.Map
-	watchers  []Watcher
-	log       *log.Helper
+	// prefixes is the change observers keyed by the key prefix.
+	prefixes map[string][]ChangeObserver
+	prefixMu sync.RWMutex
+	// reload guards merging and resolving a change as a whole.
+	reload   sync.RWMutex
+	watchers []Watcher
+	log      *log.Helper
+	// schema is nil unless it is configured, schemaErr is the error of compiling it.
+	schema    *schema
+	schemaErr error
+	// handlers is the reload handlers in the registration order.
+	handlers  []ReloadHandler
+	handlerMu sync.Mutex
 }
 
 // New new a config with options.
@@ -56,11 +113,15 @@ func New(opts ...Option) Config {
 	for _, o := range opts {
 		o(&options)
 	}
-	return &config{
+	c := &config{
 		opts:   options,
 		reader: newReader(options),
 		log:    log.NewHelper(options.logger),
 	}
+	if len(options.schema) > 0 {
+		c.schema, c.schemaErr = compileSchema(options.schema)
+	}
+	return c
 }
 
 func (c *config) watch(w Watcher) {
@@ -75,18 +136,25 @@ func (c *config) watch(w Watcher) {
 			c.log.Errorf("failed to watch next config: %v", err)
 			continue
 		}
-		if err := c.reader.Merge(kvs...); err != nil {
-			c.log.Errorf("failed to merge next config: %v", err)
-			continue
-		}
-		if err := c.reader.Resolve(); err != nil {
-			c.log.Errorf("failed to resolve next config: %v", err)
+		olds := c.prefixValues()
+		if err := c.apply(kvs); err != nil {
+			c.log.Errorf("failed to apply next config: %v", err)
 			continue
 		}
+		c.notifyPrefixes(olds)
 		c.cached.Range(func(key, value interface{}) bool {
 			k := key.(string)
 			v := value.(Value)
-			if n, ok := c.reader.Value(k); ok && !reflect.DeepEqual(n.Load(), v.Load()) {
+			n, ok := c.reader.Value(k)
+			if !ok {
+				// the key is deleted, it is read again once it is set.
+				c.cached.Delete(k)
+				if o, ok := c.observers.Load(k); ok {
+					o.(Observer)(k, &errValue{err: ErrNotFound})
+				}
+				return true
+			}
+			if !reflect.DeepEqual(n.Load(), v.Load()) {
 				v.Store(n.Load())
 				if o, ok := c.observers.Load(k); ok {
 					o.(Observer)(k, v)
@@ -97,7 +165,46 @@ func (c *config) watch(w Watcher) {
 	}
 }
 
+func (c *config) apply(kvs []*KeyValue) error {
+	c.reload.Lock()
+	defer c.reload.Unlock()
+	restore := func() {}
+	handlers := c.reloadHandlers()
+	if r, ok := c.reader.(*reader); ok && (c.schema != nil || len(handlers) > 0) {
+		restore = r.checkpoint()
+	}
+	if err := c.reader.Merge(kvs...); err != nil {
+		return fmt.Errorf("merge: %w", err)
+	}
+	if err := c.reader.Resolve(); err != nil {
+		return fmt.Errorf("resolve: %w", err)
+	}
+	if err := c.validate(); err != nil {
+		// the violating config is rejected and the previous valid one is kept.
+		restore()
+		return fmt.Errorf("validate: %w", err)
+	}
+	return c.runHandlers(handlers, restore)
+}
+
+// validate validates the merged config against the schema, if any.
+func (c *config) validate() error {
+	if c.schema == nil {
+		return nil
+	}
+	data, err := c.reader.Source()
+	if err != nil {
+		return err
+	}
+	return validateSchema(c.schema, data)
+}
+
 func (c *config) Load() error {
+	c.reload.Lock()
+	defer c.reload.Unlock()
+	if c.schemaErr != nil {
+		return c.schemaErr
+	}
 	for _, src := range c.opts.sources {
 		kvs, err := src.Load()
 		if err != nil {
@@ -112,6 +219,9 @@ func (c *config) Load() error {
 			c.log.Errorf("failed to watch config source: %v", err)
 			return err
 		}
+		if c.opts.debounce > 0 {
+			w = newDebounceWatcher(w, c.opts.debounce)
+		}
 		c.watchers = append(c.watchers, w)
 		go c.watch(w)
 	}
@@ -119,6 +229,10 @@ func (c *config) Load() error {
 		c.log.Errorf("failed to resolve config source: %v", err)
 		return err
 	}
+	if err := c.validate(); err != nil {
+		c.log.Errorf("failed to validate config: %v", err)
+		return err
+	}
 	return nil
 }
 
@@ -138,7 +252,24 @@ func (c *config) Scan(v interface{}) error {
 	if err != nil {
 		return err
 	}
-	return unmarshalJSON(data, v)
+	return scan(data, v, c.opts.scanValidate)
+}
+
+// scan decodes the data to v with the default tags, and the validation if validated is true.
+func scan(data []byte, v interface{}, validated bool) error {
+	if err := setDefaults(v); err != nil {
+		return err
+	}
+	if err := unmarshalJSON(data, v); err != nil {
+		return err
+	}
+	if err := setElemDefaults(v); err != nil {
+		return err
+	}
+	if !validated {
+		return nil
+	}
+	return validate(v)
 }
 
 func (c *config) Watch(key string, o Observer) error {
@@ -149,6 +280,89 @@ func (c *config) Watch(key string, o Observer) error {
 	return nil
 }
 
+// WatchPrefix registers the observer which is called once per reload if any key
+// under the prefix changes, e.g. "logging" matches "logging.level" but not "loggingx".
+func (c *config) WatchPrefix(prefix string, o ChangeObserver) error {
+	c.prefixMu.Lock()
+	defer c.prefixMu.Unlock()
+	if c.prefixes == nil {
+		c.prefixes = make(map[string][]ChangeObserver)
+	}
+	c.prefixes[prefix] = append(c.prefixes[prefix], o)
+	return nil
+}
+
+func (c *config) lookup(key string) Value {
+	if v, ok := c.reader.Value(key); ok {
+		return v
+	}
+	return &errValue{err: ErrNotFound}
+}
+
+// prefixValues returns the current values of the watched prefixes.
+func (c *config) prefixValues() map[string]Value {
+	c.prefixMu.RLock()
+	defer c.prefixMu.RUnlock()
+	values := make(map[string]Value, len(c.prefixes))
+	for prefix := range c.prefixes {
+		values[prefix] = c.lookup(prefix)
+	}
+	return values
+}
+
+func (c *config) notifyPrefixes(olds map[string]Value) {
+	c.prefixMu.RLock()
+	prefixes := make(map[string][]ChangeObserver, len(c.prefixes))
+	for prefix, observers := range c.prefixes {
+		prefixes[prefix] = observers
+	}
+	c.prefixMu.RUnlock()
+	for prefix, observers := range prefixes {
+		old, ok := olds[prefix]
+		if !ok {
+			// the observers registered during the reload are notified from the next one.
+			continue
+		}
+		n := c.lookup(prefix)
+		if reflect.DeepEqual(old.Load(), n.Load()) {
+			continue
+		}
+		for _, o := range observers {
+			o(prefix, old, n)
+		}
+	}
+}
+
+// Origin returns the origin of the value of the leaf key, e.g. "server.http.addr",
+// which is updated when another KeyValue overrides the key on a reload.
+func (c *config) Origin(key string) (Origin, bool) {
+	if r, ok := c.reader.(*reader); ok {
+		return r.origin(key)
+	}
+	return Origin{}, false
+}
+
+// Snapshot returns an immutable view of the current config values.
+func (c *config) Snapshot() Snapshot {
+	c.reload.RLock()
+	defer c.reload.RUnlock()
+	return c.snapshot()
+}
+
+// snapshot returns the snapshot of the current values, the reload lock must be held.
+func (c *config) snapshot() Snapshot {
+	if r, ok := c.reader.(*reader); ok {
+		values, secrets := r.snapshot()
+		return &snapshot{values: values, secrets: secrets, validate: c.opts.scanValidate}
+	}
+	// the other readers are snapshotted by a copy of their source.
+	values := make(map[string]interface{})
+	if data, err := c.reader.Source(); err == nil {
+		_ = json.Unmarshal(data, &values)
+	}
+	return &snapshot{values: values, secrets: c.opts.secrets, validate: c.opts.scanValidate}
+}
+
 func (c *config) Close() error {
 	for _, w := range c.watchers {
 		if err := w.Stop(); err != nil {
@@ -157,3 +371,24 @@ func (c *config) Close() error {
 	}
 	return nil
 }
+
+type snapshot struct {
+	values   map[string]interface{}
+	secrets  []string
+	validate bool
+}
+
+func (s *snapshot) Value(key string) Value {
+	if v, ok := readValue(s.values, key); ok {
+		return maskValue(key, v, s.secrets)
+	}
+	return &errValue{err: ErrNotFound}
+}
+
+func (s *snapshot) Scan(v interface{}) error {
+	data, err := marshalJSON(s.values)
+	if err != nil {
+		return err
+	}
+	return scan(data, v, s.validate)
+}
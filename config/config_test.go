@@ -1,9 +1,11 @@
 package config
 
 import (
+	"context"
 	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/stretchr/testify/assert"
@@ -307,3 +309,166 @@ func TestDefaultResolver(t *testing.T) {
 		})
 	}
 }
+
+type testKVSource struct {
+	kvs []*KeyValue
+	ch  chan []*KeyValue
+}
+
+func (s *testKVSource) Load() ([]*KeyValue, error) { return s.kvs, nil }
+func (s *testKVSource) Watch() (Watcher, error) {
+	return &testKVWatcher{ch: s.ch, exit: make(chan struct{})}, nil
+}
+
+type testKVWatcher struct {
+	ch   chan []*KeyValue
+	exit chan struct{}
+}
+
+func (w *testKVWatcher) Next() ([]*KeyValue, error) {
+	select {
+	case kvs := <-w.ch:
+		return kvs, nil
+	case <-w.exit:
+		return nil, context.Canceled
+	}
+}
+
+func (w *testKVWatcher) Stop() error {
+	close(w.exit)
+	return nil
+}
+
+func TestWatchDeleted(t *testing.T) {
+	src := &testKVSource{
+		kvs: []*KeyValue{{Key: "server.addr", Value: []byte("0.0.0.0")}, {Key: "server.port", Value: []byte("8000")}},
+		ch:  make(chan []*KeyValue),
+	}
+	c := New(WithSource(src))
+	assert.NoError(t, c.Load())
+	defer c.Close()
+
+	deleted := make(chan error, 1)
+	assert.NoError(t, c.Watch("server.addr", func(key string, v Value) {
+		_, err := v.String()
+		deleted <- err
+	}))
+	src.ch <- []*KeyValue{{Key: "server.addr", Deleted: true}}
+	select {
+	case err := <-deleted:
+		assert.Equal(t, ErrNotFound, err)
+	case <-time.After(time.Second):
+		t.Fatal("the deletion was not dispatched")
+	}
+	_, err := c.Value("server.addr").String()
+	assert.Equal(t, ErrNotFound, err)
+	port, err := c.Value("server.port").Int()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8000), port)
+}
+
+func TestWatchPrefix(t *testing.T) {
+	src := &testKVSource{
+		kvs: []*KeyValue{{Key: "json", Format: "json", Value: []byte(`{"logging":{"level":"info"},"server":{"addr":"0.0.0.0"}}`)}},
+		ch:  make(chan []*KeyValue),
+	}
+	c := New(WithSource(src))
+	assert.NoError(t, c.Load())
+	defer c.Close()
+
+	type change struct {
+		prefix   string
+		old, new interface{}
+	}
+	changes := make(chan change, 10)
+	observer := func(prefix string, old, new Value) {
+		changes <- change{prefix, old.Load(), new.Load()}
+	}
+	assert.NoError(t, WatchPrefix(c, "logging.level", observer))
+	assert.NoError(t, WatchPrefix(c, "server", observer))
+	assert.NoError(t, WatchPrefix(c, "log", observer))
+	// the embedded config hides the PrefixWatcher implementation.
+	assert.Equal(t, ErrUnsupported, WatchPrefix(struct{ Config }{c}, "server", observer))
+
+	// both keys change in one reload.
+	src.ch <- []*KeyValue{{Key: "json", Format: "json", Value: []byte(`{"logging":{"level":"debug"},"server":{"addr":"127.0.0.1"}}`)}}
+	got := map[string]change{}
+	for i := 0; i < 2; i++ {
+		select {
+		case ch := <-changes:
+			got[ch.prefix] = ch
+		case <-time.After(time.Second):
+			t.Fatal("the change was not dispatched")
+		}
+	}
+	assert.Equal(t, change{"logging.level", "info", "debug"}, got["logging.level"])
+	assert.Equal(t, "127.0.0.1", got["server"].new.(map[string]interface{})["addr"])
+
+	// the unchanged prefixes are not notified.
+	src.ch <- []*KeyValue{{Key: "json", Format: "json", Value: []byte(`{"logging":{"level":"debug"},"server":{"addr":"127.0.0.2"}}`)}}
+	select {
+	case ch := <-changes:
+		assert.Equal(t, "server", ch.prefix)
+	case <-time.After(time.Second):
+		t.Fatal("the change was not dispatched")
+	}
+	select {
+	case ch := <-changes:
+		t.Fatalf("unexpected change: %v", ch)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	src := &testKVSource{
+		kvs: []*KeyValue{{Key: "json", Format: "json", Value: []byte(`{"server":{"host":"127.0.0.1","port":8000,"tls":false}}`)}},
+		ch:  make(chan []*KeyValue),
+	}
+	c := New(WithSource(src))
+	assert.NoError(t, c.Load())
+	defer c.Close()
+
+	reloaded := make(chan struct{})
+	assert.NoError(t, WatchPrefix(c, "server", func(string, Value, Value) { close(reloaded) }))
+	s, err := SnapshotOf(c)
+	assert.NoError(t, err)
+	src.ch <- []*KeyValue{{Key: "json", Format: "json", Value: []byte(`{"server":{"host":"127.0.0.2","port":9000,"tls":true}}`)}}
+	select {
+	case <-reloaded:
+	case <-time.After(time.Second):
+		t.Fatal("the config was not reloaded")
+	}
+
+	host, err := s.Value("server.host").String()
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", host)
+	port, err := s.Value("server.port").Int()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8000), port)
+	_, err = s.Value("server.none").Bool()
+	assert.Equal(t, ErrNotFound, err)
+
+	var server struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+		TLS  bool   `json:"tls"`
+	}
+	assert.NoError(t, s.Value("server").Scan(&server))
+	assert.Equal(t, "127.0.0.1", server.Host)
+
+	s, err = SnapshotOf(c)
+	assert.NoError(t, err)
+	tls, err := s.Value("server.tls").Bool()
+	assert.NoError(t, err)
+	assert.True(t, tls)
+
+	var conf struct {
+		Server struct {
+			Port int `json:"port"`
+		} `json:"server"`
+	}
+	assert.NoError(t, s.Scan(&conf))
+	assert.Equal(t, 9000, conf.Server.Port)
+	_, err = SnapshotOf(struct{ Config }{c})
+	assert.Equal(t, ErrUnsupported, err)
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// WithReloadDebounce with the debounce window of the reloads, the changes of a source within the window
+// since its first change are coalesced into a single reload, e.g. the several events of an editor saving a file.
+// The latest KeyValue of every key in the window is applied, so the final state of the burst wins.
+func WithReloadDebounce(d time.Duration) Option {
+	return func(o *options) {
+		o.debounce = d
+	}
+}
+
+type nextResult struct {
+	kvs []*KeyValue
+	err error
+}
+
+// debounceWatcher coalesces the changes of the watcher within the window.
+type debounceWatcher struct {
+	watcher Watcher
+	window  time.Duration
+	results chan nextResult
+	// pending is the error received within the window, which is returned by the next Next.
+	pending error
+	done    chan struct{}
+}
+
+func newDebounceWatcher(w Watcher, window time.Duration) Watcher {
+	d := &debounceWatcher{
+		watcher: w,
+		window:  window,
+		results: make(chan nextResult),
+		done:    make(chan struct{}),
+	}
+	go d.pump()
+	return d
+}
+
+func (d *debounceWatcher) pump() {
+	for {
+		kvs, err := d.watcher.Next()
+		select {
+		case d.results <- nextResult{kvs: kvs, err: err}:
+		case <-d.done:
+			return
+		}
+		if errors.Is(err, context.Canceled) {
+			return
+		}
+	}
+}
+
+func (d *debounceWatcher) Next() ([]*KeyValue, error) {
+	if err := d.pending; err != nil {
+		d.pending = nil
+		return nil, err
+	}
+	var first nextResult
+	select {
+	case first = <-d.results:
+	case <-d.done:
+		return nil, context.Canceled
+	}
+	if first.err != nil {
+		return nil, first.err
+	}
+	kvs := first.kvs
+	timer := time.NewTimer(d.window)
+	defer timer.Stop()
+	for {
+		select {
+		case r := <-d.results:
+			if r.err != nil {
+				d.pending = r.err
+				return coalesce(kvs), nil
+			}
+			kvs = append(kvs, r.kvs...)
+		case <-timer.C:
+			return coalesce(kvs), nil
+		case <-d.done:
+			return coalesce(kvs), nil
+		}
+	}
+}
+
+func (d *debounceWatcher) Stop() error {
+	select {
+	case <-d.done:
+	default:
+		close(d.done)
+	}
+	return d.watcher.Stop()
+}
+
+// coalesce keeps the latest KeyValue of every key in the order of their latest change.
+func coalesce(kvs []*KeyValue) []*KeyValue {
+	latest := make(map[string]int, len(kvs))
+	for i, kv := range kvs {
+		latest[kv.Key] = i
+	}
+	coalesced := make([]*KeyValue, 0, len(latest))
+	for i, kv := range kvs {
+		if latest[kv.Key] == i {
+			coalesced = append(coalesced, kv)
+		}
+	}
+	return coalesced
+}
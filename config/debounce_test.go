@@ -0,0 +1,63 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoalesce(t *testing.T) {
+	a1 := &KeyValue{Key: "a", Value: []byte("1")}
+	b1 := &KeyValue{Key: "b", Value: []byte("1")}
+	a2 := &KeyValue{Key: "a", Value: []byte("2")}
+	assert.Equal(t, []*KeyValue{b1, a2}, coalesce([]*KeyValue{a1, b1, a2}))
+}
+
+func TestDebounceWatcher(t *testing.T) {
+	ch := make(chan []*KeyValue)
+	w := newDebounceWatcher(&testKVWatcher{ch: ch, exit: make(chan struct{})}, 50*time.Millisecond)
+	go func() {
+		for i := 1; i <= 3; i++ {
+			ch <- []*KeyValue{{Key: "app.yaml", Format: "yaml", Value: []byte{byte('0' + i)}}}
+		}
+	}()
+	kvs, err := w.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, []*KeyValue{{Key: "app.yaml", Format: "yaml", Value: []byte("3")}}, kvs)
+
+	assert.NoError(t, w.Stop())
+	_, err = w.Next()
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestWithReloadDebounce(t *testing.T) {
+	src := &testKVSource{
+		kvs: []*KeyValue{{Key: "json", Format: "json", Value: []byte(`{"version":0}`)}},
+		ch:  make(chan []*KeyValue),
+	}
+	c := New(WithSource(src), WithReloadDebounce(50*time.Millisecond))
+	assert.NoError(t, c.Load())
+	defer c.Close()
+
+	changes := make(chan interface{}, 10)
+	assert.NoError(t, WatchPrefix(c, "version", func(prefix string, old, new Value) {
+		changes <- new.Load()
+	}))
+	for _, v := range []string{"1", "2", "3"} {
+		src.ch <- []*KeyValue{{Key: "json", Format: "json", Value: []byte(`{"version":` + v + `}`)}}
+	}
+	select {
+	case v := <-changes:
+		assert.Equal(t, float64(3), v)
+	case <-time.After(time.Second):
+		t.Fatal("the change was not dispatched")
+	}
+	select {
+	case v := <-changes:
+		t.Fatalf("unexpected change: %v", v)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -41,8 +41,9 @@ func (e *env) load(envStrings []string) []*config.KeyValue {
 
 		if len(k) != 0 {
 			kv = append(kv, &config.KeyValue{
-				Key:   k,
-				Value: []byte(v),
+				Key:    k,
+				Value:  []byte(v),
+				Source: "env",
 			})
 		}
 	}
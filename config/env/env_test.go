@@ -275,9 +275,9 @@ func Test_env_load(t *testing.T) {
 				},
 			},
 			want: []*config.KeyValue{
-				{Key: "SERVICE_NAME", Value: []byte("kratos_app"), Format: ""},
-				{Key: "ADDR", Value: []byte("192.168.0.1"), Format: ""},
-				{Key: "AGE", Value: []byte("20"), Format: ""},
+				{Key: "SERVICE_NAME", Value: []byte("kratos_app"), Format: "", Source: "env"},
+				{Key: "ADDR", Value: []byte("192.168.0.1"), Format: "", Source: "env"},
+				{Key: "AGE", Value: []byte("20"), Format: "", Source: "env"},
 			},
 		},
 
@@ -294,9 +294,9 @@ func Test_env_load(t *testing.T) {
 				},
 			},
 			want: []*config.KeyValue{
-				{Key: "_SERVICE_NAME", Value: []byte("kratos_app"), Format: ""},
-				{Key: "_ADDR", Value: []byte("192.168.0.1"), Format: ""},
-				{Key: "_AGE", Value: []byte("20"), Format: ""},
+				{Key: "_SERVICE_NAME", Value: []byte("kratos_app"), Format: "", Source: "env"},
+				{Key: "_ADDR", Value: []byte("192.168.0.1"), Format: "", Source: "env"},
+				{Key: "_AGE", Value: []byte("20"), Format: "", Source: "env"},
 			},
 		},
 
@@ -313,9 +313,9 @@ func Test_env_load(t *testing.T) {
 				},
 			},
 			want: []*config.KeyValue{
-				{Key: "SERVICE_NAME", Value: []byte("kratos_app"), Format: ""},
-				{Key: "ADDR", Value: []byte("192.168.0.1"), Format: ""},
-				{Key: "AGE", Value: []byte("20"), Format: ""},
+				{Key: "SERVICE_NAME", Value: []byte("kratos_app"), Format: "", Source: "env"},
+				{Key: "ADDR", Value: []byte("192.168.0.1"), Format: "", Source: "env"},
+				{Key: "AGE", Value: []byte("20"), Format: "", Source: "env"},
 			},
 		},
 
@@ -332,9 +332,9 @@ func Test_env_load(t *testing.T) {
 				},
 			},
 			want: []*config.KeyValue{
-				{Key: "SERVICE_NAME", Value: []byte("kratos_app"), Format: ""},
-				{Key: "ADDR", Value: []byte("192.168.0.1"), Format: ""},
-				{Key: "AGE", Value: []byte("20"), Format: ""},
+				{Key: "SERVICE_NAME", Value: []byte("kratos_app"), Format: "", Source: "env"},
+				{Key: "ADDR", Value: []byte("192.168.0.1"), Format: "", Source: "env"},
+				{Key: "AGE", Value: []byte("20"), Format: "", Source: "env"},
 			},
 		},
 
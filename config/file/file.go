@@ -38,6 +38,8 @@ func (f *file) loadFile(path string) (*config.KeyValue, error) {
 		Key:    info.Name(),
 		Format: format(info.Name()),
 		Value:  data,
+		Source: "file",
+		Path:   path,
 	}, nil
 }
 
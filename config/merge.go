@@ -0,0 +1,93 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/imdario/mergo"
+)
+
+// MergeStrategy is the strategy of merging the values of the same key in multiple sources.
+type MergeStrategy int
+
+const (
+	// MergeDeep merges the maps recursively and replaces the other values, it is the default strategy.
+	MergeDeep MergeStrategy = iota
+	// MergeReplace replaces the values of the top-level keys wholesale.
+	MergeReplace
+	// MergeAppend merges the maps recursively and appends the slices,
+	// the appended elements are deduplicated so that reloading a source doesn't repeat them.
+	MergeAppend
+)
+
+func (o options) strategy(key string) MergeStrategy {
+	if s, ok := o.merges[key]; ok {
+		return s
+	}
+	return o.merge
+}
+
+func merge(dst, src map[string]interface{}, s MergeStrategy) error {
+	switch s {
+	case MergeReplace:
+		for k, v := range src {
+			dst[k] = v
+		}
+	case MergeAppend:
+		mergeAppend(dst, src)
+	default:
+		return mergo.Map(&dst, src, mergo.WithOverride)
+	}
+	return nil
+}
+
+// deletePath removes the value of the key path, and the maps which are left empty by it.
+func deletePath(values map[string]interface{}, path string) {
+	keys := strings.SplitN(path, ".", 2)
+	if len(keys) == 1 {
+		delete(values, path)
+		return
+	}
+	if m, ok := values[keys[0]].(map[string]interface{}); ok {
+		deletePath(m, keys[1])
+		if len(m) == 0 {
+			delete(values, keys[0])
+		}
+	}
+}
+
+func mergeAppend(dst, src map[string]interface{}) {
+	for k, sv := range src {
+		switch svt := sv.(type) {
+		case map[string]interface{}:
+			if dvt, ok := dst[k].(map[string]interface{}); ok {
+				mergeAppend(dvt, svt)
+				continue
+			}
+		case []interface{}:
+			if dvt, ok := dst[k].([]interface{}); ok {
+				dst[k] = appendUnique(dvt, svt)
+				continue
+			}
+		}
+		dst[k] = sv
+	}
+}
+
+func appendUnique(dst, src []interface{}) []interface{} {
+	merged := make([]interface{}, len(dst), len(dst)+len(src))
+	copy(merged, dst)
+	for _, v := range src {
+		found := false
+		for _, e := range merged {
+			if reflect.DeepEqual(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, v)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	_testBaseYAML = `
+server:
+  addr: 0.0.0.0
+  port: 8000
+endpoints:
+  - a
+  - b
+`
+	_testOverrideYAML = `
+server:
+  port: 9000
+endpoints:
+  - b
+  - c
+`
+)
+
+func newMergeConfig(t *testing.T, opts ...Option) Config {
+	src := &testKVSource{
+		kvs: []*KeyValue{
+			{Key: "base.yaml", Format: "yaml", Value: []byte(_testBaseYAML)},
+			{Key: "override.yaml", Format: "yaml", Value: []byte(_testOverrideYAML)},
+		},
+		ch: make(chan []*KeyValue),
+	}
+	c := New(append(opts, WithSource(src))...)
+	assert.NoError(t, c.Load())
+	return c
+}
+
+func TestMergeStrategy(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []Option
+		server    map[string]interface{}
+		endpoints []interface{}
+	}{
+		{
+			name:      "deep",
+			server:    map[string]interface{}{"addr": "0.0.0.0", "port": 9000},
+			endpoints: []interface{}{"b", "c"},
+		},
+		{
+			name:      "replace",
+			opts:      []Option{WithMergeStrategy(MergeReplace)},
+			server:    map[string]interface{}{"port": 9000},
+			endpoints: []interface{}{"b", "c"},
+		},
+		{
+			name:      "append",
+			opts:      []Option{WithMergeStrategy(MergeAppend)},
+			server:    map[string]interface{}{"addr": "0.0.0.0", "port": 9000},
+			endpoints: []interface{}{"a", "b", "c"},
+		},
+		{
+			name:      "append override file",
+			opts:      []Option{WithMergeStrategy(MergeAppend, "override.yaml")},
+			server:    map[string]interface{}{"addr": "0.0.0.0", "port": 9000},
+			endpoints: []interface{}{"a", "b", "c"},
+		},
+		{
+			name:      "append base file",
+			opts:      []Option{WithMergeStrategy(MergeAppend, "base.yaml")},
+			server:    map[string]interface{}{"addr": "0.0.0.0", "port": 9000},
+			endpoints: []interface{}{"b", "c"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := newMergeConfig(t, test.opts...)
+			defer c.Close()
+			var conf struct {
+				Server    map[string]interface{} `json:"server"`
+				Endpoints []interface{}          `json:"endpoints"`
+			}
+			assert.NoError(t, c.Scan(&conf))
+			assert.Equal(t, len(test.server), len(conf.Server))
+			for k, v := range test.server {
+				assert.EqualValues(t, v, conf.Server[k])
+			}
+			assert.Equal(t, test.endpoints, conf.Endpoints)
+		})
+	}
+}
+
+func TestMergeAppendReload(t *testing.T) {
+	dst := map[string]interface{}{"a": []interface{}{"x"}}
+	src := map[string]interface{}{"a": []interface{}{"y"}}
+	assert.NoError(t, merge(dst, src, MergeAppend))
+	assert.NoError(t, merge(dst, src, MergeAppend))
+	assert.Equal(t, []interface{}{"x", "y"}, dst["a"])
+}
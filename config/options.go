@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/go-kratos/kratos/v2/encoding"
 	"github.com/go-kratos/kratos/v2/log"
@@ -23,6 +24,17 @@ type options struct {
 	decoder  Decoder
 	resolver Resolver
 	logger   log.Logger
+	merge    MergeStrategy
+	// merges is the merge strategies keyed by the KeyValue key.
+	merges map[string]MergeStrategy
+	// secrets is the key paths of the secret values.
+	secrets []string
+	// schema is the JSON Schema of the merged config.
+	schema []byte
+	// debounce is the window coalescing the changes of a source, zero disables the debounce.
+	debounce time.Duration
+	// scanValidate is whether Scan validates the scanned values.
+	scanValidate bool
 }
 
 // WithSource with config source.
@@ -50,6 +62,24 @@ func WithResolver(r Resolver) Option {
 	}
 }
 
+// WithMergeStrategy with the strategy of merging the values of the same key in multiple sources,
+// it is applied globally, or only to the KeyValues of the keys if any, e.g. the file names of the file source.
+// The later loaded KeyValues always take precedence over the earlier ones.
+func WithMergeStrategy(s MergeStrategy, keys ...string) Option {
+	return func(o *options) {
+		if len(keys) == 0 {
+			o.merge = s
+			return
+		}
+		if o.merges == nil {
+			o.merges = make(map[string]MergeStrategy, len(keys))
+		}
+		for _, k := range keys {
+			o.merges[k] = s
+		}
+	}
+}
+
 // WithLogger with config logger.
 func WithLogger(l log.Logger) Option {
 	return func(o *options) {
@@ -122,3 +152,97 @@ func defaultResolver(input map[string]interface{}) error {
 	}
 	return resolve(input)
 }
+
+// EnvResolver returns a resolver which expands the environment variable references in the string values,
+// reference format in ${ENV} or ${ENV:-default}, and $$ is an escaped $.
+// The unset references without default are left as-is, or fail the resolution if strict is true.
+func EnvResolver(strict bool) Resolver {
+	return func(input map[string]interface{}) error {
+		return resolveEnv(input, "", strict)
+	}
+}
+
+func resolveEnv(v interface{}, path string, strict bool) error {
+	switch vt := v.(type) {
+	case map[string]interface{}:
+		for k, sub := range vt {
+			key := k
+			if path != "" {
+				key = path + "." + k
+			}
+			if s, ok := sub.(string); ok {
+				expanded, err := expandEnv(s, key, strict)
+				if err != nil {
+					return err
+				}
+				vt[k] = expanded
+				continue
+			}
+			if err := resolveEnv(sub, key, strict); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i, sub := range vt {
+			key := fmt.Sprintf("%s[%d]", path, i)
+			if s, ok := sub.(string); ok {
+				expanded, err := expandEnv(s, key, strict)
+				if err != nil {
+					return err
+				}
+				vt[i] = expanded
+				continue
+			}
+			if err := resolveEnv(sub, key, strict); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func expandEnv(s, key string, strict bool) (string, error) {
+	if !strings.Contains(s, "$") {
+		return s, nil
+	}
+	var buf strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			buf.WriteByte(s[i])
+			continue
+		}
+		switch s[i+1] {
+		case '$':
+			buf.WriteByte('$')
+			i++
+			continue
+		case '{':
+		default:
+			buf.WriteByte(s[i])
+			continue
+		}
+		end := strings.IndexByte(s[i:], '}')
+		if end < 0 {
+			buf.WriteString(s[i:])
+			break
+		}
+		ref := s[i : i+end+1]
+		name, def, hasDef := ref[2:len(ref)-1], "", false
+		if idx := strings.Index(name, ":-"); idx >= 0 {
+			name, def, hasDef = name[:idx], name[idx+2:], true
+		}
+		value, ok := os.LookupEnv(name)
+		switch {
+		case ok && (value != "" || !hasDef):
+			buf.WriteString(value)
+		case hasDef:
+			buf.WriteString(def)
+		case strict:
+			return "", fmt.Errorf("config: %s: environment variable %q is not set", key, name)
+		default:
+			buf.WriteString(ref)
+		}
+		i += end
+	}
+	return buf.String(), nil
+}
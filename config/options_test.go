@@ -1,6 +1,7 @@
 package config
 
 import (
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -35,3 +36,37 @@ func TestDefaultDecoder(t *testing.T) {
 		},
 	}, target)
 }
+
+func TestEnvResolver(t *testing.T) {
+	os.Setenv("KRATOS_TEST_HOST", "127.0.0.1")
+	os.Setenv("KRATOS_TEST_EMPTY", "")
+	defer os.Unsetenv("KRATOS_TEST_HOST")
+	defer os.Unsetenv("KRATOS_TEST_EMPTY")
+
+	input := map[string]interface{}{
+		"addr":    "${KRATOS_TEST_HOST}:${KRATOS_TEST_PORT:-8000}",
+		"empty":   "${KRATOS_TEST_EMPTY:-default}",
+		"price":   "$$10 $HOME",
+		"unset":   "${KRATOS_TEST_UNSET}",
+		"partial": "${KRATOS_TEST_HOST",
+		"server": map[string]interface{}{
+			"hosts": []interface{}{"${KRATOS_TEST_HOST}", map[string]interface{}{"name": "${KRATOS_TEST_HOST}"}},
+			"port":  8000,
+		},
+	}
+	assert.NoError(t, EnvResolver(false)(input))
+	assert.Equal(t, "127.0.0.1:8000", input["addr"])
+	assert.Equal(t, "default", input["empty"])
+	assert.Equal(t, "$10 $HOME", input["price"])
+	assert.Equal(t, "${KRATOS_TEST_UNSET}", input["unset"])
+	assert.Equal(t, "${KRATOS_TEST_HOST", input["partial"])
+	server := input["server"].(map[string]interface{})
+	assert.Equal(t, "127.0.0.1", server["hosts"].([]interface{})[0])
+	assert.Equal(t, "127.0.0.1", server["hosts"].([]interface{})[1].(map[string]interface{})["name"])
+	assert.Equal(t, 8000, server["port"])
+
+	err := EnvResolver(true)(map[string]interface{}{
+		"server": map[string]interface{}{"hosts": []interface{}{"${KRATOS_TEST_UNSET}"}},
+	})
+	assert.EqualError(t, err, `config: server.hosts[0]: environment variable "KRATOS_TEST_UNSET" is not set`)
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OriginReporter is implemented by the configs which report the origins of their values.
+type OriginReporter interface {
+	Origin(key string) (Origin, bool)
+}
+
+// OriginOf returns the origin of the value of the leaf key,
+// it is false unless the config is an OriginReporter.
+func OriginOf(c Config, key string) (Origin, bool) {
+	if r, ok := c.(OriginReporter); ok {
+		return r.Origin(key)
+	}
+	return Origin{}, false
+}
+
+// Origin is the provenance of a config value, which is the KeyValue merged last for the key.
+type Origin struct {
+	// Source is the name of the source, e.g. "file" or "env", it is empty if the source doesn't report it.
+	Source string
+	// Key is the key of the KeyValue, e.g. the file name or the environment variable.
+	Key string
+	// Path is the path of the file, and Line is the line of the key in it, zero if unknown.
+	Path string
+	Line int
+}
+
+func (o Origin) String() string {
+	var b strings.Builder
+	if o.Source != "" {
+		b.WriteString(o.Source)
+		b.WriteString(" ")
+	}
+	if o.Path != "" {
+		b.WriteString(o.Path)
+	} else {
+		b.WriteString(o.Key)
+	}
+	if o.Line > 0 {
+		b.WriteString(":")
+		b.WriteString(strconv.Itoa(o.Line))
+	}
+	return b.String()
+}
+
+// setOrigins records the origins of the leaf key paths of the values decoded from the KeyValue.
+func setOrigins(origins map[string]Origin, kv *KeyValue, values map[string]interface{}) {
+	lines := keyLines(kv)
+	for _, path := range leafPaths("", values, nil) {
+		origins[path] = Origin{Source: kv.Source, Key: kv.Key, Path: kv.Path, Line: lines[path]}
+	}
+}
+
+// dropOrigins removes the origins of the key and the keys under it.
+func dropOrigins(origins map[string]Origin, key string) {
+	for path := range origins {
+		if path == key || strings.HasPrefix(path, key+".") {
+			delete(origins, path)
+		}
+	}
+}
+
+// pruneOrigins removes the origins of the key paths which are not leaves of the merged values any more.
+func pruneOrigins(origins map[string]Origin, values map[string]interface{}) {
+	for path := range origins {
+		v, ok := readValue(values, path)
+		if !ok {
+			delete(origins, path)
+			continue
+		}
+		if m, ok := v.Load().(map[string]interface{}); ok && len(m) > 0 {
+			delete(origins, path)
+		}
+	}
+}
+
+// keyLines returns the lines of the key paths of the JSON and YAML KeyValues, nil for the other formats.
+func keyLines(kv *KeyValue) map[string]int {
+	lines := make(map[string]int)
+	switch kv.Format {
+	case "yaml", "yml":
+		var doc yaml.Node
+		if err := yaml.Unmarshal(kv.Value, &doc); err != nil {
+			return nil
+		}
+		yamlLines(&doc, "", lines)
+	case "json":
+		dec := json.NewDecoder(bytes.NewReader(kv.Value))
+		if err := jsonLines(dec, kv.Value, "", lines); err != nil {
+			return nil
+		}
+	default:
+		return nil
+	}
+	return lines
+}
+
+func yamlLines(node *yaml.Node, prefix string, lines map[string]int) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, n := range node.Content {
+			yamlLines(n, prefix, lines)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			path := joinPath(prefix, node.Content[i].Value)
+			lines[path] = node.Content[i].Line
+			yamlLines(node.Content[i+1], path, lines)
+		}
+	}
+}
+
+// jsonLines reads a JSON value from dec, and records the lines of the object keys under the prefix unless lines is nil.
+func jsonLines(dec *json.Decoder, data []byte, prefix string, lines map[string]int) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case json.Delim('{'):
+		for dec.More() {
+			tok, err = dec.Token()
+			if err != nil {
+				return err
+			}
+			key, _ := tok.(string)
+			path := joinPath(prefix, key)
+			if lines != nil {
+				lines[path] = 1 + bytes.Count(data[:dec.InputOffset()], []byte("\n"))
+			}
+			if err = jsonLines(dec, data, path, lines); err != nil {
+				return err
+			}
+		}
+		_, err = dec.Token()
+	case json.Delim('['):
+		for dec.More() {
+			// the elements of the arrays are not addressable by the key paths.
+			if err = jsonLines(dec, data, prefix, nil); err != nil {
+				return err
+			}
+		}
+		_, err = dec.Token()
+	}
+	return err
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyLines(t *testing.T) {
+	yamlKV := &KeyValue{Format: "yaml", Value: []byte("server:\n  http:\n    addr: 0.0.0.0\n  grpc:\n    addr: 0.0.0.1\nname: kratos\n")}
+	assert.Equal(t, map[string]int{
+		"server": 1, "server.http": 2, "server.http.addr": 3, "server.grpc": 4, "server.grpc.addr": 5, "name": 6,
+	}, keyLines(yamlKV))
+
+	jsonKV := &KeyValue{Format: "json", Value: []byte("{\n  \"server\": {\n    \"addr\": \"0.0.0.0\",\n    \"ports\": [{\"a\": 1}]\n  },\n  \"name\": \"kratos\"\n}")}
+	assert.Equal(t, map[string]int{
+		"server": 2, "server.addr": 3, "server.ports": 4, "name": 6,
+	}, keyLines(jsonKV))
+
+	assert.Nil(t, keyLines(&KeyValue{Format: "json", Value: []byte("{")}))
+	assert.Nil(t, keyLines(&KeyValue{Key: "a.b", Value: []byte("1")}))
+}
+
+func TestOrigin(t *testing.T) {
+	file := &KeyValue{
+		Key: "config.yaml", Format: "yaml", Source: "file", Path: "/data/conf/config.yaml",
+		Value: []byte("server:\n  addr: 0.0.0.0\n  timeout: 1s\nname: kratos\n"),
+	}
+	src := &testKVSource{kvs: []*KeyValue{file}, ch: make(chan []*KeyValue)}
+	env := &testKVSource{kvs: []*KeyValue{{Key: "server.addr", Value: []byte("127.0.0.1"), Source: "env"}}, ch: make(chan []*KeyValue)}
+	c := New(WithSource(src, env))
+	assert.NoError(t, c.Load())
+	defer c.Close()
+
+	o, ok := OriginOf(c, "server.addr")
+	assert.True(t, ok)
+	assert.Equal(t, Origin{Source: "env", Key: "server.addr"}, o)
+	assert.Equal(t, "env server.addr", o.String())
+	o, ok = OriginOf(c, "server.timeout")
+	assert.True(t, ok)
+	assert.Equal(t, Origin{Source: "file", Key: "config.yaml", Path: "/data/conf/config.yaml", Line: 3}, o)
+	assert.Equal(t, "file /data/conf/config.yaml:3", o.String())
+	_, ok = OriginOf(c, "server")
+	assert.False(t, ok)
+	_, ok = OriginOf(c, "missing")
+	assert.False(t, ok)
+	_, ok = OriginOf(struct{ Config }{c}, "server.addr")
+	assert.False(t, ok)
+
+	// the reloaded file wins the key, and the removed key has no origin.
+	reloaded := *file
+	reloaded.Value = []byte("name: kratos\nserver:\n  addr: 0.0.0.2\n")
+	src.ch <- []*KeyValue{&reloaded}
+	assert.Eventually(t, func() bool {
+		o, ok := OriginOf(c, "server.addr")
+		return ok && o.Source == "file" && o.Line == 3
+	}, time.Second, 10*time.Millisecond)
+	o, ok = OriginOf(c, "server.timeout")
+	assert.True(t, ok)
+	assert.Equal(t, 3, o.Line)
+}
+
+func TestOriginReplace(t *testing.T) {
+	r := newReader(options{
+		decoder: defaultDecoder,
+		merges:  map[string]MergeStrategy{"b": MergeReplace},
+	}).(*reader)
+	assert.NoError(t, r.Merge(
+		&KeyValue{Key: "a", Format: "json", Value: []byte(`{"server":{"addr":"0.0.0.0","timeout":"1s"}}`)},
+		&KeyValue{Key: "b", Format: "json", Value: []byte(`{"server":{"addr":"127.0.0.1"}}`)},
+	))
+	o, ok := r.origin("server.addr")
+	assert.True(t, ok)
+	assert.Equal(t, "b", o.Key)
+	_, ok = r.origin("server.timeout")
+	assert.False(t, ok)
+}
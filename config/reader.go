@@ -8,7 +8,6 @@ import (
 	"strings"
 	"sync"
 
-	"github.com/imdario/mergo"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 )
@@ -24,35 +23,69 @@ type Reader interface {
 type reader struct {
 	opts   options
 	values map[string]interface{}
-	lock   sync.Mutex
+	// secrets is the key paths provided by the secret KeyValues, keyed by the KeyValue key.
+	secrets map[string][]string
+	// origins is the origins of the leaf key paths of the values.
+	origins map[string]Origin
+	lock    sync.Mutex
 }
 
 func newReader(opts options) Reader {
 	return &reader{
-		opts:   opts,
-		values: make(map[string]interface{}),
-		lock:   sync.Mutex{},
+		opts:    opts,
+		values:  make(map[string]interface{}),
+		secrets: make(map[string][]string),
+		origins: make(map[string]Origin),
+		lock:    sync.Mutex{},
 	}
 }
 
 func (r *reader) Merge(kvs ...*KeyValue) error {
 	r.lock.Lock()
 	merged, err := cloneMap(r.values)
+	origins := make(map[string]Origin, len(r.origins))
+	for k, o := range r.origins {
+		origins[k] = o
+	}
 	r.lock.Unlock()
 	if err != nil {
 		return err
 	}
+	secrets := make(map[string][]string)
 	for _, kv := range kvs {
 		next := make(map[string]interface{})
 		if err := r.opts.decoder(kv, next); err != nil {
 			return err
 		}
-		if err := mergo.Map(&merged, convertMap(next), mergo.WithOverride); err != nil {
+		values := convertMap(next).(map[string]interface{})
+		if kv.Deleted {
+			for _, path := range leafPaths("", values, nil) {
+				deletePath(merged, path)
+			}
+			secrets[kv.Key] = nil
+			continue
+		}
+		if kv.Secret {
+			secrets[kv.Key] = leafPaths("", values, nil)
+		}
+		strategy := r.opts.strategy(kv.Key)
+		if err := merge(merged, values, strategy); err != nil {
 			return err
 		}
+		if strategy == MergeReplace {
+			for k := range values {
+				dropOrigins(origins, k)
+			}
+		}
+		setOrigins(origins, kv, values)
 	}
+	pruneOrigins(origins, merged)
 	r.lock.Lock()
 	r.values = merged
+	r.origins = origins
+	for k, paths := range secrets {
+		r.secrets[k] = paths
+	}
 	r.lock.Unlock()
 	return nil
 }
@@ -60,7 +93,11 @@ func (r *reader) Merge(kvs ...*KeyValue) error {
 func (r *reader) Value(path string) (Value, bool) {
 	r.lock.Lock()
 	defer r.lock.Unlock()
-	return readValue(r.values, path)
+	v, ok := readValue(r.values, path)
+	if !ok {
+		return nil, false
+	}
+	return maskValue(path, v, r.secretPaths()), true
 }
 
 func (r *reader) Source() ([]byte, error) {
@@ -69,10 +106,62 @@ func (r *reader) Source() ([]byte, error) {
 	return marshalJSON(convertMap(r.values))
 }
 
+// Resolve resolves a copy of the values and swaps it in,
+// so that the values held by snapshots are never modified.
 func (r *reader) Resolve() error {
 	r.lock.Lock()
 	defer r.lock.Unlock()
-	return r.opts.resolver(r.values)
+	resolved, err := cloneMap(r.values)
+	if err != nil {
+		return err
+	}
+	if err = r.opts.resolver(resolved); err != nil {
+		return err
+	}
+	r.values = resolved
+	return nil
+}
+
+// origin returns the origin of the leaf key path.
+func (r *reader) origin(path string) (Origin, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	o, ok := r.origins[path]
+	return o, ok
+}
+
+// snapshot returns the current values, which must not be modified, and the secret key paths.
+func (r *reader) snapshot() (map[string]interface{}, []string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.values, r.secretPaths()
+}
+
+// checkpoint returns a func which restores the current values, secret key paths and origins.
+func (r *reader) checkpoint() func() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	values, origins := r.values, r.origins
+	secrets := make(map[string][]string, len(r.secrets))
+	for k, paths := range r.secrets {
+		secrets[k] = paths
+	}
+	return func() {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		r.values = values
+		r.secrets = secrets
+		r.origins = origins
+	}
+}
+
+// secretPaths returns the secret key paths of the options and the secret KeyValues, the lock must be held.
+func (r *reader) secretPaths() []string {
+	paths := append([]string(nil), r.opts.secrets...)
+	for _, p := range r.secrets {
+		paths = append(paths, p...)
+	}
+	return paths
 }
 
 func cloneMap(src map[string]interface{}) (map[string]interface{}, error) {
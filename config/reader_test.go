@@ -55,6 +55,31 @@ func TestReader_Merge(t *testing.T) {
 	assert.Equal(t, int64(2), vvx)
 }
 
+func TestReader_MergeDeleted(t *testing.T) {
+	r := newReader(options{decoder: defaultDecoder, resolver: defaultResolver})
+	assert.NoError(t, r.Merge(
+		&KeyValue{Key: "app.json", Value: []byte(`{"server":{"addr":"0.0.0.0","port":8000},"name":"kratos"}`), Format: "json"},
+		&KeyValue{Key: "data.driver", Value: []byte("mysql")},
+	))
+
+	// the leaf key paths of the deleted KeyValues are removed, and the maps left empty by them.
+	assert.NoError(t, r.Merge(
+		&KeyValue{Key: "app.json", Value: []byte(`{"server":{"port":8000}}`), Format: "json", Deleted: true},
+		&KeyValue{Key: "data.driver", Deleted: true},
+	))
+	_, ok := r.Value("server.port")
+	assert.False(t, ok)
+	_, ok = r.Value("data")
+	assert.False(t, ok)
+	v, ok := r.Value("server.addr")
+	assert.True(t, ok)
+	addr, err := v.String()
+	assert.NoError(t, err)
+	assert.Equal(t, "0.0.0.0", addr)
+	_, ok = r.Value("name")
+	assert.True(t, ok)
+}
+
 func TestReader_Value(t *testing.T) {
 	opts := options{
 		decoder: func(kv *KeyValue, v map[string]interface{}) error {
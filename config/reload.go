@@ -0,0 +1,83 @@
+package config
+
+import "fmt"
+
+// ReloadHandler applies a reloaded config, e.g. by scanning it into the options of a component.
+type ReloadHandler func(Config) error
+
+// Reloader is implemented by the configs which apply the reloads by the handlers.
+type Reloader interface {
+	OnReload(h ReloadHandler)
+}
+
+// OnReload registers the reload handler on the config,
+// it returns ErrUnsupported unless the config is a Reloader.
+func OnReload(c Config, h ReloadHandler) error {
+	if r, ok := c.(Reloader); ok {
+		r.OnReload(h)
+		return nil
+	}
+	return ErrUnsupported
+}
+
+// ReloadError is the error of a reload handler, the reload is rolled back to the previous config.
+type ReloadError struct {
+	// Index is the index of the failed handler in the registration order.
+	Index int
+	Err   error
+}
+
+func (e *ReloadError) Error() string {
+	return fmt.Sprintf("config: reload handler %d: %v", e.Index, e.Err)
+}
+
+func (e *ReloadError) Unwrap() error { return e.Err }
+
+// OnReload registers the handler which is called with the reloaded config on each reload, in the registration order,
+// before the observers are notified. If a handler fails, the previous config is restored, the handlers which
+// succeeded are called again with it to revert, and the *ReloadError is logged.
+func (c *config) OnReload(h ReloadHandler) {
+	c.handlerMu.Lock()
+	defer c.handlerMu.Unlock()
+	c.handlers = append(c.handlers, h)
+}
+
+func (c *config) reloadHandlers() []ReloadHandler {
+	c.handlerMu.Lock()
+	defer c.handlerMu.Unlock()
+	return c.handlers
+}
+
+// runHandlers runs the reload handlers with the reloaded config, the reload lock must be held.
+// The config is restored by restore if a handler fails.
+func (c *config) runHandlers(handlers []ReloadHandler, restore func()) error {
+	view := &reloadView{config: c}
+	for i, h := range handlers {
+		err := h(view)
+		if err == nil {
+			continue
+		}
+		restore()
+		for j := 0; j < i; j++ {
+			if e := handlers[j](view); e != nil {
+				c.log.Errorf("failed to revert config reload handler %d: %v", j, e)
+			}
+		}
+		return &ReloadError{Index: i, Err: err}
+	}
+	return nil
+}
+
+// reloadView is the config passed to the reload handlers, it reads the values being applied,
+// which are not cached yet, and is snapshotted without the reload lock.
+type reloadView struct {
+	*config
+}
+
+func (v *reloadView) Value(key string) Value {
+	return v.lookup(key)
+}
+
+func (v *reloadView) Snapshot() Snapshot {
+	return v.snapshot()
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnReload(t *testing.T) {
+	src := &testKVSource{
+		kvs: []*KeyValue{{Key: "json", Format: "json", Value: []byte(`{"server":{"addr":"0.0.0.0","port":8000}}`)}},
+		ch:  make(chan []*KeyValue),
+	}
+	c := New(WithSource(src))
+	assert.NoError(t, c.Load())
+	defer c.Close()
+	assert.NoError(t, c.Watch("server.addr", func(string, Value) {}))
+
+	type server struct {
+		Addr string `json:"addr"`
+		Port int    `json:"port"`
+	}
+	var (
+		applied  = make(chan server, 10)
+		current  server
+		failures = make(chan string, 10)
+	)
+	assert.NoError(t, OnReload(c, func(c Config) error {
+		var s server
+		if err := c.Value("server").Scan(&s); err != nil {
+			return err
+		}
+		current = s
+		applied <- s
+		return nil
+	}))
+	assert.NoError(t, OnReload(c, func(c Config) error {
+		addr, _ := c.Value("server.addr").String()
+		s, err := SnapshotOf(c)
+		if err != nil {
+			return err
+		}
+		if port, _ := s.Value("server.port").Int(); port <= 0 {
+			failures <- addr
+			return errors.New("invalid port")
+		}
+		return nil
+	}))
+	assert.Equal(t, ErrUnsupported, OnReload(struct{ Config }{c}, func(Config) error { return nil }))
+
+	src.ch <- []*KeyValue{{Key: "json", Format: "json", Value: []byte(`{"server":{"addr":"127.0.0.1","port":9000}}`)}}
+	select {
+	case s := <-applied:
+		assert.Equal(t, server{"127.0.0.1", 9000}, s)
+	case <-time.After(time.Second):
+		t.Fatal("the reload was not applied")
+	}
+
+	// the second handler fails, the config is rolled back and the first handler reverts.
+	src.ch <- []*KeyValue{{Key: "json", Format: "json", Value: []byte(`{"server":{"addr":"127.0.0.2","port":-1}}`)}}
+	select {
+	case addr := <-failures:
+		assert.Equal(t, "127.0.0.2", addr)
+	case <-time.After(time.Second):
+		t.Fatal("the reload handler was not called")
+	}
+	assert.Equal(t, server{"127.0.0.2", -1}, <-applied)
+	assert.Equal(t, server{"127.0.0.1", 9000}, <-applied)
+	assert.Equal(t, server{"127.0.0.1", 9000}, current)
+	addr, err := c.Value("server.addr").String()
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", addr)
+}
+
+func TestRunHandlers(t *testing.T) {
+	c := New().(*config)
+	var (
+		calls    []int
+		restored bool
+	)
+	cause := errors.New("bad value")
+	handlers := []ReloadHandler{
+		func(Config) error { calls = append(calls, 0); return nil },
+		func(Config) error { calls = append(calls, 1); return cause },
+		func(Config) error { calls = append(calls, 2); return nil },
+	}
+	err := c.runHandlers(handlers, func() { restored = true })
+	var re *ReloadError
+	assert.True(t, errors.As(err, &re))
+	assert.Equal(t, 1, re.Index)
+	assert.True(t, errors.Is(err, cause))
+	assert.Equal(t, "config: reload handler 1: bad value", err.Error())
+	assert.True(t, restored)
+	assert.Equal(t, []int{0, 1, 0}, calls)
+}
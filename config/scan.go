@@ -0,0 +1,267 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+type validator interface {
+	Validate() error
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// WithScanValidation with the validation of the values scanned by Scan, the `validate:"required"` fields
+// must be non-zero, and the Validate methods of the value and its nested values must succeed,
+// e.g. the messages generated by protoc-gen-validate. Scan doesn't validate the values by default.
+func WithScanValidation() Option {
+	return func(o *options) {
+		o.scanValidate = true
+	}
+}
+
+// setDefaults sets the fields to the value of their default tag, e.g. `default:"0.0.0.0:8000"`,
+// the slices are in comma separated format. The proto messages have no default tags.
+func setDefaults(v interface{}) error {
+	if _, ok := v.(proto.Message); ok {
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return nil
+	}
+	return walkDefaults(rv.Elem(), "", false)
+}
+
+// setElemDefaults sets the defaults of the zero fields of the decoded slice elements,
+// which are allocated by the decoder and missed by setDefaults.
+func setElemDefaults(v interface{}) error {
+	if _, ok := v.(proto.Message); ok {
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return nil
+	}
+	return walkDefaults(rv.Elem(), "", true)
+}
+
+func walkDefaults(v reflect.Value, path string, elems bool) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			return walkDefaults(v.Elem(), path, elems)
+		}
+	case reflect.Slice, reflect.Array:
+		if !elems {
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			elem := v.Index(i)
+			if err := walkDefaults(elem, fmt.Sprintf("%s[%d]", path, i), elems); err != nil {
+				return err
+			}
+			if elem.Kind() == reflect.Struct {
+				if err := fillDefaults(elem, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			fv := v.Field(i)
+			key := fieldPath(path, f)
+			if def, ok := f.Tag.Lookup("default"); ok && !elems {
+				if err := setField(fv, def); err != nil {
+					return fmt.Errorf("config: %s: invalid default %q: %v", key, def, err)
+				}
+				continue
+			}
+			if err := walkDefaults(fv, key, elems); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fillDefaults sets the defaults of the zero fields of the struct and its nested structs.
+func fillDefaults(v reflect.Value, path string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		fv := v.Field(i)
+		key := fieldPath(path, f)
+		if def, ok := f.Tag.Lookup("default"); ok {
+			if fv.IsZero() {
+				if err := setField(fv, def); err != nil {
+					return fmt.Errorf("config: %s: invalid default %q: %v", key, def, err)
+				}
+			}
+			continue
+		}
+		if fv.Kind() == reflect.Struct {
+			if err := fillDefaults(fv, key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func setField(v reflect.Value, s string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		parts := strings.Split(s, ",")
+		slice := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setField(slice.Index(i), strings.TrimSpace(part)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	case reflect.Ptr:
+		elem := reflect.New(v.Type().Elem())
+		if err := setField(elem.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(elem)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// validate checks the `validate:"required"` fields are non-zero, and calls the Validate method
+// of the value and its nested values, e.g. the messages generated by protoc-gen-validate.
+func validate(v interface{}) error {
+	if vv, ok := v.(validator); ok {
+		if err := vv.Validate(); err != nil {
+			return fmt.Errorf("config: %v", err)
+		}
+	}
+	if _, ok := v.(proto.Message); ok {
+		return nil
+	}
+	return walkValidate(reflect.ValueOf(v), "")
+}
+
+func walkValidate(v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			return walkValidate(v.Elem(), path)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := validateField(v.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := validateField(iter.Value(), fmt.Sprintf("%s[%v]", path, iter.Key())); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			fv := v.Field(i)
+			key := fieldPath(path, f)
+			if f.Tag.Get("validate") == "required" && fv.IsZero() {
+				return fmt.Errorf("config: %s is required", key)
+			}
+			if err := validateField(fv, key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateField(v reflect.Value, path string) error {
+	if v.CanInterface() {
+		if vv, ok := v.Interface().(validator); ok && !(v.Kind() == reflect.Ptr && v.IsNil()) {
+			if err := vv.Validate(); err != nil {
+				return fmt.Errorf("config: %s: %v", path, err)
+			}
+			if _, ok := vv.(proto.Message); ok {
+				return nil
+			}
+		} else if v.CanAddr() {
+			if vv, ok := v.Addr().Interface().(validator); ok {
+				if err := vv.Validate(); err != nil {
+					return fmt.Errorf("config: %s: %v", path, err)
+				}
+			}
+		}
+	}
+	return walkValidate(v, path)
+}
+
+// fieldPath returns the path of the field by its json name.
+func fieldPath(path string, f reflect.StructField) string {
+	name := f.Name
+	if tag := f.Tag.Get("json"); tag != "" && tag != "-" {
+		if n := strings.Split(tag, ",")[0]; n != "" {
+			name = n
+		}
+	}
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
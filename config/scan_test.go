@@ -0,0 +1,93 @@
+package config
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testScanServer struct {
+	Addr    string        `json:"addr" default:"0.0.0.0:8000"`
+	Timeout time.Duration `json:"timeout" default:"1s"`
+	Enable  bool          `json:"enable" default:"true"`
+	Name    string        `json:"name" validate:"required"`
+}
+
+type testScanConfig struct {
+	Server  testScanServer   `json:"server"`
+	Servers []testScanServer `json:"servers"`
+	Tags    []string         `json:"tags" default:"a,b"`
+	Ratio   float64          `json:"ratio" default:"0.5"`
+	Limit   *int             `json:"limit" default:"10"`
+	Data    *testScanData    `json:"data"`
+}
+
+type testScanData struct {
+	Driver string `json:"driver"`
+}
+
+func (d *testScanData) Validate() error {
+	if d.Driver != "mysql" {
+		return errors.New("unsupported driver " + d.Driver)
+	}
+	return nil
+}
+
+func newScanConfig(t *testing.T, data string, opts ...Option) Config {
+	c := New(append([]Option{WithSource(&testKVSource{
+		kvs: []*KeyValue{{Key: "json", Format: "json", Value: []byte(data)}},
+		ch:  make(chan []*KeyValue),
+	})}, opts...)...)
+	assert.NoError(t, c.Load())
+	return c
+}
+
+func TestScanDefaults(t *testing.T) {
+	c := newScanConfig(t, `{"server":{"name":"a","enable":false},"servers":[{"name":"b","addr":"127.0.0.1:9000"}]}`)
+	defer c.Close()
+
+	var conf testScanConfig
+	assert.NoError(t, c.Scan(&conf))
+	assert.Equal(t, "0.0.0.0:8000", conf.Server.Addr)
+	assert.Equal(t, time.Second, conf.Server.Timeout)
+	// the config value overrides the default.
+	assert.False(t, conf.Server.Enable)
+	assert.Equal(t, []string{"a", "b"}, conf.Tags)
+	assert.Equal(t, 0.5, conf.Ratio)
+	assert.Equal(t, 10, *conf.Limit)
+	assert.Equal(t, "127.0.0.1:9000", conf.Servers[0].Addr)
+	assert.Equal(t, time.Second, conf.Servers[0].Timeout)
+}
+
+func TestScanValidate(t *testing.T) {
+	c := newScanConfig(t, `{"server":{"name":"a"},"servers":[{"addr":"127.0.0.1:9000"}]}`, WithScanValidation())
+	defer c.Close()
+	var conf testScanConfig
+	assert.EqualError(t, c.Scan(&conf), "config: servers[0].name is required")
+	s, err := SnapshotOf(c)
+	assert.NoError(t, err)
+	assert.EqualError(t, s.Scan(&conf), "config: servers[0].name is required")
+
+	c = newScanConfig(t, `{"server":{"name":"a"},"data":{"driver":"sqlite"}}`, WithScanValidation())
+	defer c.Close()
+	conf = testScanConfig{}
+	assert.EqualError(t, c.Scan(&conf), "config: data: unsupported driver sqlite")
+
+	// the values are not validated without the option.
+	c = newScanConfig(t, `{"server":{"name":"a"},"data":{"driver":"sqlite"}}`)
+	defer c.Close()
+	conf = testScanConfig{}
+	assert.NoError(t, c.Scan(&conf))
+	assert.Equal(t, "sqlite", conf.Data.Driver)
+}
+
+func TestScanInvalidDefault(t *testing.T) {
+	c := newScanConfig(t, `{}`)
+	defer c.Close()
+	var conf struct {
+		Port int `json:"port" default:"abc"`
+	}
+	assert.Error(t, c.Scan(&conf))
+}
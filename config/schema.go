@@ -0,0 +1,294 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// WithSchema with the JSON Schema which the merged config is validated against after the load and on each reload,
+// a violating reload is rejected and the previous config is kept. The supported keywords are type, enum, const,
+// properties, required, additionalProperties, items, minItems, maxItems, minLength, maxLength, pattern,
+// minimum, maximum, exclusiveMinimum and exclusiveMaximum.
+func WithSchema(schema []byte) Option {
+	return func(o *options) {
+		o.schema = schema
+	}
+}
+
+// SchemaError is the schema violations of the config, every violation is prefixed by its key path.
+// The violations never contain the config values, which may be secret.
+type SchemaError struct {
+	Violations []string
+}
+
+func (e *SchemaError) Error() string {
+	return "config: schema: " + strings.Join(e.Violations, "; ")
+}
+
+type schema struct {
+	Types                []string
+	Enum                 []interface{}
+	Const                *interface{}
+	Properties           map[string]*schema
+	Required             []string
+	AdditionalProperties *schema
+	NoAdditional         bool
+	Items                *schema
+	MinItems, MaxItems   *int
+	MinLength, MaxLength *int
+	Pattern              *regexp.Regexp
+	Minimum, Maximum     *float64
+	ExclusiveMinimum     *float64
+	ExclusiveMaximum     *float64
+}
+
+type rawSchema struct {
+	Type                 json.RawMessage      `json:"type"`
+	Enum                 []interface{}        `json:"enum"`
+	Const                json.RawMessage      `json:"const"`
+	Properties           map[string]rawSchema `json:"properties"`
+	Required             []string             `json:"required"`
+	AdditionalProperties json.RawMessage      `json:"additionalProperties"`
+	Items                *rawSchema           `json:"items"`
+	MinItems             *int                 `json:"minItems"`
+	MaxItems             *int                 `json:"maxItems"`
+	MinLength            *int                 `json:"minLength"`
+	MaxLength            *int                 `json:"maxLength"`
+	Pattern              *string              `json:"pattern"`
+	Minimum              *float64             `json:"minimum"`
+	Maximum              *float64             `json:"maximum"`
+	ExclusiveMinimum     *float64             `json:"exclusiveMinimum"`
+	ExclusiveMaximum     *float64             `json:"exclusiveMaximum"`
+}
+
+// compileSchema parses the JSON Schema document.
+func compileSchema(data []byte) (*schema, error) {
+	var raw rawSchema
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("config: schema: %w", err)
+	}
+	s, err := raw.compile("")
+	if err != nil {
+		return nil, fmt.Errorf("config: schema: %w", err)
+	}
+	return s, nil
+}
+
+func (r *rawSchema) compile(path string) (*schema, error) {
+	s := &schema{
+		Enum:             r.Enum,
+		Required:         r.Required,
+		MinItems:         r.MinItems,
+		MaxItems:         r.MaxItems,
+		MinLength:        r.MinLength,
+		MaxLength:        r.MaxLength,
+		Minimum:          r.Minimum,
+		Maximum:          r.Maximum,
+		ExclusiveMinimum: r.ExclusiveMinimum,
+		ExclusiveMaximum: r.ExclusiveMaximum,
+	}
+	if len(r.Type) > 0 {
+		var typ string
+		if err := json.Unmarshal(r.Type, &typ); err == nil {
+			s.Types = []string{typ}
+		} else if err = json.Unmarshal(r.Type, &s.Types); err != nil {
+			return nil, fmt.Errorf("%s: invalid type", schemaPath(path))
+		}
+	}
+	if len(r.Const) > 0 {
+		var v interface{}
+		if err := json.Unmarshal(r.Const, &v); err != nil {
+			return nil, fmt.Errorf("%s: invalid const", schemaPath(path))
+		}
+		s.Const = &v
+	}
+	if r.Pattern != nil {
+		re, err := regexp.Compile(*r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid pattern: %v", schemaPath(path), err)
+		}
+		s.Pattern = re
+	}
+	if len(r.Properties) > 0 {
+		s.Properties = make(map[string]*schema, len(r.Properties))
+		for k, p := range r.Properties {
+			p := p
+			sub, err := p.compile(joinPath(path, k))
+			if err != nil {
+				return nil, err
+			}
+			s.Properties[k] = sub
+		}
+	}
+	if len(r.AdditionalProperties) > 0 {
+		var allowed bool
+		if err := json.Unmarshal(r.AdditionalProperties, &allowed); err == nil {
+			s.NoAdditional = !allowed
+		} else {
+			var sub rawSchema
+			if err = json.Unmarshal(r.AdditionalProperties, &sub); err != nil {
+				return nil, fmt.Errorf("%s: invalid additionalProperties", schemaPath(path))
+			}
+			if s.AdditionalProperties, err = sub.compile(joinPath(path, "*")); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if r.Items != nil {
+		sub, err := r.Items.compile(path + "[]")
+		if err != nil {
+			return nil, err
+		}
+		s.Items = sub
+	}
+	return s, nil
+}
+
+// validateSchema validates the JSON encoded config against the schema.
+func validateSchema(s *schema, data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	var violations []string
+	s.validate(v, "", &violations)
+	if len(violations) > 0 {
+		return &SchemaError{Violations: violations}
+	}
+	return nil
+}
+
+func (s *schema) validate(v interface{}, path string, violations *[]string) {
+	report := func(format string, args ...interface{}) {
+		*violations = append(*violations, schemaPath(path)+": "+fmt.Sprintf(format, args...))
+	}
+	if len(s.Types) > 0 && !matchType(v, s.Types) {
+		report("must be of type %s", strings.Join(s.Types, " or "))
+		return
+	}
+	if s.Const != nil && !reflect.DeepEqual(v, *s.Const) {
+		report("must be the const value")
+	}
+	if len(s.Enum) > 0 {
+		found := false
+		for _, e := range s.Enum {
+			if reflect.DeepEqual(v, e) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			report("must be one of the enum values")
+		}
+	}
+	switch vt := v.(type) {
+	case map[string]interface{}:
+		for _, k := range s.Required {
+			if _, ok := vt[k]; !ok {
+				*violations = append(*violations, schemaPath(joinPath(path, k))+": is required")
+			}
+		}
+		keys := make([]string, 0, len(vt))
+		for k := range vt {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if p, ok := s.Properties[k]; ok {
+				p.validate(vt[k], joinPath(path, k), violations)
+			} else if s.AdditionalProperties != nil {
+				s.AdditionalProperties.validate(vt[k], joinPath(path, k), violations)
+			} else if s.NoAdditional {
+				*violations = append(*violations, schemaPath(joinPath(path, k))+": is not allowed")
+			}
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(vt) < *s.MinItems {
+			report("must have at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(vt) > *s.MaxItems {
+			report("must have at most %d items", *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range vt {
+				s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i), violations)
+			}
+		}
+	case string:
+		n := utf8.RuneCountInString(vt)
+		if s.MinLength != nil && n < *s.MinLength {
+			report("must be at least %d characters long", *s.MinLength)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			report("must be at most %d characters long", *s.MaxLength)
+		}
+		if s.Pattern != nil && !s.Pattern.MatchString(vt) {
+			report("must match the pattern %q", s.Pattern.String())
+		}
+	case float64:
+		if s.Minimum != nil && vt < *s.Minimum {
+			report("must be >= %v", *s.Minimum)
+		}
+		if s.Maximum != nil && vt > *s.Maximum {
+			report("must be <= %v", *s.Maximum)
+		}
+		if s.ExclusiveMinimum != nil && vt <= *s.ExclusiveMinimum {
+			report("must be > %v", *s.ExclusiveMinimum)
+		}
+		if s.ExclusiveMaximum != nil && vt >= *s.ExclusiveMaximum {
+			report("must be < %v", *s.ExclusiveMaximum)
+		}
+	}
+}
+
+func matchType(v interface{}, types []string) bool {
+	for _, t := range types {
+		switch vt := v.(type) {
+		case nil:
+			if t == "null" {
+				return true
+			}
+		case bool:
+			if t == "boolean" {
+				return true
+			}
+		case string:
+			if t == "string" {
+				return true
+			}
+		case float64:
+			if t == "number" || (t == "integer" && vt == math.Trunc(vt)) {
+				return true
+			}
+		case []interface{}:
+			if t == "array" {
+				return true
+			}
+		case map[string]interface{}:
+			if t == "object" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func schemaPath(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+	return path
+}
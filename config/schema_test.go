@@ -0,0 +1,83 @@
+package config
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSchema = `{
+	"type": "object",
+	"required": ["server"],
+	"properties": {
+		"server": {
+			"type": "object",
+			"required": ["addr"],
+			"additionalProperties": false,
+			"properties": {
+				"addr": {"type": "string", "pattern": "^[0-9.]+:[0-9]+$"},
+				"timeout": {"type": "integer", "minimum": 1}
+			}
+		},
+		"mode": {"enum": ["debug", "release"]},
+		"hosts": {"type": "array", "minItems": 1, "items": {"type": "string", "minLength": 1}}
+	}
+}`
+
+func TestSchema(t *testing.T) {
+	s, err := compileSchema([]byte(testSchema))
+	assert.NoError(t, err)
+	assert.NoError(t, validateSchema(s, []byte(`{"server":{"addr":"0.0.0.0:8000","timeout":3},"mode":"debug","hosts":["a"]}`)))
+
+	err = validateSchema(s, []byte(`{"server":{"addr":"localhost","timeout":1.5,"port":1},"mode":"test","hosts":[""]}`))
+	var se *SchemaError
+	assert.True(t, errors.As(err, &se))
+	assert.Equal(t, []string{
+		`hosts[0]: must be at least 1 characters long`,
+		`mode: must be one of the enum values`,
+		`server.addr: must match the pattern "^[0-9.]+:[0-9]+$"`,
+		`server.port: is not allowed`,
+		`server.timeout: must be of type integer`,
+	}, se.Violations)
+
+	err = validateSchema(s, []byte(`{"hosts":[]}`))
+	assert.True(t, errors.As(err, &se))
+	assert.Equal(t, []string{"server: is required", "hosts: must have at least 1 items"}, se.Violations)
+
+	_, err = compileSchema([]byte(`{"pattern":"("}`))
+	assert.Error(t, err)
+}
+
+func TestWithSchema(t *testing.T) {
+	src := &testKVSource{
+		kvs: []*KeyValue{{Key: "json", Format: "json", Value: []byte(`{"server":{"addr":"localhost"}}`)}},
+		ch:  make(chan []*KeyValue),
+	}
+	c := New(WithSource(src), WithSchema([]byte(testSchema)))
+	var se *SchemaError
+	assert.True(t, errors.As(c.Load(), &se))
+
+	src.kvs = []*KeyValue{{Key: "json", Format: "json", Value: []byte(`{"server":{"addr":"0.0.0.0:8000"}}`)}}
+	c = New(WithSource(src), WithSchema([]byte(testSchema)))
+	assert.NoError(t, c.Load())
+	defer c.Close()
+
+	changes := make(chan [2]interface{}, 1)
+	assert.NoError(t, WatchPrefix(c, "server.addr", func(prefix string, old, new Value) {
+		changes <- [2]interface{}{old.Load(), new.Load()}
+	}))
+	// the violating reload is rejected and the previous config is kept.
+	src.ch <- []*KeyValue{{Key: "json", Format: "json", Value: []byte(`{"server":{"addr":"localhost"}}`)}}
+	src.ch <- []*KeyValue{{Key: "json", Format: "json", Value: []byte(`{"server":{"addr":"127.0.0.1:9000"}}`)}}
+	select {
+	case change := <-changes:
+		assert.Equal(t, [2]interface{}{"0.0.0.0:8000", "127.0.0.1:9000"}, change)
+	case <-time.After(time.Second):
+		t.Fatal("the change was not dispatched")
+	}
+
+	c = New(WithSchema([]byte(`{`)))
+	assert.Error(t, c.Load())
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SecretMask is the placeholder of the secret values in the String and the fmt rendering.
+const SecretMask = "******"
+
+var _ Value = (*secretValue)(nil)
+
+// WithSecretKeys with the key paths of the secret values, e.g. "data.database.password",
+// a key masks the values of its subtree whichever source provides them.
+func WithSecretKeys(keys ...string) Option {
+	return func(o *options) {
+		o.secrets = append(o.secrets, keys...)
+	}
+}
+
+// SecretSource returns a source whose values are all secret, e.g. the values of a secrets backend.
+// The keys provided by a secret source stay masked even if a later merged source overrides them,
+// so that merging secret and non-secret sources never unmasks a secret.
+func SecretSource(src Source) Source {
+	return &secretSource{Source: src}
+}
+
+type secretSource struct {
+	Source
+}
+
+func (s *secretSource) Load() ([]*KeyValue, error) {
+	kvs, err := s.Source.Load()
+	if err != nil {
+		return nil, err
+	}
+	return markSecret(kvs), nil
+}
+
+func (s *secretSource) Watch() (Watcher, error) {
+	w, err := s.Source.Watch()
+	if err != nil {
+		return nil, err
+	}
+	return &secretWatcher{Watcher: w}, nil
+}
+
+type secretWatcher struct {
+	Watcher
+}
+
+func (w *secretWatcher) Next() ([]*KeyValue, error) {
+	kvs, err := w.Watcher.Next()
+	if err != nil {
+		return nil, err
+	}
+	return markSecret(kvs), nil
+}
+
+func markSecret(kvs []*KeyValue) []*KeyValue {
+	for _, kv := range kvs {
+		kv.Secret = true
+	}
+	return kvs
+}
+
+// Secret returns the value as a string like String, but a secret value is never masked.
+func Secret(v Value) (string, error) {
+	if s, ok := v.(*secretValue); ok {
+		return s.atomicValue.String()
+	}
+	return v.String()
+}
+
+// secretValue is a value of a secret key, or a value which contains the secret keys.
+// The String and the fmt rendering are masked, Secret returns the real value.
+type secretValue struct {
+	*atomicValue
+	path    string
+	secrets []string
+}
+
+func (v *secretValue) String() (string, error) {
+	if isSecretPath(v.path, v.secrets) {
+		return SecretMask, nil
+	}
+	return v.atomicValue.String()
+}
+
+// Format renders the value with the secret values masked.
+func (v *secretValue) Format(s fmt.State, verb rune) {
+	fmt.Fprint(s, maskValues(v.path, v.Load(), v.secrets))
+}
+
+// maskValue wraps the value of the path if it is secret or contains the secret keys.
+func maskValue(path string, v Value, secrets []string) Value {
+	av, ok := v.(*atomicValue)
+	if !ok || (!isSecretPath(path, secrets) && !containsSecret(path, secrets)) {
+		return v
+	}
+	return &secretValue{atomicValue: av, path: path, secrets: secrets}
+}
+
+// isSecretPath reports whether the path is a secret key or under one.
+func isSecretPath(path string, secrets []string) bool {
+	for _, s := range secrets {
+		if path == s || strings.HasPrefix(path, s+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// containsSecret reports whether any secret key is under the path.
+func containsSecret(path string, secrets []string) bool {
+	for _, s := range secrets {
+		if strings.HasPrefix(s, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// maskValues returns a copy of the value of the path whose secret values are replaced by the mask.
+func maskValues(path string, value interface{}, secrets []string) interface{} {
+	if isSecretPath(path, secrets) {
+		return SecretMask
+	}
+	m, ok := value.(map[string]interface{})
+	if !ok || !containsSecret(path, secrets) {
+		return value
+	}
+	masked := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		masked[k] = maskValues(path+"."+k, v, secrets)
+	}
+	return masked
+}
+
+// leafPaths returns the key paths of the leaf values.
+func leafPaths(prefix string, values map[string]interface{}, paths []string) []string {
+	for k, v := range values {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		if m, ok := v.(map[string]interface{}); ok && len(m) > 0 {
+			paths = leafPaths(path, m, paths)
+			continue
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretSource(t *testing.T) {
+	base := &testKVSource{
+		kvs: []*KeyValue{{Key: "base", Format: "json", Value: []byte(`{"data":{"driver":"mysql","password":"plain"},"name":"kratos"}`)}},
+		ch:  make(chan []*KeyValue),
+	}
+	vault := &testKVSource{
+		kvs: []*KeyValue{{Key: "vault", Format: "json", Value: []byte(`{"data":{"password":"secret"}}`)}},
+		ch:  make(chan []*KeyValue),
+	}
+	override := &testKVSource{
+		kvs: []*KeyValue{{Key: "override", Format: "json", Value: []byte(`{"data":{"password":"override"},"token":"t"}`)}},
+		ch:  make(chan []*KeyValue),
+	}
+	c := New(WithSource(base, SecretSource(vault), override), WithSecretKeys("token"))
+	assert.NoError(t, c.Load())
+	defer c.Close()
+
+	// the secret key stays masked although the later source overrides it.
+	v := c.Value("data.password")
+	s, err := v.String()
+	assert.NoError(t, err)
+	assert.Equal(t, SecretMask, s)
+	s, err = Secret(v)
+	assert.NoError(t, err)
+	assert.Equal(t, "override", s)
+	assert.Equal(t, SecretMask, fmt.Sprint(v))
+	assert.Equal(t, SecretMask, fmt.Sprintf("%+v", v))
+
+	s, err = c.Value("token").String()
+	assert.NoError(t, err)
+	assert.Equal(t, SecretMask, s)
+
+	// the parent value masks its secret keys.
+	assert.Equal(t, fmt.Sprint(map[string]interface{}{"driver": "mysql", "password": SecretMask}), fmt.Sprint(c.Value("data")))
+	assert.Equal(t, "override", c.Value("data").Load().(map[string]interface{})["password"])
+
+	s, err = c.Value("name").String()
+	assert.NoError(t, err)
+	assert.Equal(t, "kratos", s)
+	s, err = Secret(c.Value("name"))
+	assert.NoError(t, err)
+	assert.Equal(t, "kratos", s)
+
+	var conf struct {
+		Data struct {
+			Password string `json:"password"`
+		} `json:"data"`
+	}
+	assert.NoError(t, c.Scan(&conf))
+	assert.Equal(t, "override", conf.Data.Password)
+
+	snap, err := SnapshotOf(c)
+	assert.NoError(t, err)
+	s, err = snap.Value("data.password").String()
+	assert.NoError(t, err)
+	assert.Equal(t, SecretMask, s)
+	s, err = Secret(snap.Value("data.password"))
+	assert.NoError(t, err)
+	assert.Equal(t, "override", s)
+
+	_, err = Secret(c.Value("missing"))
+	assert.ErrorIs(t, err, ErrNotFound)
+}
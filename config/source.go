@@ -5,6 +5,15 @@ type KeyValue struct {
 	Key    string
 	Value  []byte
 	Format string
+	// Secret reports whether the values are secret, they are masked in the String and the fmt rendering.
+	Secret bool
+	// Source is the name of the source, e.g. "file", and Path is the path of the file if any,
+	// they are reported by the Origin of the values.
+	Source string
+	Path   string
+	// Deleted reports whether the KeyValue is removed from the source,
+	// the leaf key paths of its values are removed from the merged config.
+	Deleted bool
 }
 
 // Source is config source.
@@ -0,0 +1,114 @@
+package etcd
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/encoding"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+var _ config.Source = (*source)(nil)
+
+type source struct {
+	client *clientv3.Client
+	prefix string
+
+	lock sync.Mutex
+	// kvs is the KeyValues of the last Load keyed by the etcd key, and revision is its revision,
+	// the watcher starts from them so that no change after the Load is missed.
+	kvs      map[string]*config.KeyValue
+	revision int64
+}
+
+// NewSource returns a config source of the keys under the prefix, e.g. "/kratos/helloworld/".
+// The key "/kratos/helloworld/server/addr" is the config key "server.addr", and a key with the extension
+// of a codec, e.g. "/kratos/helloworld/config.yaml", is decoded by the codec like a file.
+// The deleted keys, including the ones whose lease expired, are removed from the merged config.
+func NewSource(client *clientv3.Client, prefix string) config.Source {
+	return &source{client: client, prefix: prefix}
+}
+
+func (s *source) Load() ([]*config.KeyValue, error) {
+	kvs, revision, err := s.list(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	s.lock.Lock()
+	s.kvs, s.revision = kvs, revision
+	s.lock.Unlock()
+	return sortedValues(kvs), nil
+}
+
+func (s *source) Watch() (config.Watcher, error) {
+	s.lock.Lock()
+	loaded, revision := s.kvs, s.revision
+	s.lock.Unlock()
+	if loaded == nil {
+		var err error
+		if loaded, revision, err = s.list(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+	kvs := make(map[string]*config.KeyValue, len(loaded))
+	for k, kv := range loaded {
+		kvs[k] = kv
+	}
+	return newWatcher(s, kvs, revision), nil
+}
+
+// list returns the KeyValues under the prefix keyed by the etcd key, and the revision of the listing.
+func (s *source) list(ctx context.Context) (map[string]*config.KeyValue, int64, error) {
+	resp, err := s.client.Get(ctx, s.prefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, 0, err
+	}
+	kvs := make(map[string]*config.KeyValue, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		if v := s.keyValue(string(kv.Key), kv.Value); v != nil {
+			kvs[string(kv.Key)] = v
+		}
+	}
+	return kvs, resp.Header.Revision, nil
+}
+
+// keyValue returns the KeyValue of the etcd key, it is nil for the prefix itself.
+func (s *source) keyValue(key string, value []byte) *config.KeyValue {
+	name := strings.Trim(strings.TrimPrefix(key, s.prefix), "/")
+	if name == "" {
+		return nil
+	}
+	kv := &config.KeyValue{Value: value, Source: "etcd", Path: key}
+	if f := strings.TrimPrefix(path.Ext(name), "."); f != "" && encoding.GetCodec(f) != nil {
+		kv.Key, kv.Format = name, f
+	} else {
+		kv.Key = strings.ReplaceAll(name, "/", ".")
+	}
+	return kv
+}
+
+// sortedValues returns the KeyValues in the order of the etcd keys.
+func sortedValues(kvs map[string]*config.KeyValue) []*config.KeyValue {
+	keys := make([]string, 0, len(kvs))
+	for k := range kvs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	values := make([]*config.KeyValue, 0, len(keys))
+	for _, k := range keys {
+		values = append(values, kvs[k])
+	}
+	return values
+}
+
+// deleted returns the deleted copy of the KeyValue, which keeps the value
+// so that the key paths of a decoded key are removed.
+func deleted(kv *config.KeyValue) *config.KeyValue {
+	d := *kv
+	d.Deleted = true
+	return &d
+}
@@ -0,0 +1,161 @@
+package etcd
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/stretchr/testify/assert"
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// testKV is an in-memory etcd store which only supports the prefix Get.
+type testKV struct {
+	clientv3.KV
+	lock     sync.Mutex
+	values   map[string]string
+	revision int64
+}
+
+func (kv *testKV) Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	kv.lock.Lock()
+	defer kv.lock.Unlock()
+	resp := &clientv3.GetResponse{Header: &pb.ResponseHeader{Revision: kv.revision}}
+	for k, v := range kv.values {
+		if strings.HasPrefix(k, key) {
+			resp.Kvs = append(resp.Kvs, &mvccpb.KeyValue{Key: []byte(k), Value: []byte(v)})
+		}
+	}
+	sort.Slice(resp.Kvs, func(i, j int) bool { return string(resp.Kvs[i].Key) < string(resp.Kvs[j].Key) })
+	return resp, nil
+}
+
+func (kv *testKV) set(revision int64, values map[string]string) {
+	kv.lock.Lock()
+	defer kv.lock.Unlock()
+	kv.values, kv.revision = values, revision
+}
+
+type testWatch struct {
+	revision int64
+	ch       chan clientv3.WatchResponse
+}
+
+// testWatcher hands out the watches to the test, which sends their responses.
+type testWatcher struct {
+	clientv3.Watcher
+	watches chan testWatch
+}
+
+func (w *testWatcher) Watch(ctx context.Context, key string, opts ...clientv3.OpOption) clientv3.WatchChan {
+	ch := make(chan clientv3.WatchResponse, 1)
+	w.watches <- testWatch{revision: clientv3.OpGet(key, opts...).Rev(), ch: ch}
+	return ch
+}
+
+func newTestClient() (*clientv3.Client, *testKV, *testWatcher) {
+	kv := &testKV{values: map[string]string{
+		"/app/server/addr": "0.0.0.0",
+		"/app/server/port": "8000",
+		"/app/log.yaml":    "level: info\n",
+		"/other/name":      "other",
+	}, revision: 5}
+	w := &testWatcher{watches: make(chan testWatch, 1)}
+	return &clientv3.Client{KV: kv, Watcher: w}, kv, w
+}
+
+func put(key, value string, revision int64) *clientv3.Event {
+	return &clientv3.Event{Type: clientv3.EventTypePut, Kv: &mvccpb.KeyValue{Key: []byte(key), Value: []byte(value), ModRevision: revision}}
+}
+
+func del(key string, revision int64) *clientv3.Event {
+	return &clientv3.Event{Type: clientv3.EventTypeDelete, Kv: &mvccpb.KeyValue{Key: []byte(key), ModRevision: revision}}
+}
+
+type kv struct {
+	key, value, format string
+	deleted            bool
+}
+
+func kvsOf(kvs []*config.KeyValue) []kv {
+	var s []kv
+	for _, v := range kvs {
+		s = append(s, kv{v.Key, string(v.Value), v.Format, v.Deleted})
+	}
+	return s
+}
+
+func TestSource(t *testing.T) {
+	client, store, watcher := newTestClient()
+	s := NewSource(client, "/app/")
+	kvs, err := s.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, []kv{
+		{"log.yaml", "level: info\n", "yaml", false},
+		{"server.addr", "0.0.0.0", "", false},
+		{"server.port", "8000", "", false},
+	}, kvsOf(kvs))
+	assert.Equal(t, "etcd", kvs[0].Source)
+	assert.Equal(t, "/app/log.yaml", kvs[0].Path)
+
+	w, err := s.Watch()
+	assert.NoError(t, err)
+	// the watch starts from the revision after the Load.
+	watch := <-watcher.watches
+	assert.Equal(t, int64(6), watch.revision)
+
+	watch.ch <- clientv3.WatchResponse{Events: []*clientv3.Event{put("/app/server/port", "9000", 6), del("/app/server/addr", 7)}}
+	kvs, err = w.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, []kv{{"server.port", "9000", "", false}, {"server.addr", "0.0.0.0", "", true}}, kvsOf(kvs))
+
+	// the compacted watch lists the prefix again, the decoded key which is gone is deleted with its value.
+	store.set(10, map[string]string{"/app/server/port": "9000", "/app/name": "kratos"})
+	watch.ch <- clientv3.WatchResponse{CompactRevision: 9}
+	kvs, err = w.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, []kv{
+		{"log.yaml", "level: info\n", "yaml", true},
+		{"name", "kratos", "", false},
+		{"server.port", "9000", "", false},
+	}, kvsOf(kvs))
+	watch = <-watcher.watches
+	assert.Equal(t, int64(11), watch.revision)
+
+	assert.NoError(t, w.Stop())
+	_, err = w.Next()
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestConfig(t *testing.T) {
+	client, _, watcher := newTestClient()
+	c := config.New(config.WithSource(NewSource(client, "/app")))
+	assert.NoError(t, c.Load())
+	defer c.Close()
+	watch := <-watcher.watches
+
+	level, err := c.Value("level").String()
+	assert.NoError(t, err)
+	assert.Equal(t, "info", level)
+	port, err := c.Value("server.port").Int()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8000), port)
+
+	// the deleted keys are removed from the merged config.
+	watch.ch <- clientv3.WatchResponse{Events: []*clientv3.Event{del("/app/log.yaml", 6), del("/app/server/port", 7)}}
+	assert.Eventually(t, func() bool {
+		_, err := c.Value("server.port").Int()
+		return err == config.ErrNotFound
+	}, time.Second, 10*time.Millisecond)
+	_, err = c.Value("level").String()
+	assert.Equal(t, config.ErrNotFound, err)
+	addr, err := c.Value("server.addr").String()
+	assert.NoError(t, err)
+	assert.Equal(t, "0.0.0.0", addr)
+}
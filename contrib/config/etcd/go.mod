@@ -0,0 +1,12 @@
+module github.com/go-kratos/kratos/contrib/config/etcd/v2
+
+go 1.15
+
+require (
+	github.com/go-kratos/kratos/v2 v2.0.3
+	github.com/stretchr/testify v1.7.0
+	go.etcd.io/etcd/api/v3 v3.5.0
+	go.etcd.io/etcd/client/v3 v3.5.0
+)
+
+replace github.com/go-kratos/kratos/v2 => ../../../
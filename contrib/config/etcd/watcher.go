@@ -0,0 +1,120 @@
+package etcd
+
+import (
+	"context"
+	"sort"
+
+	"github.com/go-kratos/kratos/v2/config"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+var _ config.Watcher = (*watcher)(nil)
+
+type watcher struct {
+	source *source
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// kvs is the current KeyValues keyed by the etcd key, which the deletions and the re-listings are diffed against.
+	kvs      map[string]*config.KeyValue
+	revision int64
+	// ch is the current watch, it is nil once the watch fails and the prefix is listed again by the next Next.
+	ch   clientv3.WatchChan
+	stop context.CancelFunc
+}
+
+func newWatcher(s *source, kvs map[string]*config.KeyValue, revision int64) *watcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &watcher{source: s, ctx: ctx, cancel: cancel, kvs: kvs, revision: revision}
+	w.watch()
+	return w
+}
+
+// watch watches the prefix from the revision after the current one.
+func (w *watcher) watch() {
+	if w.stop != nil {
+		w.stop()
+	}
+	ctx, stop := context.WithCancel(w.ctx)
+	w.ch = w.source.client.Watch(ctx, w.source.prefix, clientv3.WithPrefix(), clientv3.WithRev(w.revision+1))
+	w.stop = stop
+}
+
+func (w *watcher) Next() ([]*config.KeyValue, error) {
+	for {
+		if w.ch == nil {
+			return w.relist()
+		}
+		select {
+		case <-w.ctx.Done():
+			return nil, w.ctx.Err()
+		case resp, ok := <-w.ch:
+			if !ok {
+				w.ch = nil
+				continue
+			}
+			if err := resp.Err(); err != nil {
+				w.ch = nil
+				if resp.CompactRevision != 0 {
+					// the revisions to watch are compacted, the changes are missed and the prefix is listed again.
+					continue
+				}
+				return nil, err
+			}
+			if kvs := w.apply(resp.Events); len(kvs) > 0 {
+				return kvs, nil
+			}
+		}
+	}
+}
+
+// apply applies the events to the current KeyValues, and returns the changed ones.
+func (w *watcher) apply(events []*clientv3.Event) []*config.KeyValue {
+	var kvs []*config.KeyValue
+	for _, e := range events {
+		key := string(e.Kv.Key)
+		switch e.Type {
+		case clientv3.EventTypePut:
+			if kv := w.source.keyValue(key, e.Kv.Value); kv != nil {
+				w.kvs[key] = kv
+				kvs = append(kvs, kv)
+			}
+		case clientv3.EventTypeDelete:
+			if kv, ok := w.kvs[key]; ok {
+				delete(w.kvs, key)
+				kvs = append(kvs, deleted(kv))
+			}
+		}
+		w.revision = e.Kv.ModRevision
+	}
+	return kvs
+}
+
+// relist lists the prefix again and watches it from the listing, it returns the full snapshot of the KeyValues
+// which is preceded by the deleted ones.
+func (w *watcher) relist() ([]*config.KeyValue, error) {
+	kvs, revision, err := w.source.list(w.ctx)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for k := range w.kvs {
+		if _, ok := kvs[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	changes := make([]*config.KeyValue, 0, len(keys)+len(kvs))
+	for _, k := range keys {
+		changes = append(changes, deleted(w.kvs[k]))
+	}
+	changes = append(changes, sortedValues(kvs)...)
+	w.kvs, w.revision = kvs, revision
+	w.watch()
+	return changes, nil
+}
+
+func (w *watcher) Stop() error {
+	w.cancel()
+	return nil
+}
@@ -0,0 +1,12 @@
+module github.com/go-kratos/kratos/contrib/encoding/msgpack/v2
+
+go 1.15
+
+require (
+	github.com/go-kratos/kratos/v2 v2.0.3
+	github.com/vmihailenco/msgpack/v5 v5.3.5
+	google.golang.org/grpc v1.39.1
+	google.golang.org/protobuf v1.27.1
+)
+
+replace github.com/go-kratos/kratos/v2 => ../../../
@@ -0,0 +1,46 @@
+package msgpack
+
+import (
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/vmihailenco/msgpack/v5"
+	grpcencoding "google.golang.org/grpc/encoding"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// Name is the name registered for the msgpack codec, which is the gRPC content-subtype, e.g. "application/grpc+msgpack".
+	Name = "msgpack"
+	// HTTPName is the name registered for the msgpack codec of the "application/x-msgpack" content type.
+	HTTPName = "x-msgpack"
+)
+
+func init() {
+	encoding.RegisterCodec(codec{name: Name})
+	encoding.RegisterCodec(codec{name: HTTPName})
+	grpcencoding.RegisterCodec(codec{name: Name})
+}
+
+// codec is a Codec implementation with MessagePack.
+// The proto messages are encoded as the maps of their fields by the proto reflection,
+// and the other values are encoded by msgpack.
+type codec struct {
+	name string
+}
+
+func (codec) Marshal(v interface{}) ([]byte, error) {
+	if m, ok := v.(proto.Message); ok {
+		return marshalProto(m)
+	}
+	return msgpack.Marshal(v)
+}
+
+func (codec) Unmarshal(data []byte, v interface{}) error {
+	if m, ok := v.(proto.Message); ok {
+		return unmarshalProto(data, m)
+	}
+	return msgpack.Unmarshal(data, v)
+}
+
+func (c codec) Name() string {
+	return c.name
+}
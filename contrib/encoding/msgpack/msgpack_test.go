@@ -0,0 +1,256 @@
+package msgpack
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	_ "github.com/go-kratos/kratos/v2/encoding/json"
+	_ "github.com/go-kratos/kratos/v2/encoding/xml"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/vmihailenco/msgpack/v5"
+	grpcencoding "google.golang.org/grpc/encoding"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type testEmbed struct {
+	Level1a int `json:"a"`
+	Level1b int `json:"b"`
+	Level1c int `json:"c"`
+}
+
+type testMessage struct {
+	Field1 string     `json:"a"`
+	Field2 string     `json:"b"`
+	Field3 string     `json:"c"`
+	Embed  *testEmbed `json:"embed,omitempty"`
+}
+
+func TestRoundTripWithCodecs(t *testing.T) {
+	inputs := []interface{}{
+		&testMessage{},
+		&testMessage{Field1: "a", Field2: "b", Field3: "c", Embed: &testEmbed{Level1a: 1, Level1b: -2, Level1c: 300}},
+	}
+	for _, name := range []string{"json", "xml"} {
+		other := encoding.GetCodec(name)
+		for _, in := range inputs {
+			data, err := other.Marshal(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := &testMessage{}
+			if err := other.Unmarshal(data, want); err != nil {
+				t.Fatal(err)
+			}
+			if data, err = encoding.GetCodec(Name).Marshal(in); err != nil {
+				t.Fatal(err)
+			}
+			got := &testMessage{}
+			if err := encoding.GetCodec(Name).Unmarshal(data, got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(want, got) {
+				t.Fatalf("%s: want %+v, got %+v", name, want, got)
+			}
+		}
+	}
+}
+
+func TestProtoMessage(t *testing.T) {
+	c := encoding.GetCodec(Name)
+	in := &errors.Error{Code: 404, Reason: "NOT_FOUND", Message: "not found", Metadata: map[string]string{"id": "1"}}
+	data, err := c.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &errors.Error{}
+	if err := c.Unmarshal(data, out); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(in, out) {
+		t.Fatalf("want %v, got %v", in, out)
+	}
+}
+
+func TestProtoOneof(t *testing.T) {
+	c := encoding.GetCodec(Name)
+	in, err := structpb.NewValue(map[string]interface{}{"name": "kratos", "tags": []interface{}{"a", 1.5, true, nil}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := c.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &structpb.Value{}
+	if err := c.Unmarshal(data, out); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(in, out) {
+		t.Fatalf("want %v, got %v", in, out)
+	}
+}
+
+// testItemDescriptor returns the descriptor of the message:
+//
+//	message Item {
+//	  enum Kind { UNKNOWN = 0; BOOK = 1; }
+//	  int64 id = 1;
+//	  uint64 count = 2;
+//	  sint32 delta = 3;
+//	  float ratio = 4;
+//	  bytes data = 5;
+//	  Kind kind = 6;
+//	  repeated int64 ids = 7;
+//	  map<int64, string> names = 8;
+//	  map<string, Item> children = 9;
+//	}
+func testItemDescriptor(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, label descriptorpb.FieldDescriptorProto_Label, typeName string) *descriptorpb.FieldDescriptorProto {
+		f := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Type:     typ.Enum(),
+			Label:    label.Enum(),
+		}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	optional, repeated := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL, descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+	entry := func(name string, key, value *descriptorpb.FieldDescriptorProto) *descriptorpb.DescriptorProto {
+		return &descriptorpb.DescriptorProto{
+			Name:    proto.String(name),
+			Field:   []*descriptorpb.FieldDescriptorProto{key, value},
+			Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+		}
+	}
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("item.proto"),
+		Package: proto.String("test"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Item"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64, optional, ""),
+				field("count", 2, descriptorpb.FieldDescriptorProto_TYPE_UINT64, optional, ""),
+				field("delta", 3, descriptorpb.FieldDescriptorProto_TYPE_SINT32, optional, ""),
+				field("ratio", 4, descriptorpb.FieldDescriptorProto_TYPE_FLOAT, optional, ""),
+				field("data", 5, descriptorpb.FieldDescriptorProto_TYPE_BYTES, optional, ""),
+				field("kind", 6, descriptorpb.FieldDescriptorProto_TYPE_ENUM, optional, ".test.Item.Kind"),
+				field("ids", 7, descriptorpb.FieldDescriptorProto_TYPE_INT64, repeated, ""),
+				field("names", 8, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, repeated, ".test.Item.NamesEntry"),
+				field("children", 9, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, repeated, ".test.Item.ChildrenEntry"),
+			},
+			NestedType: []*descriptorpb.DescriptorProto{
+				entry("NamesEntry",
+					field("key", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64, optional, ""),
+					field("value", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional, "")),
+				entry("ChildrenEntry",
+					field("key", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional, ""),
+					field("value", 2, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, optional, ".test.Item")),
+			},
+			EnumType: []*descriptorpb.EnumDescriptorProto{{
+				Name: proto.String("Kind"),
+				Value: []*descriptorpb.EnumValueDescriptorProto{
+					{Name: proto.String("UNKNOWN"), Number: proto.Int32(0)},
+					{Name: proto.String("BOOK"), Number: proto.Int32(1)},
+				},
+			}},
+		}},
+	}
+	fd, err := protodesc.NewFile(fdp, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fd.Messages().Get(0)
+}
+
+func TestProtoKinds(t *testing.T) {
+	md := testItemDescriptor(t)
+	in := dynamicpb.NewMessage(md)
+	if err := protojson.Unmarshal([]byte(`{
+		"id": "9007199254740993", "count": "18446744073709551615", "delta": -3, "ratio": 0.5, "data": "AAE=",
+		"kind": "BOOK", "ids": ["1", "-2"], "names": {"-1": "a", "2": "b"},
+		"children": {"x": {"id": "1", "names": {"3": "c"}}}
+	}`), in); err != nil {
+		t.Fatal(err)
+	}
+	c := encoding.GetCodec(Name)
+	data, err := c.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := dynamicpb.NewMessage(md)
+	if err := c.Unmarshal(data, out); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(in, out) {
+		t.Fatalf("want %v, got %v", in, out)
+	}
+}
+
+func TestProtoInt64(t *testing.T) {
+	data, err := encoding.GetCodec(Name).Marshal(wrapperspb.Int64(1 << 60))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the 64-bit integers are the msgpack integers rather than the strings of the JSON mapping.
+	var value map[string]interface{}
+	if err := msgpack.Unmarshal(data, &value); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := value["value"].(uint64); !ok || v != 1<<60 {
+		t.Fatalf("unexpected value %#v", value["value"])
+	}
+}
+
+func TestProtoSize(t *testing.T) {
+	in := &errors.Error{Code: 503, Reason: "SERVICE_UNAVAILABLE", Message: "service unavailable", Metadata: map[string]string{"region": "us-east-1", "retry": "3"}}
+	data, err := encoding.GetCodec(Name).Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	js, err := encoding.GetCodec("json").Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) >= len(js) {
+		t.Fatalf("the msgpack size %d is not smaller than the json size %d", len(data), len(js))
+	}
+}
+
+func TestUnmarshalError(t *testing.T) {
+	c := encoding.GetCodec(Name)
+	data, err := c.Marshal(&testMessage{Field1: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Unmarshal(data[:len(data)-1], &testMessage{}); err == nil {
+		t.Fatal("want the error of the short data")
+	}
+	if err := c.Unmarshal(data[:len(data)-1], &errors.Error{}); err == nil {
+		t.Fatal("want the error of the short data")
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	for _, name := range []string{Name, HTTPName} {
+		if c := encoding.GetCodec(name); c == nil || c.Name() != name {
+			t.Fatalf("codec %s is not registered", name)
+		}
+	}
+	if c := grpcencoding.GetCodec(Name); c == nil {
+		t.Fatal("grpc codec is not registered")
+	}
+}
@@ -0,0 +1,243 @@
+package msgpack
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// marshalProto encodes the message as a map of its populated fields keyed by their JSON names,
+// the scalars are encoded as the native msgpack values, e.g. the 64-bit integers are not strings.
+func marshalProto(m proto.Message) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	if err := encodeMessage(enc, m.ProtoReflect()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// unmarshalProto decodes the message of marshalProto, the unknown fields are discarded.
+func unmarshalProto(data []byte, m proto.Message) error {
+	proto.Reset(m)
+	return decodeMessage(msgpack.NewDecoder(bytes.NewReader(data)), m.ProtoReflect())
+}
+
+func encodeMessage(enc *msgpack.Encoder, m protoreflect.Message) error {
+	n := 0
+	m.Range(func(protoreflect.FieldDescriptor, protoreflect.Value) bool {
+		n++
+		return true
+	})
+	if err := enc.EncodeMapLen(n); err != nil {
+		return err
+	}
+	var err error
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if err = enc.EncodeString(fd.JSONName()); err != nil {
+			return false
+		}
+		err = encodeField(enc, fd, v)
+		return err == nil
+	})
+	return err
+}
+
+func encodeField(enc *msgpack.Encoder, fd protoreflect.FieldDescriptor, v protoreflect.Value) error {
+	switch {
+	case fd.IsList():
+		l := v.List()
+		if err := enc.EncodeArrayLen(l.Len()); err != nil {
+			return err
+		}
+		for i := 0; i < l.Len(); i++ {
+			if err := encodeValue(enc, fd, l.Get(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case fd.IsMap():
+		mm := v.Map()
+		if err := enc.EncodeMapLen(mm.Len()); err != nil {
+			return err
+		}
+		var err error
+		mm.Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+			if err = encodeValue(enc, fd.MapKey(), k.Value()); err != nil {
+				return false
+			}
+			err = encodeValue(enc, fd.MapValue(), v)
+			return err == nil
+		})
+		return err
+	}
+	return encodeValue(enc, fd, v)
+}
+
+func encodeValue(enc *msgpack.Encoder, fd protoreflect.FieldDescriptor, v protoreflect.Value) error {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return enc.EncodeBool(v.Bool())
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return enc.EncodeInt(v.Int())
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return enc.EncodeUint(v.Uint())
+	case protoreflect.FloatKind:
+		return enc.EncodeFloat32(float32(v.Float()))
+	case protoreflect.DoubleKind:
+		return enc.EncodeFloat64(v.Float())
+	case protoreflect.StringKind:
+		return enc.EncodeString(v.String())
+	case protoreflect.BytesKind:
+		return enc.EncodeBytes(v.Bytes())
+	case protoreflect.EnumKind:
+		return enc.EncodeInt(int64(v.Enum()))
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return encodeMessage(enc, v.Message())
+	}
+	return fmt.Errorf("msgpack: unsupported field %s kind %s", fd.FullName(), fd.Kind())
+}
+
+func decodeMessage(dec *msgpack.Decoder, m protoreflect.Message) error {
+	n, err := dec.DecodeMapLen()
+	if err != nil {
+		return err
+	}
+	fields := m.Descriptor().Fields()
+	for i := 0; i < n; i++ {
+		name, err := dec.DecodeString()
+		if err != nil {
+			return err
+		}
+		fd := fields.ByJSONName(name)
+		if fd == nil {
+			fd = fields.ByName(protoreflect.Name(name))
+		}
+		if fd == nil || isNil(dec) {
+			if err := dec.Skip(); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := decodeField(dec, m, fd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeField(dec *msgpack.Decoder, m protoreflect.Message, fd protoreflect.FieldDescriptor) error {
+	switch {
+	case fd.IsList():
+		n, err := dec.DecodeArrayLen()
+		if err != nil {
+			return err
+		}
+		l := m.Mutable(fd).List()
+		for i := 0; i < n; i++ {
+			v, err := decodeValue(dec, fd, l.NewElement)
+			if err != nil {
+				return err
+			}
+			l.Append(v)
+		}
+		return nil
+	case fd.IsMap():
+		n, err := dec.DecodeMapLen()
+		if err != nil {
+			return err
+		}
+		mm := m.Mutable(fd).Map()
+		for i := 0; i < n; i++ {
+			k, err := decodeValue(dec, fd.MapKey(), nil)
+			if err != nil {
+				return err
+			}
+			v, err := decodeValue(dec, fd.MapValue(), mm.NewValue)
+			if err != nil {
+				return err
+			}
+			mm.Set(k.MapKey(), v)
+		}
+		return nil
+	case fd.Message() != nil:
+		return decodeMessage(dec, m.Mutable(fd).Message())
+	}
+	v, err := decodeValue(dec, fd, nil)
+	if err != nil {
+		return err
+	}
+	m.Set(fd, v)
+	return nil
+}
+
+// decodeValue decodes a value of the field kind, the messages are decoded into the value of newValue.
+func decodeValue(dec *msgpack.Decoder, fd protoreflect.FieldDescriptor, newValue func() protoreflect.Value) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		v, err := dec.DecodeBool()
+		return protoreflect.ValueOfBool(v), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		v, err := dec.DecodeInt32()
+		return protoreflect.ValueOfInt32(v), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		v, err := dec.DecodeInt64()
+		return protoreflect.ValueOfInt64(v), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		v, err := dec.DecodeUint64()
+		return protoreflect.ValueOfUint32(uint32(v)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		v, err := dec.DecodeUint64()
+		return protoreflect.ValueOfUint64(v), err
+	case protoreflect.FloatKind:
+		v, err := dec.DecodeFloat64()
+		return protoreflect.ValueOfFloat32(float32(v)), err
+	case protoreflect.DoubleKind:
+		v, err := dec.DecodeFloat64()
+		return protoreflect.ValueOfFloat64(v), err
+	case protoreflect.StringKind:
+		v, err := dec.DecodeString()
+		return protoreflect.ValueOfString(v), err
+	case protoreflect.BytesKind:
+		v, err := dec.DecodeBytes()
+		return protoreflect.ValueOfBytes(v), err
+	case protoreflect.EnumKind:
+		return decodeEnum(dec, fd)
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		v := newValue()
+		return v, decodeMessage(dec, v.Message())
+	}
+	return protoreflect.Value{}, fmt.Errorf("msgpack: unsupported field %s kind %s", fd.FullName(), fd.Kind())
+}
+
+// decodeEnum decodes the number of the enum, or its name.
+func decodeEnum(dec *msgpack.Decoder, fd protoreflect.FieldDescriptor) (protoreflect.Value, error) {
+	c, err := dec.PeekCode()
+	if err != nil {
+		return protoreflect.Value{}, err
+	}
+	if !msgpcode.IsString(c) {
+		v, err := dec.DecodeInt32()
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(v)), err
+	}
+	name, err := dec.DecodeString()
+	if err != nil {
+		return protoreflect.Value{}, err
+	}
+	ev := fd.Enum().Values().ByName(protoreflect.Name(name))
+	if ev == nil {
+		return protoreflect.Value{}, fmt.Errorf("msgpack: invalid value %q of enum %s", name, fd.Enum().FullName())
+	}
+	return protoreflect.ValueOfEnum(ev.Number()), nil
+}
+
+// isNil reports whether the next value is nil, which leaves the field unset.
+func isNil(dec *msgpack.Decoder) bool {
+	c, err := dec.PeekCode()
+	return err == nil && c == msgpcode.Nil
+}
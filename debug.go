@@ -0,0 +1,114 @@
+package kratos
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+var _ transport.Server = (*debugServer)(nil)
+
+// DebugServer with the internal debug server which serves net/http/pprof under /debug/pprof/ and the Go runtime stats
+// in JSON under /debug/runtime, it starts and stops with the app. It is off by default, and the address should be
+// a loopback or internal one, e.g. "127.0.0.1:6060", since the profiles expose the internals of the process.
+func DebugServer(addr string) Option {
+	return func(o *options) { o.debugAddr = addr }
+}
+
+// debugServer is not an endpointer, so that it is neither registered nor waited for the readiness.
+type debugServer struct {
+	*http.Server
+	addr  string
+	start time.Time
+}
+
+func newDebugServer(addr string) *debugServer {
+	s := &debugServer{addr: addr, start: time.Now()}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/runtime", s.runtime)
+	s.Server = &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	return s
+}
+
+func (s *debugServer) Start(ctx context.Context) error {
+	lis, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	s.BaseContext = func(net.Listener) context.Context {
+		return ctx
+	}
+	if err = s.Serve(lis); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *debugServer) Stop(ctx context.Context) error {
+	return s.Shutdown(ctx)
+}
+
+// runtimeStats is the Go runtime stats of the process.
+type runtimeStats struct {
+	GoVersion    string  `json:"go_version"`
+	Uptime       float64 `json:"uptime_seconds"`
+	NumCPU       int     `json:"num_cpu"`
+	GOMAXPROCS   int     `json:"gomaxprocs"`
+	Goroutines   int     `json:"goroutines"`
+	CgoCalls     int64   `json:"cgo_calls"`
+	HeapAlloc    uint64  `json:"heap_alloc_bytes"`
+	HeapSys      uint64  `json:"heap_sys_bytes"`
+	HeapObjects  uint64  `json:"heap_objects"`
+	StackInuse   uint64  `json:"stack_inuse_bytes"`
+	Sys          uint64  `json:"sys_bytes"`
+	TotalAlloc   uint64  `json:"total_alloc_bytes"`
+	Mallocs      uint64  `json:"mallocs"`
+	Frees        uint64  `json:"frees"`
+	NumGC        uint32  `json:"num_gc"`
+	PauseTotal   float64 `json:"gc_pause_total_seconds"`
+	LastGC       float64 `json:"last_gc_unix_seconds"`
+	NextGC       uint64  `json:"next_gc_bytes"`
+	GCCPUPercent float64 `json:"gc_cpu_percent"`
+}
+
+func (s *debugServer) runtime(w http.ResponseWriter, r *http.Request) {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	stats := runtimeStats{
+		GoVersion:    runtime.Version(),
+		Uptime:       time.Since(s.start).Seconds(),
+		NumCPU:       runtime.NumCPU(),
+		GOMAXPROCS:   runtime.GOMAXPROCS(0),
+		Goroutines:   runtime.NumGoroutine(),
+		CgoCalls:     runtime.NumCgoCall(),
+		HeapAlloc:    ms.HeapAlloc,
+		HeapSys:      ms.HeapSys,
+		HeapObjects:  ms.HeapObjects,
+		StackInuse:   ms.StackInuse,
+		Sys:          ms.Sys,
+		TotalAlloc:   ms.TotalAlloc,
+		Mallocs:      ms.Mallocs,
+		Frees:        ms.Frees,
+		NumGC:        ms.NumGC,
+		PauseTotal:   time.Duration(ms.PauseTotalNs).Seconds(),
+		NextGC:       ms.NextGC,
+		GCCPUPercent: ms.GCCPUFraction * 100,
+	}
+	if ms.LastGC > 0 {
+		stats.LastGC = float64(ms.LastGC) / float64(time.Second)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(&stats)
+}
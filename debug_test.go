@@ -0,0 +1,61 @@
+package kratos
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	nethttp "net/http"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/transport/http"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebugServer(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	addr := lis.Addr().String()
+	lis.Close()
+
+	hs := http.NewServer()
+	app := New(Name("kratos"), Server(hs), DebugServer(addr))
+	assert.Len(t, app.opts.servers, 2)
+	done := make(chan error)
+	go func() { done <- app.Run() }()
+	assert.Eventually(t, app.Ready, time.Second, 10*time.Millisecond)
+	// the debug server is not registered.
+	instance, err := app.buildInstance()
+	assert.NoError(t, err)
+	assert.Len(t, instance.Endpoints, 1)
+
+	get := func(path string) *nethttp.Response {
+		var res *nethttp.Response
+		assert.Eventually(t, func() bool {
+			res, err = nethttp.Get(fmt.Sprintf("http://%s%s", addr, path))
+			return err == nil
+		}, time.Second, 10*time.Millisecond)
+		return res
+	}
+	res := get("/debug/pprof/")
+	res.Body.Close()
+	assert.Equal(t, nethttp.StatusOK, res.StatusCode)
+
+	res = get("/debug/runtime")
+	var stats runtimeStats
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&stats))
+	res.Body.Close()
+	assert.Equal(t, "application/json", res.Header.Get("Content-Type"))
+	assert.True(t, stats.Goroutines > 0)
+	assert.True(t, stats.HeapAlloc > 0)
+
+	assert.NoError(t, app.Stop())
+	assert.NoError(t, <-done)
+	_, err = nethttp.Get(fmt.Sprintf("http://%s/debug/runtime", addr))
+	assert.Error(t, err)
+}
+
+func TestDebugServerDisabled(t *testing.T) {
+	app := New(Name("kratos"), Server(http.NewServer()))
+	assert.Len(t, app.opts.servers, 1)
+}
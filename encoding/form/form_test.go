@@ -1,11 +1,15 @@
 package form
 
 import (
+	"net/url"
 	"testing"
 
 	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/internal/testproto/complex"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
 )
 
 type LoginRequest struct {
@@ -70,3 +74,47 @@ func TestProtoEncodeDecode(t *testing.T) {
 	require.Equal(t, "3344", in2.Simples[0])
 	require.Equal(t, "5566", in2.Simples[1])
 }
+
+func TestProtoNestedAndRepeated(t *testing.T) {
+	in := &annotations.HttpRule{
+		Selector: "kratos.v1.Greeter.SayHello",
+		Pattern:  &annotations.HttpRule_Get{Get: "/hello/{name}"},
+		AdditionalBindings: []*annotations.HttpRule{
+			{Pattern: &annotations.HttpRule_Post{Post: "/hello"}, Body: "*"},
+			{Pattern: &annotations.HttpRule_Custom{Custom: &annotations.CustomHttpPattern{Kind: "HEAD", Path: "/hello"}}},
+		},
+	}
+	content, err := encoding.GetCodec(contentType).Marshal(in)
+	require.NoError(t, err)
+	vs, err := url.ParseQuery(string(content))
+	require.NoError(t, err)
+	require.Equal(t, "/hello/{name}", vs.Get("get"))
+	require.Equal(t, "/hello", vs.Get("additionalBindings[0].post"))
+	require.Equal(t, "HEAD", vs.Get("additionalBindings[1].custom.kind"))
+	require.NotContains(t, vs, "put")
+
+	out := &annotations.HttpRule{}
+	require.NoError(t, encoding.GetCodec(contentType).Unmarshal(content, out))
+	require.True(t, proto.Equal(in, out), "want %v, got %v", in, out)
+
+	out = &annotations.HttpRule{}
+	content = []byte("selector=a.b&additionalBindings[1].get=/b&additionalBindings[0].get=/a&additional_bindings[0].body=*")
+	require.NoError(t, encoding.GetCodec(contentType).Unmarshal(content, out))
+	require.Len(t, out.AdditionalBindings, 2)
+	require.Equal(t, "/a", out.AdditionalBindings[0].GetGet())
+	require.Equal(t, "*", out.AdditionalBindings[0].Body)
+	require.Equal(t, "/b", out.AdditionalBindings[1].GetGet())
+
+	require.Error(t, encoding.GetCodec(contentType).Unmarshal([]byte("additionalBindings[x].get=/a"), &annotations.HttpRule{}))
+	require.Error(t, encoding.GetCodec(contentType).Unmarshal([]byte("selector[0]=a"), &annotations.HttpRule{}))
+}
+
+func TestProtoMap(t *testing.T) {
+	in := &errors.Error{Code: 400, Reason: "INVALID", Metadata: map[string]string{"field": "name", "rule": "len"}}
+	content, err := encoding.GetCodec(contentType).Marshal(in)
+	require.NoError(t, err)
+	require.Contains(t, string(content), "metadata%5Bfield%5D=name")
+	out := &errors.Error{}
+	require.NoError(t, encoding.GetCodec(contentType).Unmarshal(content, out))
+	require.True(t, proto.Equal(in, out), "want %v, got %v", in, out)
+}
@@ -37,13 +37,23 @@ func populateFieldValues(v protoreflect.Message, fieldPath []string, values []st
 	for i, fieldName := range fieldPath {
 		fields := v.Descriptor().Fields()
 		if fd = getDescriptorByFieldAndName(fields, fieldName); fd == nil {
-			if len(fieldName) > 2 && strings.HasSuffix(fieldName, "[]") {
-				fd = getDescriptorByFieldAndName(fields, strings.TrimSuffix(fieldName, "[]"))
+			name, key, ok := parseFieldKey(fieldName)
+			if ok {
+				fd = getDescriptorByFieldAndName(fields, name)
 			}
 			if fd == nil {
 				// ignore unexpected field.
 				return nil
 			}
+			if key != "" {
+				// the element of a repeated field, e.g. "items[0]", or the entry of a map, e.g. "labels[key]".
+				next, err := populateElement(v, fd, key, i == len(fieldPath)-1, values)
+				if err != nil || next == nil {
+					return err
+				}
+				v = next
+				continue
+			}
 		}
 
 		if i == len(fieldPath)-1 {
@@ -73,6 +83,68 @@ func populateFieldValues(v protoreflect.Message, fieldPath []string, values []st
 	return populateField(fd, v, values[0])
 }
 
+// maxListIndex is the max index of the repeated fields, e.g. "items[999]".
+const maxListIndex = 1000
+
+// parseFieldKey parses the field name with a key, e.g. "items[0]", "labels[key]" or "items[]".
+func parseFieldKey(fieldName string) (name, key string, ok bool) {
+	i := strings.IndexByte(fieldName, '[')
+	if i < 1 || !strings.HasSuffix(fieldName, "]") {
+		return "", "", false
+	}
+	return fieldName[:i], fieldName[i+1 : len(fieldName)-1], true
+}
+
+// populateElement populates the element of the list or the map field by the key, the message of
+// the element is returned if it is not the last field of the path.
+func populateElement(v protoreflect.Message, fd protoreflect.FieldDescriptor, key string, last bool, values []string) (protoreflect.Message, error) {
+	if last && len(values) > 1 {
+		return nil, fmt.Errorf("too many values for field %q: %s", fd.FullName().Name(), strings.Join(values, ", "))
+	}
+	switch {
+	case fd.IsList():
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 0 || index >= maxListIndex {
+			return nil, fmt.Errorf("invalid index %q of list %q", key, fd.FullName().Name())
+		}
+		list := v.Mutable(fd).List()
+		for list.Len() <= index {
+			list.Append(list.NewElement())
+		}
+		if last {
+			val, err := parseField(fd, values[0])
+			if err != nil {
+				return nil, fmt.Errorf("parsing list %q: %w", fd.FullName().Name(), err)
+			}
+			list.Set(index, val)
+			return nil, nil
+		}
+		if fd.Message() == nil {
+			return nil, fmt.Errorf("invalid path: %q is not a message", fd.FullName().Name())
+		}
+		return list.Get(index).Message(), nil
+	case fd.IsMap():
+		mapKey, err := parseField(fd.MapKey(), key)
+		if err != nil {
+			return nil, fmt.Errorf("parsing map key %q: %w", fd.FullName().Name(), err)
+		}
+		mp := v.Mutable(fd).Map()
+		if last {
+			val, err := parseField(fd.MapValue(), values[0])
+			if err != nil {
+				return nil, fmt.Errorf("parsing map value %q: %w", fd.FullName().Name(), err)
+			}
+			mp.Set(mapKey.MapKey(), val)
+			return nil, nil
+		}
+		if fd.MapValue().Message() == nil {
+			return nil, fmt.Errorf("invalid path: %q is not a message", fd.FullName().Name())
+		}
+		return mp.Mutable(mapKey.MapKey()).Message(), nil
+	}
+	return nil, fmt.Errorf("invalid path: %q is not a list or a map", fd.FullName().Name())
+}
+
 func getDescriptorByFieldAndName(fields protoreflect.FieldDescriptors, fieldName string) protoreflect.FieldDescriptor {
 	var fd protoreflect.FieldDescriptor
 	if fd = fields.ByName(protoreflect.Name(fieldName)); fd == nil {
@@ -187,7 +259,7 @@ func parseField(fd protoreflect.FieldDescriptor, value string) (protoreflect.Val
 	case protoreflect.StringKind:
 		return protoreflect.ValueOfString(value), nil
 	case protoreflect.BytesKind:
-		v, err := base64.StdEncoding.DecodeString(value)
+		v, err := decodeBytes(value)
 		if err != nil {
 			return protoreflect.Value{}, err
 		}
@@ -265,7 +337,7 @@ func parseMessage(md protoreflect.MessageDescriptor, value string) (protoreflect
 	case "google.protobuf.StringValue":
 		msg = wrapperspb.String(value)
 	case "google.protobuf.BytesValue":
-		v, err := base64.StdEncoding.DecodeString(value)
+		v, err := decodeBytes(value)
 		if err != nil {
 			return protoreflect.Value{}, err
 		}
@@ -279,3 +351,11 @@ func parseMessage(md protoreflect.MessageDescriptor, value string) (protoreflect
 	}
 	return protoreflect.ValueOfMessage(msg.ProtoReflect()), nil
 }
+
+// decodeBytes decodes the standard or the URL base64 encoding.
+func decodeBytes(value string) ([]byte, error) {
+	if b, err := base64.StdEncoding.DecodeString(value); err == nil {
+		return b, nil
+	}
+	return base64.URLEncoding.DecodeString(value)
+}
@@ -46,32 +46,34 @@ func encodeByField(u url.Values, path string, v protoreflect.Message) error {
 			newPath = path + "." + key
 		}
 
-		if of := fd.ContainingOneof(); of != nil {
-			if f := v.WhichOneof(of); f != nil {
-				if f != fd {
-					continue
-				}
-			}
+		if of := fd.ContainingOneof(); of != nil && v.WhichOneof(of) != fd {
+			// only the field which is set is encoded for a oneof.
 			continue
 		}
 		switch {
 		case fd.IsList():
 			if v.Get(fd).List().Len() > 0 {
-				list, err := encodeRepeatedField(fd, v.Get(fd).List())
-				if err != nil {
-					return err
+				if isFlatMessage(fd.Message()) {
+					list, err := encodeRepeatedField(fd, v.Get(fd).List())
+					if err != nil {
+						return err
+					}
+					u[newPath] = list
+					continue
+				}
+				// the repeated messages are encoded by the indexes, e.g. "items[0].name".
+				list := v.Get(fd).List()
+				for i := 0; i < list.Len(); i++ {
+					if err := encodeByField(u, fmt.Sprintf("%s[%d]", newPath, i), list.Get(i).Message()); err != nil {
+						return err
+					}
 				}
-				u[newPath] = list
 			}
 		case fd.IsMap():
 			if v.Get(fd).Map().Len() > 0 {
-				m, err := encodeMapField(fd, v.Get(fd).Map())
-				if err != nil {
+				if err := encodeMapField(u, newPath, fd, v.Get(fd).Map()); err != nil {
 					return err
 				}
-				for k, value := range m {
-					u[fmt.Sprintf("%s[%s]", newPath, k)] = []string{value}
-				}
 			}
 		case (fd.Kind() == protoreflect.MessageKind) || (fd.Kind() == protoreflect.GroupKind):
 			value, err := encodeMessage(fd.Message(), v.Get(fd))
@@ -108,22 +110,42 @@ func encodeRepeatedField(fieldDescriptor protoreflect.FieldDescriptor, list prot
 	return values, nil
 }
 
-func encodeMapField(fieldDescriptor protoreflect.FieldDescriptor, mp protoreflect.Map) (map[string]string, error) {
-	m := make(map[string]string)
+// encodeMapField encodes the map entries by the keys, e.g. "labels[key]" or "items[key].name".
+func encodeMapField(u url.Values, path string, fieldDescriptor protoreflect.FieldDescriptor, mp protoreflect.Map) error {
+	var err error
 	mp.Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
-		key, err := encodeField(fieldDescriptor.MapValue(), k.Value())
-		if err != nil {
+		var key, value string
+		if key, err = encodeField(fieldDescriptor.MapKey(), k.Value()); err != nil {
 			return false
 		}
-		value, err := encodeField(fieldDescriptor.MapValue(), v)
-		if err != nil {
+		path := fmt.Sprintf("%s[%s]", path, key)
+		if !isFlatMessage(fieldDescriptor.MapValue().Message()) {
+			err = encodeByField(u, path, v.Message())
+			return err == nil
+		}
+		if value, err = encodeField(fieldDescriptor.MapValue(), v); err != nil {
 			return false
 		}
-		m[key] = value
+		u[path] = []string{value}
 		return true
 	})
+	return err
+}
 
-	return m, nil
+// isFlatMessage reports whether the field of the message descriptor is encoded as a single value,
+// which is either a scalar or a well-known message.
+func isFlatMessage(md protoreflect.MessageDescriptor) bool {
+	if md == nil {
+		return true
+	}
+	switch md.FullName() {
+	case "google.protobuf.Timestamp", "google.protobuf.Duration", "google.protobuf.BytesValue",
+		"google.protobuf.DoubleValue", "google.protobuf.FloatValue", "google.protobuf.Int64Value", "google.protobuf.Int32Value",
+		"google.protobuf.UInt64Value", "google.protobuf.UInt32Value", "google.protobuf.BoolValue", "google.protobuf.StringValue",
+		"google.protobuf.FieldMask":
+		return true
+	}
+	return false
 }
 
 func encodeField(fieldDescriptor protoreflect.FieldDescriptor, value protoreflect.Value) (string, error) {
@@ -0,0 +1,140 @@
+package errors
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Summary is the count of the identical errors which are aggregated in a window.
+type Summary struct {
+	Code   int32
+	Reason string
+	// Count is the number of the errors which are not reported individually.
+	Count int
+}
+
+type aggregateKey struct {
+	code   int32
+	reason string
+}
+
+// Aggregator aggregates the identical errors by the code and the reason over a window, e.g. to keep the error logs
+// readable during a dependency outage. In a window, the first error of each code and reason is reported individually
+// up to the max distinct errors, and the others are counted into the summaries of the window.
+type Aggregator struct {
+	window time.Duration
+	max    int
+	now    func() time.Time
+
+	lock   sync.Mutex
+	start  time.Time
+	seen   map[aggregateKey]int
+	logged int
+	notify func([]Summary)
+	// timer is the pending flush of the window, it is nil unless the summaries are notified.
+	timer *time.Timer
+}
+
+// NewAggregator returns an Aggregator of the window, which reports up to max distinct errors individually per window.
+func NewAggregator(window time.Duration, max int) *Aggregator {
+	return &Aggregator{
+		window: window,
+		max:    max,
+		now:    time.Now,
+		seen:   make(map[aggregateKey]int),
+	}
+}
+
+// Add adds the error, and reports whether it should be reported individually. The summaries of the previous window
+// are returned once by the first call after the window elapses, in the order of the code and the reason,
+// a nil error only checks the window.
+func (a *Aggregator) Add(err error) (individual bool, summaries []Summary) {
+	now := a.now()
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.start.IsZero() {
+		a.start = now
+	}
+	if now.Sub(a.start) >= a.window {
+		summaries = a.flush()
+		a.start = now
+	}
+	if err == nil {
+		return false, summaries
+	}
+	se := FromError(err)
+	key := aggregateKey{code: se.Code, reason: se.Reason}
+	count, ok := a.seen[key]
+	if !ok && a.logged < a.max {
+		a.logged++
+		a.seen[key] = 0
+		return true, summaries
+	}
+	a.seen[key] = count + 1
+	if a.notify != nil && a.timer == nil {
+		a.timer = time.AfterFunc(a.start.Add(a.window).Sub(now), a.expire)
+	}
+	return false, summaries
+}
+
+// Notify sets the func which is called with the summaries of a window once it elapses, so that the counts
+// are reported even if no error is added afterwards, e.g. after an outage or on a quiet service.
+// The summaries of a window are either returned by Add or notified, never both.
+func (a *Aggregator) Notify(f func([]Summary)) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.notify = f
+}
+
+// Flush returns the summaries of the current window and starts a new one, e.g. when stopping.
+func (a *Aggregator) Flush() []Summary {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.start = time.Time{}
+	return a.flush()
+}
+
+// expire notifies the summaries of the elapsed window, unless it is already flushed by Add or Flush.
+func (a *Aggregator) expire() {
+	a.lock.Lock()
+	a.timer = nil
+	now := a.now()
+	if !a.start.IsZero() && now.Sub(a.start) < a.window {
+		// a new window is started, which is scheduled by its first aggregated error.
+		for _, n := range a.seen {
+			if n > 0 {
+				a.timer = time.AfterFunc(a.start.Add(a.window).Sub(now), a.expire)
+				break
+			}
+		}
+		a.lock.Unlock()
+		return
+	}
+	summaries := a.flush()
+	a.start = time.Time{}
+	notify := a.notify
+	a.lock.Unlock()
+	if len(summaries) > 0 && notify != nil {
+		notify(summaries)
+	}
+}
+
+// flush returns the summaries of the window and resets it.
+func (a *Aggregator) flush() []Summary {
+	var summaries []Summary
+	for k, n := range a.seen {
+		if n > 0 {
+			summaries = append(summaries, Summary{Code: k.code, Reason: k.reason, Count: n})
+		}
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Code != summaries[j].Code {
+			return summaries[i].Code < summaries[j].Code
+		}
+		return summaries[i].Reason < summaries[j].Reason
+	})
+	a.seen = make(map[aggregateKey]int)
+	a.logged = 0
+	return summaries
+}
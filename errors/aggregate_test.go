@@ -0,0 +1,82 @@
+package errors
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAggregator(t *testing.T) {
+	now := time.Unix(0, 0)
+	a := NewAggregator(time.Second, 2)
+	a.now = func() time.Time { return now }
+
+	add := func(err error, want bool) {
+		t.Helper()
+		individual, summaries := a.Add(err)
+		if individual != want {
+			t.Errorf("Add(%v) = %v, want %v", err, individual, want)
+		}
+		if summaries != nil {
+			t.Errorf("unexpected summaries %v", summaries)
+		}
+	}
+	add(ServiceUnavailable("DB", "database unavailable"), true)
+	add(ServiceUnavailable("DB", "database unavailable"), false)
+	add(errors.New("connection refused"), true)
+	add(ServiceUnavailable("DB", "timeout"), false)
+	add(ServiceUnavailable("DB", "database unavailable"), false)
+	add(nil, false)
+
+	now = now.Add(time.Second)
+	individual, summaries := a.Add(nil)
+	if individual {
+		t.Error("nil error reported individually")
+	}
+	want := []Summary{{Code: 503, Reason: "DB", Count: 3}}
+	if !reflect.DeepEqual(summaries, want) {
+		t.Errorf("summaries = %v, want %v", summaries, want)
+	}
+	// a new window reports the errors individually again.
+	add(ServiceUnavailable("DB", "database unavailable"), true)
+	now = now.Add(time.Second)
+	if _, summaries = a.Add(nil); summaries != nil {
+		t.Errorf("unexpected summaries %v", summaries)
+	}
+}
+
+func TestAggregatorNotify(t *testing.T) {
+	a := NewAggregator(20*time.Millisecond, 1)
+	notified := make(chan []Summary, 1)
+	a.Notify(func(summaries []Summary) { notified <- summaries })
+	for i := 0; i < 3; i++ {
+		a.Add(ServiceUnavailable("DB", "database unavailable"))
+	}
+	select {
+	case summaries := <-notified:
+		want := []Summary{{Code: 503, Reason: "DB", Count: 2}}
+		if !reflect.DeepEqual(summaries, want) {
+			t.Errorf("summaries = %v, want %v", summaries, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the summaries are not notified")
+	}
+	// the notified window is not reported again.
+	if _, summaries := a.Add(nil); summaries != nil {
+		t.Errorf("unexpected summaries %v", summaries)
+	}
+}
+
+func TestAggregatorFlush(t *testing.T) {
+	a := NewAggregator(time.Hour, 1)
+	a.Add(ServiceUnavailable("DB", "database unavailable"))
+	a.Add(ServiceUnavailable("DB", "database unavailable"))
+	want := []Summary{{Code: 503, Reason: "DB", Count: 1}}
+	if summaries := a.Flush(); !reflect.DeepEqual(summaries, want) {
+		t.Errorf("summaries = %v, want %v", summaries, want)
+	}
+	if summaries := a.Flush(); summaries != nil {
+		t.Errorf("unexpected summaries %v", summaries)
+	}
+}
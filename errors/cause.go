@@ -0,0 +1,103 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+)
+
+// causeDetail marks the debug info detail which carries the cause chain of an error.
+const causeDetail = "cause"
+
+// causeError is an error with its cause.
+type causeError struct {
+	err   *Error
+	cause error
+}
+
+// WithCause returns the error with the cause, the cause is returned by Unwrap,
+// and the returned error is still converted to the *Error by FromError and As.
+func (e *Error) WithCause(cause error) error {
+	return &causeError{err: e, cause: cause}
+}
+
+func (e *causeError) Error() string {
+	return fmt.Sprintf("%s cause = %v", e.err.Error(), e.cause)
+}
+
+// Unwrap returns the cause of the error.
+func (e *causeError) Unwrap() error { return e.cause }
+
+// Is matches the error by the reason.
+func (e *causeError) Is(err error) bool { return e.err.Is(err) }
+
+// As sets the target to the *Error.
+func (e *causeError) As(target interface{}) bool {
+	if t, ok := target.(**Error); ok {
+		*t = e.err
+		return true
+	}
+	return false
+}
+
+// GRPCStatus returns the Status of the error without the cause.
+func (e *causeError) GRPCStatus() *status.Status { return e.err.GRPCStatus() }
+
+// causeMessage is a cause which is reconstructed from the message of the cause.
+type causeMessage struct {
+	msg   string
+	cause error
+}
+
+func (e *causeMessage) Error() string { return e.msg }
+func (e *causeMessage) Unwrap() error { return e.cause }
+
+// StatusWithCause returns the Status of the error with the messages of its cause chain in the details,
+// the chain is reconstructed on the client by FromStatusWithCause. The causes may contain internal
+// details, so it should only be used within a trust boundary.
+func StatusWithCause(err error) *status.Status {
+	gs := FromError(err).GRPCStatus()
+	var causes []string
+	for cause := causeOf(err); cause != nil; cause = errors.Unwrap(cause) {
+		causes = append(causes, cause.Error())
+	}
+	if len(causes) == 0 {
+		return gs
+	}
+	if s, err := gs.WithDetails(&errdetails.DebugInfo{Detail: causeDetail, StackEntries: causes}); err == nil {
+		return s
+	}
+	return gs
+}
+
+// causeOf returns the cause of the first *Error with a cause in the chain.
+func causeOf(err error) error {
+	var ce *causeError
+	if errors.As(err, &ce) {
+		return ce.cause
+	}
+	return nil
+}
+
+// FromStatusWithCause converts the gRPC status error to the error with the cause chain of StatusWithCause,
+// the error without the cause chain is returned as is.
+func FromStatusWithCause(err error) error {
+	gs, ok := status.FromError(err)
+	if !ok || err == nil {
+		return err
+	}
+	for _, detail := range gs.Details() {
+		d, ok := detail.(*errdetails.DebugInfo)
+		if !ok || d.Detail != causeDetail || len(d.StackEntries) == 0 {
+			continue
+		}
+		var cause error
+		for i := len(d.StackEntries) - 1; i >= 0; i-- {
+			cause = &causeMessage{msg: d.StackEntries[i], cause: cause}
+		}
+		return FromError(err).WithCause(cause)
+	}
+	return err
+}
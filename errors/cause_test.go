@@ -0,0 +1,40 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestWithCause(t *testing.T) {
+	io := errors.New("connection refused")
+	db := fmt.Errorf("query users: %w", io)
+	err := ServiceUnavailable("DB", "database unavailable").WithCause(db)
+
+	if !errors.Is(err, io) {
+		t.Errorf("should be the cause: %v", err)
+	}
+	if !IsServiceUnavailable(err) || Reason(err) != "DB" {
+		t.Errorf("should be the kratos error: %v", err)
+	}
+	if errors.Unwrap(err) != db {
+		t.Errorf("should unwrap the cause: %v", err)
+	}
+
+	// the cause is not sent by default.
+	if se := FromStatusWithCause(FromError(err).GRPCStatus().Err()); errors.Unwrap(se) != nil {
+		t.Errorf("should have no cause: %v", se)
+	}
+
+	got := FromStatusWithCause(StatusWithCause(err).Err())
+	if Reason(got) != "DB" || Code(got) != 503 {
+		t.Errorf("should be the kratos error: %v", got)
+	}
+	var msgs []string
+	for cause := errors.Unwrap(got); cause != nil; cause = errors.Unwrap(cause) {
+		msgs = append(msgs, cause.Error())
+	}
+	if fmt.Sprint(msgs) != "[query users: connection refused connection refused]" {
+		t.Errorf("unexpected cause chain %v", msgs)
+	}
+}
@@ -0,0 +1,68 @@
+package errors
+
+import (
+	"context"
+	"strings"
+)
+
+// Catalog is a catalog of the localized messages, the messages are keyed by the error reasons.
+type Catalog interface {
+	// Message returns the message template of the key in the locale, e.g. "zh-CN".
+	Message(locale, key string) (string, bool)
+}
+
+// MapCatalog is a catalog of the message templates keyed by the locale and the key,
+// e.g. {"en": {"USER_NOT_FOUND": "user {id} not found"}}.
+type MapCatalog map[string]map[string]string
+
+// Message returns the message template of the key in the locale,
+// the base language is used if the locale has no such key, e.g. "en" for "en-US".
+func (c MapCatalog) Message(locale, key string) (string, bool) {
+	locale = strings.ToLower(locale)
+	for l, messages := range c {
+		if strings.ToLower(l) == locale {
+			if msg, ok := messages[key]; ok {
+				return msg, true
+			}
+		}
+	}
+	if i := strings.IndexByte(locale, '-'); i > 0 {
+		return c.Message(locale[:i], key)
+	}
+	return "", false
+}
+
+type localeKey struct{}
+
+// NewLocaleContext creates a new context with the locale, which takes precedence over the Accept-Language header.
+func NewLocaleContext(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// LocaleFromContext returns the locale in ctx if it exists.
+func LocaleFromContext(ctx context.Context) (string, bool) {
+	locale, ok := ctx.Value(localeKey{}).(string)
+	return locale, ok && locale != ""
+}
+
+// Localize returns the error with the message of the first locale which has the reason in the catalog,
+// the "{key}" placeholders of the message are replaced by the metadata values. The error is returned
+// as is if no locale has the reason.
+func Localize(e *Error, c Catalog, locales ...string) *Error {
+	if e == nil || c == nil {
+		return e
+	}
+	for _, locale := range locales {
+		msg, ok := c.Message(locale, e.Reason)
+		if !ok {
+			continue
+		}
+		for k, v := range e.Metadata {
+			msg = strings.ReplaceAll(msg, "{"+k+"}", v)
+		}
+		err := e.WithMetadata(e.Metadata)
+		err.Message = msg
+		return err
+	}
+	return e
+}
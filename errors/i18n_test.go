@@ -0,0 +1,32 @@
+package errors
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLocalize(t *testing.T) {
+	c := MapCatalog{
+		"en":    {"USER_NOT_FOUND": "user {id} not found"},
+		"zh-CN": {"USER_NOT_FOUND": "用户 {id} 不存在"},
+	}
+	err := NotFound("USER_NOT_FOUND", "user not found").WithMetadata(map[string]string{"id": "1"})
+
+	if e := Localize(err, c, "fr", "zh-cn"); e.Message != "用户 1 不存在" {
+		t.Errorf("unexpected message %s", e.Message)
+	}
+	if e := Localize(err, c, "en-US"); e.Message != "user 1 not found" {
+		t.Errorf("unexpected message %s", e.Message)
+	}
+	if e := Localize(err, c, "fr"); e.Message != "user not found" {
+		t.Errorf("unexpected message %s", e.Message)
+	}
+	if err.Message != "user not found" {
+		t.Errorf("the error is modified: %v", err)
+	}
+
+	ctx := NewLocaleContext(context.Background(), "en")
+	if locale, ok := LocaleFromContext(ctx); !ok || locale != "en" {
+		t.Errorf("unexpected locale %s", locale)
+	}
+}
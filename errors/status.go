@@ -0,0 +1,33 @@
+package errors
+
+// statusReasons is the reasons of the common HTTP statuses.
+var statusReasons = map[int]string{
+	400: "BAD_REQUEST",
+	401: "UNAUTHORIZED",
+	403: "FORBIDDEN",
+	404: "NOT_FOUND",
+	405: "METHOD_NOT_ALLOWED",
+	409: "CONFLICT",
+	413: "PAYLOAD_TOO_LARGE",
+	429: "TOO_MANY_REQUESTS",
+	499: "CLIENT_CLOSED",
+	500: "INTERNAL_SERVER",
+	501: "NOT_IMPLEMENTED",
+	502: "BAD_GATEWAY",
+	503: "SERVICE_UNAVAILABLE",
+	504: "GATEWAY_TIMEOUT",
+}
+
+// StatusReason is the reason of the HTTP statuses without a known reason.
+const StatusReason = "HTTP_STATUS"
+
+// FromHTTPStatus returns an error object for the HTTP status code, e.g. of a non-kratos service,
+// the code is kept as is, so Code(FromHTTPStatus(code, message)) == code, and the reason is
+// the well-known reason of the status, or StatusReason if the status is unknown.
+func FromHTTPStatus(code int, message string) *Error {
+	reason, ok := statusReasons[code]
+	if !ok {
+		reason = StatusReason
+	}
+	return New(code, reason, message)
+}
@@ -0,0 +1,34 @@
+package errors
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestFromHTTPStatus(t *testing.T) {
+	tests := []struct {
+		code   int
+		reason string
+		is     func(error) bool
+	}{
+		{http.StatusBadRequest, "BAD_REQUEST", IsBadRequest},
+		{http.StatusUnauthorized, "UNAUTHORIZED", IsUnauthorized},
+		{http.StatusForbidden, "FORBIDDEN", IsForbidden},
+		{http.StatusNotFound, "NOT_FOUND", IsNotFound},
+		{http.StatusConflict, "CONFLICT", IsConflict},
+		{http.StatusInternalServerError, "INTERNAL_SERVER", IsInternalServer},
+		{http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", IsServiceUnavailable},
+		{http.StatusGatewayTimeout, "GATEWAY_TIMEOUT", IsGatewayTimeout},
+		{499, "CLIENT_CLOSED", IsClientClosed},
+		{http.StatusTeapot, StatusReason, nil},
+	}
+	for _, test := range tests {
+		err := FromHTTPStatus(test.code, "message")
+		if Code(err) != test.code || Reason(err) != test.reason || err.Message != "message" {
+			t.Errorf("unexpected error %v for %d", err, test.code)
+		}
+		if test.is != nil && !test.is(err) {
+			t.Errorf("unexpected type %v for %d", err, test.code)
+		}
+	}
+}
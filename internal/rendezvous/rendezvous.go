@@ -0,0 +1,27 @@
+package rendezvous
+
+import "hash/fnv"
+
+// Pick returns the index of the node with the highest hash score for the key,
+// ids are the node identifiers. When a node is added or removed, only the keys
+// mapped to that node are remapped. It returns -1 if ids is empty.
+func Pick(key string, ids []string) int {
+	var (
+		picked = -1
+		best   uint64
+	)
+	for i, id := range ids {
+		if s := score(key, id); picked < 0 || s > best {
+			picked, best = i, s
+		}
+	}
+	return picked
+}
+
+func score(key, id string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(id))
+	return h.Sum64()
+}
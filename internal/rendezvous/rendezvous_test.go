@@ -0,0 +1,29 @@
+package rendezvous
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPick(t *testing.T) {
+	assert.Equal(t, -1, Pick("key", nil))
+	assert.Equal(t, 0, Pick("key", []string{"a"}))
+
+	ids := []string{"a", "b", "c", "d", "e"}
+	picked := make(map[string]string)
+	for i := 0; i < 1000; i++ {
+		key := strconv.Itoa(i)
+		idx := Pick(key, ids)
+		assert.Equal(t, idx, Pick(key, ids))
+		picked[key] = ids[idx]
+	}
+	// removing a node only remaps the keys of that node.
+	shrunk := []string{"a", "b", "d", "e"}
+	for key, id := range picked {
+		if id != "c" {
+			assert.Equal(t, id, shrunk[Pick(key, shrunk)])
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrAsyncClosed is returned by the closed async logger.
+var ErrAsyncClosed = errors.New("log: async logger closed")
+
+var _ Logger = (*AsyncLogger)(nil)
+
+// AsyncOption is async logger option.
+type AsyncOption func(*AsyncLogger)
+
+// AsyncBuffer with the max number of the buffered entries, default is 1024.
+func AsyncBuffer(size int) AsyncOption {
+	return func(l *AsyncLogger) {
+		l.size = size
+	}
+}
+
+// AsyncDrop with the policy when the buffer is full, the entries are dropped if drop is true,
+// otherwise Log blocks until the buffer has room, default is false.
+func AsyncDrop(drop bool) AsyncOption {
+	return func(l *AsyncLogger) {
+		l.drop = drop
+	}
+}
+
+type asyncEntry struct {
+	level   Level
+	keyvals []interface{}
+}
+
+// AsyncLogger is a logger which writes the logs to the underlying logger in background.
+// The valuers are evaluated in background, so it should wrap the sink logger, e.g.
+// log.With(log.NewAsyncLogger(log.NewStdLogger(w)), "caller", log.DefaultCaller).
+type AsyncLogger struct {
+	logger  Logger
+	size    int
+	drop    bool
+	entries chan asyncEntry
+	// quit releases the blocked logs, and stop is closed once no log can be enqueued, which starts the drain.
+	quit    chan struct{}
+	stop    chan struct{}
+	abort   chan struct{}
+	done    chan struct{}
+	once    sync.Once
+	abortMu sync.Once
+	// lock guards the enqueue against the close.
+	lock    sync.RWMutex
+	closed  bool
+	dropped int64
+}
+
+// NewAsyncLogger new an async logger.
+func NewAsyncLogger(logger Logger, opts ...AsyncOption) *AsyncLogger {
+	l := &AsyncLogger{
+		logger: logger,
+		size:   1024,
+		quit:   make(chan struct{}),
+		stop:   make(chan struct{}),
+		abort:  make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	for _, o := range opts {
+		o(l)
+	}
+	if l.size < 1 {
+		l.size = 1
+	}
+	l.entries = make(chan asyncEntry, l.size)
+	go l.run()
+	return l
+}
+
+// Log buffers the log.
+func (l *AsyncLogger) Log(level Level, keyvals ...interface{}) error {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	if l.closed {
+		atomic.AddInt64(&l.dropped, 1)
+		return ErrAsyncClosed
+	}
+	e := asyncEntry{level: level, keyvals: append([]interface{}(nil), keyvals...)}
+	if l.drop {
+		select {
+		case l.entries <- e:
+		default:
+			atomic.AddInt64(&l.dropped, 1)
+		}
+		return nil
+	}
+	select {
+	case l.entries <- e:
+		return nil
+	case <-l.quit:
+		atomic.AddInt64(&l.dropped, 1)
+		return ErrAsyncClosed
+	}
+}
+
+// Dropped returns the number of the dropped logs.
+func (l *AsyncLogger) Dropped() int64 {
+	return atomic.LoadInt64(&l.dropped)
+}
+
+// Close stops accepting logs and flushes the buffered ones until the context is done,
+// the logs not flushed before the deadline are dropped.
+func (l *AsyncLogger) Close(ctx context.Context) error {
+	l.once.Do(func() {
+		close(l.quit)
+		l.lock.Lock()
+		l.closed = true
+		l.lock.Unlock()
+		close(l.stop)
+	})
+	select {
+	case <-l.done:
+		return nil
+	case <-ctx.Done():
+		l.abortMu.Do(func() { close(l.abort) })
+		<-l.done
+		return ctx.Err()
+	}
+}
+
+func (l *AsyncLogger) run() {
+	defer close(l.done)
+	for {
+		// the closing is checked first, so that the abort is not missed by a ready entry.
+		select {
+		case <-l.stop:
+			l.drain()
+			return
+		default:
+		}
+		select {
+		case e := <-l.entries:
+			_ = l.logger.Log(e.level, e.keyvals...)
+		case <-l.stop:
+			l.drain()
+			return
+		}
+	}
+}
+
+// drain flushes the buffered logs until the buffer is empty or the close is aborted.
+func (l *AsyncLogger) drain() {
+	for {
+		select {
+		case <-l.abort:
+			atomic.AddInt64(&l.dropped, int64(len(l.entries)))
+			return
+		default:
+		}
+		select {
+		case e := <-l.entries:
+			_ = l.logger.Log(e.level, e.keyvals...)
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type blockingLogger struct {
+	lock    sync.Mutex
+	release chan struct{}
+	buf     bytes.Buffer
+}
+
+func (l *blockingLogger) Log(level Level, keyvals ...interface{}) error {
+	if l.release != nil {
+		<-l.release
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return NewStdLogger(&l.buf).Log(level, keyvals...)
+}
+
+func (l *blockingLogger) String() string {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.buf.String()
+}
+
+func TestAsyncLogger(t *testing.T) {
+	sink := &blockingLogger{}
+	l := NewAsyncLogger(sink)
+	NewHelper(l).Info("hello")
+	_ = l.Log(LevelWarn, "key", "value")
+	if err := l.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sink.String(), "INFO msg=hello\nWARN key=value\n"; got != want {
+		t.Fatalf("want %q, got %q", want, got)
+	}
+	if err := l.Log(LevelInfo, "msg", "closed"); err != ErrAsyncClosed {
+		t.Fatalf("want ErrAsyncClosed, got %v", err)
+	}
+}
+
+func TestAsyncLoggerDrop(t *testing.T) {
+	sink := &blockingLogger{release: make(chan struct{})}
+	l := NewAsyncLogger(sink, AsyncBuffer(2), AsyncDrop(true))
+	for i := 0; i < 10; i++ {
+		_ = l.Log(LevelInfo, "i", i)
+	}
+	// one entry is being written, two are buffered.
+	if got := l.Dropped(); got < 7 {
+		t.Fatalf("want at least 7 dropped, got %d", got)
+	}
+	close(sink.release)
+	if err := l.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAsyncLoggerCloseDeadline(t *testing.T) {
+	sink := &blockingLogger{release: make(chan struct{})}
+	l := NewAsyncLogger(sink, AsyncBuffer(4))
+	for i := 0; i < 4; i++ {
+		_ = l.Log(LevelInfo, "i", i)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(sink.release)
+	}()
+	if err := l.Close(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("want deadline exceeded, got %v", err)
+	}
+	if l.Dropped() == 0 {
+		t.Fatal("want the unflushed logs dropped")
+	}
+}
+
+func TestAsyncLoggerCloseRace(t *testing.T) {
+	for _, drop := range []bool{true, false} {
+		for i := 0; i < 200; i++ {
+			sink := &blockingLogger{}
+			l := NewAsyncLogger(sink, AsyncBuffer(4), AsyncDrop(drop))
+			const logs = 16
+			var wg sync.WaitGroup
+			start := make(chan struct{})
+			for j := 0; j < logs; j++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					<-start
+					_ = l.Log(LevelInfo, "msg", "hello")
+				}()
+			}
+			close(start)
+			if err := l.Close(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			wg.Wait()
+			// every log is either written or dropped, none is enqueued after the drain.
+			written := int64(strings.Count(sink.String(), "\n"))
+			if written+l.Dropped() != logs {
+				t.Fatalf("drop %v: written %d and dropped %d of %d logs", drop, written, l.Dropped(), logs)
+			}
+		}
+	}
+}
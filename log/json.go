@@ -0,0 +1,128 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+var _ Logger = (*jsonLogger)(nil)
+
+// JSONOption is JSON logger option.
+type JSONOption func(*jsonLogger)
+
+// JSONTimeKey with the key of the timestamp, default is "ts", the timestamp is omitted if the key is empty.
+func JSONTimeKey(key string) JSONOption {
+	return func(l *jsonLogger) {
+		l.timeKey = key
+	}
+}
+
+// JSONTimeFormat with the layout of the timestamp, default is time.RFC3339.
+func JSONTimeFormat(layout string) JSONOption {
+	return func(l *jsonLogger) {
+		l.timeFormat = layout
+	}
+}
+
+// JSONLevelKey with the key of the level, default is "level".
+func JSONLevelKey(key string) JSONOption {
+	return func(l *jsonLogger) {
+		l.levelKey = key
+	}
+}
+
+// JSONMessageKey with the key of the message, the "msg" key of the entries is renamed to it, default is "msg".
+func JSONMessageKey(key string) JSONOption {
+	return func(l *jsonLogger) {
+		l.messageKey = key
+	}
+}
+
+type jsonLogger struct {
+	mu         sync.Mutex
+	w          io.Writer
+	pool       *sync.Pool
+	timeKey    string
+	timeFormat string
+	levelKey   string
+	messageKey string
+}
+
+// NewJSONLogger new a logger which writes each entry as a JSON object per line,
+// the fields are written in the order of the timestamp, the level and the kv pairs.
+func NewJSONLogger(w io.Writer, opts ...JSONOption) Logger {
+	l := &jsonLogger{
+		w:          w,
+		timeKey:    "ts",
+		timeFormat: time.RFC3339,
+		levelKey:   "level",
+		messageKey: "msg",
+		pool: &sync.Pool{
+			New: func() interface{} {
+				return new(bytes.Buffer)
+			},
+		},
+	}
+	for _, o := range opts {
+		o(l)
+	}
+	return l
+}
+
+// Log print the kv pairs log as a JSON object.
+func (l *jsonLogger) Log(level Level, keyvals ...interface{}) error {
+	if len(keyvals) == 0 {
+		return nil
+	}
+	if (len(keyvals) & 1) == 1 {
+		keyvals = append(keyvals, "KEYVALS UNPAIRED")
+	}
+	buf := l.pool.Get().(*bytes.Buffer)
+	defer func() {
+		buf.Reset()
+		l.pool.Put(buf)
+	}()
+	buf.WriteByte('{')
+	if l.timeKey != "" {
+		writeJSONField(buf, l.timeKey, time.Now().Format(l.timeFormat))
+	}
+	writeJSONField(buf, l.levelKey, level.String())
+	for i := 0; i < len(keyvals); i += 2 {
+		key := fmt.Sprint(keyvals[i])
+		if key == "msg" {
+			key = l.messageKey
+		}
+		writeJSONField(buf, key, keyvals[i+1])
+	}
+	buf.WriteString("}\n")
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err := l.w.Write(buf.Bytes())
+	return err
+}
+
+func writeJSONField(buf *bytes.Buffer, key string, value interface{}) {
+	if buf.Len() > 1 {
+		buf.WriteByte(',')
+	}
+	k, _ := json.Marshal(key)
+	buf.Write(k)
+	buf.WriteByte(':')
+	buf.Write(jsonValue(value))
+}
+
+// jsonValue marshals the value, the value which is not JSON serializable falls back to %v.
+func jsonValue(value interface{}) []byte {
+	if err, ok := value.(error); ok {
+		value = err.Error()
+	}
+	if data, err := json.Marshal(value); err == nil {
+		return data
+	}
+	data, _ := json.Marshal(fmt.Sprintf("%v", value))
+	return data
+}
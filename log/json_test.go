@@ -0,0 +1,59 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestJSONLogger(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := With(NewJSONLogger(buf, JSONTimeKey("")), "service", "kratos")
+	logger = NewFilter(logger, FilterLevel(LevelInfo))
+
+	_ = logger.Log(LevelDebug, "msg", "dropped")
+	_ = logger.Log(LevelInfo, "msg", "hello", "b", 1, "a", []int{1, 2})
+	expected := `{"level":"INFO","service":"kratos","msg":"hello","b":1,"a":[1,2]}` + "\n"
+	if buf.String() != expected {
+		t.Fatalf("expected %s, got %s", expected, buf.String())
+	}
+}
+
+func TestJSONLoggerKeys(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := NewJSONLogger(buf, JSONTimeKey("@timestamp"), JSONLevelKey("severity"), JSONMessageKey("message"))
+	_ = logger.Log(LevelWarn, "msg", "hello")
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["severity"] != "WARN" || m["message"] != "hello" || m["@timestamp"] == nil {
+		t.Fatalf("unexpected entry %s", buf.String())
+	}
+	if !strings.HasPrefix(buf.String(), `{"@timestamp":`) {
+		t.Fatalf("unexpected order %s", buf.String())
+	}
+}
+
+func TestJSONLoggerFallback(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := NewJSONLogger(buf, JSONTimeKey(""))
+	_ = logger.Log(LevelError, "err", errors.New("boom"), "ch", make(chan int), "odd")
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["err"] != "boom" {
+		t.Fatalf("unexpected err %v", m["err"])
+	}
+	if s, ok := m["ch"].(string); !ok || !strings.HasPrefix(s, "0x") {
+		t.Fatalf("unexpected ch %v", m["ch"])
+	}
+	if m["odd"] != "KEYVALS UNPAIRED" {
+		t.Fatalf("unexpected odd %v", m["odd"])
+	}
+}
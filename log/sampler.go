@@ -0,0 +1,232 @@
+package log
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _ Logger = (*Sampler)(nil)
+
+// SamplerOption is sampler option.
+type SamplerOption func(*Sampler)
+
+// SampleRate with the sample rate, 1 of every n identical messages in a window is logged.
+func SampleRate(n int) SamplerOption {
+	return func(s *Sampler) {
+		s.rate = n
+	}
+}
+
+// SampleWindow with the sample window, the suppressed count of a message is reported once its window ends,
+// even if the message is not logged again.
+func SampleWindow(d time.Duration) SamplerOption {
+	return func(s *Sampler) {
+		s.window = d
+	}
+}
+
+// SampleKey with the func which returns the key of the identical messages, default is MessageKey, e.g. CallSiteKey.
+func SampleKey(f func(level Level, keyvals ...interface{}) string) SamplerOption {
+	return func(s *Sampler) {
+		s.key = f
+	}
+}
+
+type sampleEntry struct {
+	level      Level
+	start      time.Time
+	count      int
+	suppressed int
+	msg        interface{}
+}
+
+// Sampler is a logger sampler which suppresses the high-volume identical messages.
+type Sampler struct {
+	logger Logger
+	rate   int
+	window time.Duration
+	key    func(level Level, keyvals ...interface{}) string
+
+	lock      sync.Mutex
+	entries   map[string]*sampleEntry
+	lastSweep time.Time
+	now       func() time.Time
+	// timer reports the suppressed counts of the ended windows, it is armed while any message is suppressed.
+	timer  *time.Timer
+	closed bool
+}
+
+// NewSampler new a logger sampler, the messages are identical if they are logged
+// at the same level with the same msg by default, so that the varying fields still collapse,
+// e.g. log.Warnw("msg", "zero endpoint found", "endpoint", e). Use SampleKey(CallSiteKey)
+// to collapse the formatted messages of a call site, e.g. log.Warnf("zero endpoint found: %s", e).
+func NewSampler(logger Logger, opts ...SamplerOption) *Sampler {
+	s := &Sampler{
+		logger:  logger,
+		rate:    100,
+		window:  time.Second,
+		key:     MessageKey,
+		entries: make(map[string]*sampleEntry),
+		now:     time.Now,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	if s.rate < 1 {
+		s.rate = 1
+	}
+	return s
+}
+
+// Log Print log by level and keyvals.
+func (s *Sampler) Log(level Level, keyvals ...interface{}) error {
+	key := s.key(level, keyvals...)
+	s.lock.Lock()
+	now := s.now()
+	summaries := s.sweep(now)
+	e, ok := s.entries[key]
+	if !ok || now.Sub(e.start) >= s.window {
+		if ok && e.suppressed > 0 {
+			summaries = append(summaries, *e)
+		}
+		e = &sampleEntry{level: level, start: now}
+		s.entries[key] = e
+	}
+	e.count++
+	pass := (e.count-1)%s.rate == 0
+	if pass {
+		e.msg = messageOf(keyvals)
+	} else {
+		e.suppressed++
+		if s.timer == nil && !s.closed {
+			s.timer = time.AfterFunc(e.start.Add(s.window).Sub(now), s.expire)
+		}
+	}
+	s.lock.Unlock()
+
+	for _, sum := range summaries {
+		if err := s.summary(sum); err != nil {
+			return err
+		}
+	}
+	if !pass {
+		return nil
+	}
+	return s.logger.Log(level, keyvals...)
+}
+
+// sweep drops the entries of the ended windows and returns the ones to report, at most once per window.
+func (s *Sampler) sweep(now time.Time) (summaries []sampleEntry) {
+	if now.Sub(s.lastSweep) < s.window {
+		return nil
+	}
+	s.lastSweep = now
+	for key, e := range s.entries {
+		if now.Sub(e.start) >= s.window {
+			if e.suppressed > 0 {
+				summaries = append(summaries, *e)
+			}
+			delete(s.entries, key)
+		}
+	}
+	return summaries
+}
+
+// expire reports the suppressed counts of the ended windows, and schedules the next window to end.
+func (s *Sampler) expire() {
+	s.lock.Lock()
+	s.timer = nil
+	if s.closed {
+		s.lock.Unlock()
+		return
+	}
+	now := s.now()
+	var (
+		summaries []sampleEntry
+		next      time.Time
+	)
+	for key, e := range s.entries {
+		end := e.start.Add(s.window)
+		if !now.Before(end) {
+			if e.suppressed > 0 {
+				summaries = append(summaries, *e)
+			}
+			delete(s.entries, key)
+			continue
+		}
+		if e.suppressed > 0 && (next.IsZero() || end.Before(next)) {
+			next = end
+		}
+	}
+	if !next.IsZero() {
+		s.timer = time.AfterFunc(next.Sub(now), s.expire)
+	}
+	s.lock.Unlock()
+	for _, sum := range summaries {
+		_ = s.summary(sum)
+	}
+}
+
+// Close stops the timer of the summaries, and reports the suppressed counts of the current windows.
+func (s *Sampler) Close() error {
+	s.lock.Lock()
+	s.closed = true
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	var summaries []sampleEntry
+	for key, e := range s.entries {
+		if e.suppressed > 0 {
+			summaries = append(summaries, *e)
+		}
+		delete(s.entries, key)
+	}
+	s.lock.Unlock()
+	for _, sum := range summaries {
+		if err := s.summary(sum); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Sampler) summary(e sampleEntry) error {
+	return s.logger.Log(e.level, "msg", fmt.Sprintf("suppressed %d messages", e.suppressed), "sample", e.msg)
+}
+
+func messageOf(keyvals []interface{}) interface{} {
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if keyvals[i] == "msg" {
+			return keyvals[i+1]
+		}
+	}
+	return fmt.Sprint(keyvals...)
+}
+
+// MessageKey returns the level and the msg of the keyvals, it is the default key of the Sampler.
+func MessageKey(level Level, keyvals ...interface{}) string {
+	return level.String() + " " + fmt.Sprint(messageOf(keyvals))
+}
+
+// CallSiteKey returns the level and the first caller outside of the log package, all messages of a call site are
+// identical, so it should not be used if the messages are logged by a shared call site, e.g. a logging middleware.
+func CallSiteKey(level Level, keyvals ...interface{}) string {
+	var pcs [16]uintptr
+	n := runtime.Callers(2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "github.com/go-kratos/kratos/v2/log.") || strings.HasSuffix(frame.File, "_test.go") {
+			return level.String() + " " + frame.File + ":" + strconv.Itoa(frame.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return MessageKey(level, keyvals...)
+}
@@ -0,0 +1,111 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSampler(t *testing.T) {
+	buf := new(bytes.Buffer)
+	now := time.Unix(1000, 0)
+	s := NewSampler(NewStdLogger(buf), SampleRate(3), SampleWindow(time.Second))
+	s.now = func() time.Time { return now }
+	log := NewHelper(s)
+
+	for i := 0; i < 7; i++ {
+		log.Warnw("msg", "zero endpoint found", "ins", i)
+	}
+	log.Info("other")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("want 4 lines, got: %q", lines)
+	}
+	for i, want := range []string{"ins=0", "ins=3", "ins=6", "msg=other"} {
+		if !strings.Contains(lines[i], want) {
+			t.Fatalf("line %d want %q, got %q", i, want, lines[i])
+		}
+	}
+
+	buf.Reset()
+	now = now.Add(time.Second)
+	log.Info("next")
+	if got := buf.String(); !strings.Contains(got, "WARN msg=suppressed 4 messages sample=zero endpoint found") {
+		t.Fatalf("want summary, got %q", got)
+	}
+}
+
+func TestSamplerKey(t *testing.T) {
+	buf := new(bytes.Buffer)
+	s := NewSampler(NewStdLogger(buf), SampleRate(2), SampleKey(func(level Level, keyvals ...interface{}) string {
+		return level.String()
+	}))
+	_ = s.Log(LevelInfo, "msg", "a")
+	_ = s.Log(LevelInfo, "msg", "b")
+	_ = s.Log(LevelWarn, "msg", "c")
+	if got := buf.String(); got != "INFO msg=a\nWARN msg=c\n" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestSamplerCallSiteKey(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewHelper(NewSampler(NewStdLogger(buf), SampleRate(3), SampleKey(CallSiteKey)))
+	for i := 0; i < 4; i++ {
+		log.Warnf("zero endpoint found, ins: %d", i)
+	}
+	for i := 0; i < 2; i++ {
+		log.Warnf("other, ins: %d", i)
+	}
+	if got := buf.String(); got != "WARN msg=zero endpoint found, ins: 0\nWARN msg=zero endpoint found, ins: 3\nWARN msg=other, ins: 0\n" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestSamplerMessageKey(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewHelper(NewSampler(NewStdLogger(buf), SampleRate(2)))
+	// the messages of a shared call site are not collapsed.
+	warn := func(msg string) { log.Warn(msg) }
+	warn("a")
+	warn("b")
+	warn("a")
+	if got := buf.String(); got != "WARN msg=a\nWARN msg=b\n" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestSamplerExpire(t *testing.T) {
+	sink := &blockingLogger{}
+	s := NewSampler(sink, SampleRate(2), SampleWindow(20*time.Millisecond))
+	defer s.Close()
+	for i := 0; i < 4; i++ {
+		_ = s.Log(LevelWarn, "msg", "zero endpoint found")
+	}
+	// the summary is reported once the window ends without any other log.
+	deadline := time.Now().Add(time.Second)
+	for !strings.Contains(sink.String(), "WARN msg=suppressed 2 messages sample=zero endpoint found") {
+		if time.Now().After(deadline) {
+			t.Fatalf("want summary, got %q", sink.String())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestSamplerClose(t *testing.T) {
+	sink := &blockingLogger{}
+	s := NewSampler(sink, SampleRate(2), SampleWindow(time.Hour))
+	for i := 0; i < 2; i++ {
+		_ = s.Log(LevelWarn, "msg", "zero endpoint found")
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := sink.String(); got != "WARN msg=zero endpoint found\nWARN msg=suppressed 1 messages sample=zero endpoint found\n" {
+		t.Fatalf("got %q", got)
+	}
+	if s.timer != nil {
+		t.Fatal("the timer is not stopped")
+	}
+}
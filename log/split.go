@@ -0,0 +1,29 @@
+package log
+
+import "io"
+
+var _ Logger = (*splitLogger)(nil)
+
+type splitLogger struct {
+	out       Logger
+	err       Logger
+	threshold Level
+}
+
+// NewSplitLogger new a logger which writes the logs below the threshold to out
+// and the others to err, e.g. NewSplitLogger(os.Stdout, os.Stderr, LevelWarn).
+func NewSplitLogger(out, err io.Writer, threshold Level) Logger {
+	return &splitLogger{
+		out:       NewStdLogger(out),
+		err:       NewStdLogger(err),
+		threshold: threshold,
+	}
+}
+
+// Log print the kv pairs log to the writer of the level.
+func (l *splitLogger) Log(level Level, keyvals ...interface{}) error {
+	if level >= l.threshold {
+		return l.err.Log(level, keyvals...)
+	}
+	return l.out.Log(level, keyvals...)
+}
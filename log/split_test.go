@@ -0,0 +1,26 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSplitLogger(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	log := NewHelper(NewFilter(NewSplitLogger(out, err, LevelWarn), FilterLevel(LevelDebug)))
+	log.Debug("debug log")
+	log.Info("info log")
+	log.Warn("warn log")
+	log.Error("error log")
+
+	if strings.Contains(out.String(), "warn log") || strings.Contains(out.String(), "error log") {
+		t.Fatalf("unexpected stdout %s", out.String())
+	}
+	if strings.Contains(err.String(), "info log") || strings.Contains(err.String(), "debug log") {
+		t.Fatalf("unexpected stderr %s", err.String())
+	}
+	if !strings.Contains(out.String(), "INFO msg=info log") || !strings.Contains(err.String(), "WARN msg=warn log") {
+		t.Fatalf("unexpected logs %s %s", out.String(), err.String())
+	}
+}
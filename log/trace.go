@@ -0,0 +1,62 @@
+package log
+
+import "context"
+
+// TraceExtractor extracts the trace id and span id from the context.
+type TraceExtractor func(ctx context.Context) (traceID, spanID string)
+
+// TraceOption is trace logger option.
+type TraceOption func(*traceOptions)
+
+type traceOptions struct {
+	traceKey  string
+	spanKey   string
+	extractor TraceExtractor
+}
+
+// TraceKeys with the log keys of the trace id and span id, default is trace_id and span_id.
+func TraceKeys(traceKey, spanKey string) TraceOption {
+	return func(o *traceOptions) {
+		o.traceKey = traceKey
+		o.spanKey = spanKey
+	}
+}
+
+// WithTraceExtractor with the trace id and span id extractor, default is the TraceID and SpanID valuers.
+func WithTraceExtractor(e TraceExtractor) TraceOption {
+	return func(o *traceOptions) {
+		o.extractor = e
+	}
+}
+
+// WithTrace returns a logger which adds the TraceID and SpanID of the context to every log,
+// the context is bound by WithContext or Helper.WithContext.
+func WithTrace(l Logger, opts ...TraceOption) Logger {
+	o := traceOptions{
+		traceKey: "trace_id",
+		spanKey:  "span_id",
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.extractor == nil {
+		return With(l, o.traceKey, TraceID(), o.spanKey, SpanID())
+	}
+	return With(l,
+		o.traceKey, Valuer(func(ctx context.Context) interface{} {
+			traceID, _ := extract(ctx, o.extractor)
+			return traceID
+		}),
+		o.spanKey, Valuer(func(ctx context.Context) interface{} {
+			_, spanID := extract(ctx, o.extractor)
+			return spanID
+		}),
+	)
+}
+
+func extract(ctx context.Context, e TraceExtractor) (string, string) {
+	if ctx == nil {
+		return "", ""
+	}
+	return e(ctx)
+}
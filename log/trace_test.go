@@ -0,0 +1,44 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestWithTrace(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewHelper(WithTrace(NewStdLogger(buf)))
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+	log.WithContext(ctx).Info("hello")
+	log.Info("no context")
+	want := "INFO trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7 msg=hello\n" +
+		"INFO trace_id= span_id= msg=no context\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("want %q, got %q", want, got)
+	}
+}
+
+func TestWithTraceOptions(t *testing.T) {
+	type key struct{}
+	buf := new(bytes.Buffer)
+	log := NewHelper(WithTrace(NewStdLogger(buf),
+		TraceKeys("tid", "sid"),
+		WithTraceExtractor(func(ctx context.Context) (string, string) {
+			id, _ := ctx.Value(key{}).(string)
+			return id, id + "-span"
+		}),
+	))
+	log.WithContext(context.WithValue(context.Background(), key{}, "abc")).Info("hello")
+	if got, want := buf.String(), "INFO tid=abc sid=abc-span msg=hello\n"; got != want {
+		t.Fatalf("want %q, got %q", want, got)
+	}
+}
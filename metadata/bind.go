@@ -0,0 +1,185 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned by the typed getters when the key is absent.
+var ErrNotFound = errors.New("metadata: key not found")
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// GetInt returns the value associated with the passed key as an int64.
+func (m Metadata) GetInt(key string) (int64, error) {
+	v, ok := m.lookup(key)
+	if !ok {
+		return 0, ErrNotFound
+	}
+	return strconv.ParseInt(v, 10, 64)
+}
+
+// GetBool returns the value associated with the passed key as a bool, e.g. "true", "1" or "false".
+func (m Metadata) GetBool(key string) (bool, error) {
+	v, ok := m.lookup(key)
+	if !ok {
+		return false, ErrNotFound
+	}
+	return strconv.ParseBool(v)
+}
+
+// GetTime returns the value associated with the passed key as a time, which is formatted as RFC 3339.
+func (m Metadata) GetTime(key string) (time.Time, error) {
+	v, ok := m.lookup(key)
+	if !ok {
+		return time.Time{}, ErrNotFound
+	}
+	return time.Parse(time.RFC3339Nano, v)
+}
+
+func (m Metadata) lookup(key string) (string, bool) {
+	v, ok := m[strings.ToLower(key)]
+	return v, ok && v != ""
+}
+
+// Bind binds the server metadata of ctx to the struct pointed to by v, the fields are mapped by the md tag,
+// e.g. `md:"x-md-global-tenant"`, and the fields without the tag are skipped. The multiple values of a key
+// are joined by commas, the slice fields get all of them, and the other fields get the joined value
+// unless the tag has the first option, e.g. `md:"x-md-global-role,first"`.
+func Bind(ctx context.Context, v interface{}) error {
+	md, _ := FromServerContext(ctx)
+	return md.Bind(v)
+}
+
+// Bind binds the metadata to the struct pointed to by v as the Bind function.
+func (m Metadata) Bind(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("metadata: bind target must be a non-nil struct pointer, got %T", v)
+	}
+	return m.bindStruct(rv.Elem())
+}
+
+func (m Metadata) bindStruct(rv reflect.Value) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		tag, ok := field.Tag.Lookup("md")
+		if !ok {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				if err := m.bindStruct(rv.Field(i)); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		name, opts := tag, ""
+		if i := strings.IndexByte(tag, ','); i >= 0 {
+			name, opts = tag[:i], tag[i+1:]
+		}
+		if name == "" || name == "-" {
+			continue
+		}
+		value, ok := m.lookup(name)
+		if !ok {
+			continue
+		}
+		if err := setField(rv.Field(i), value, opts == "first"); err != nil {
+			return fmt.Errorf("metadata: bind %s to field %s: %w", name, field.Name, err)
+		}
+	}
+	return nil
+}
+
+func setField(fv reflect.Value, value string, first bool) error {
+	if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+		values := splitValues(value)
+		slice := reflect.MakeSlice(fv.Type(), len(values), len(values))
+		for i, v := range values {
+			if err := setValue(slice.Index(i), v); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+		return nil
+	}
+	if first {
+		value = splitValues(value)[0]
+	}
+	return setValue(fv, value)
+}
+
+func splitValues(value string) []string {
+	values := strings.Split(value, ",")
+	for i, v := range values {
+		values[i] = strings.TrimSpace(v)
+	}
+	return values
+}
+
+func setValue(fv reflect.Value, value string) error {
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		return setValue(fv.Elem(), value)
+	}
+	switch fv.Type() {
+	case timeType:
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(t))
+		return nil
+	case durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %s", fv.Type())
+	}
+	return nil
+}
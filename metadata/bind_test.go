@@ -0,0 +1,96 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTypedGetters(t *testing.T) {
+	md := New(map[string]string{
+		"x-md-count": "42",
+		"x-md-flag":  "true",
+		"x-md-time":  "2021-08-01T10:00:00Z",
+		"x-md-bad":   "foo",
+	})
+	if v, err := md.GetInt("X-Md-Count"); err != nil || v != 42 {
+		t.Errorf("GetInt() = %v, %v, want 42", v, err)
+	}
+	if v, err := md.GetBool("x-md-flag"); err != nil || !v {
+		t.Errorf("GetBool() = %v, %v, want true", v, err)
+	}
+	want := time.Date(2021, 8, 1, 10, 0, 0, 0, time.UTC)
+	if v, err := md.GetTime("x-md-time"); err != nil || !v.Equal(want) {
+		t.Errorf("GetTime() = %v, %v, want %v", v, err, want)
+	}
+	if _, err := md.GetInt("x-md-bad"); err == nil {
+		t.Errorf("GetInt() want an error for an invalid value")
+	}
+	if _, err := md.GetBool("x-md-missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetBool() error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+type Common struct {
+	Tenant string `md:"x-md-global-tenant"`
+}
+
+type bindTarget struct {
+	Common
+	UID     *int64        `md:"x-md-global-uid"`
+	Roles   []string      `md:"x-md-global-roles"`
+	Role    string        `md:"x-md-global-roles,first"`
+	Joined  string        `md:"x-md-global-roles"`
+	Beta    bool          `md:"x-md-global-beta"`
+	Timeout time.Duration `md:"x-md-timeout"`
+	Weights []float64     `md:"x-md-weights"`
+	Missing string        `md:"x-md-missing"`
+	Skipped string        `md:"-"`
+	NoTag   string
+}
+
+func TestBind(t *testing.T) {
+	md := New(map[string]string{
+		"x-md-global-tenant": "kratos",
+		"x-md-global-uid":    "1024",
+		"x-md-global-roles":  "admin, dev",
+		"x-md-global-beta":   "1",
+		"x-md-timeout":       "1.5s",
+		"x-md-weights":       "0.5,1",
+		"notag":              "foo",
+	})
+	var v bindTarget
+	if err := Bind(NewServerContext(context.Background(), md), &v); err != nil {
+		t.Fatalf("Bind() error = %v", err)
+	}
+	uid := int64(1024)
+	want := bindTarget{
+		Common:  Common{Tenant: "kratos"},
+		UID:     &uid,
+		Roles:   []string{"admin", "dev"},
+		Role:    "admin",
+		Joined:  "admin, dev",
+		Beta:    true,
+		Timeout: 1500 * time.Millisecond,
+		Weights: []float64{0.5, 1},
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Bind() = %+v, want %+v", v, want)
+	}
+}
+
+func TestBindError(t *testing.T) {
+	var v bindTarget
+	if err := Bind(context.Background(), v); err == nil {
+		t.Errorf("Bind() want an error for a non-pointer")
+	}
+	if err := Bind(context.Background(), &v); err != nil {
+		t.Errorf("Bind() error = %v without metadata", err)
+	}
+	md := New(map[string]string{"x-md-global-uid": "foo"})
+	if err := md.Bind(&v); err == nil {
+		t.Errorf("Bind() want an error for an invalid value")
+	}
+}
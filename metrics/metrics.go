@@ -20,3 +20,10 @@ type Observer interface {
 	With(lvs ...string) Observer
 	Observe(float64)
 }
+
+// ExemplarObserver is an observer which also supports the exemplars, e.g. the Prometheus histograms.
+// The observer returned by With should implement it to link the observations with the traces.
+type ExemplarObserver interface {
+	Observer
+	ObserveWithExemplar(value float64, exemplar map[string]string)
+}
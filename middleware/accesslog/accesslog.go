@@ -0,0 +1,174 @@
+package accesslog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/http"
+
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Format is the format of the access log lines.
+type Format int
+
+const (
+	// Combined is the Apache Combined Log Format followed by the latency in seconds.
+	Combined Format = iota
+	// JSON is one JSON object per line.
+	JSON
+)
+
+const combinedTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// Option is access log option.
+type Option func(*options)
+
+type options struct {
+	format Format
+	now    func() time.Time
+}
+
+// WithFormat with the format of the lines, default is Combined.
+func WithFormat(f Format) Option {
+	return func(o *options) {
+		o.format = f
+	}
+}
+
+// Record is the access record of a request.
+// The status of an HTTP request is the HTTP status code, and the one of a gRPC request is the gRPC status code,
+// the method of a gRPC request is POST and its path is the full method name.
+// The bytes is the encoded size of the reply, which is 0 for a failed request and -1 for a streaming reply.
+type Record struct {
+	Time       time.Time `json:"time"`
+	Kind       string    `json:"kind"`
+	RemoteAddr string    `json:"remote_addr"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	Proto      string    `json:"proto"`
+	Status     int       `json:"status"`
+	Bytes      int       `json:"bytes"`
+	Latency    float64   `json:"latency"`
+	Referer    string    `json:"referer"`
+	UserAgent  string    `json:"user_agent"`
+}
+
+// Server is a server middleware which writes one access log line per request to w,
+// independently of the application logger. The lines are written by a single Write each and serialized.
+func Server(w io.Writer, opts ...Option) middleware.Middleware {
+	o := &options{format: Combined, now: time.Now}
+	for _, opt := range opts {
+		opt(o)
+	}
+	var mu sync.Mutex
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (reply interface{}, err error) {
+			start := o.now()
+			reply, err = handler(ctx, req)
+			r := newRecord(ctx, reply, err)
+			r.Time = start
+			r.Latency = o.now().Sub(start).Seconds()
+			line := o.format.line(r)
+			mu.Lock()
+			_, _ = w.Write(line)
+			mu.Unlock()
+			return
+		}
+	}
+}
+
+func newRecord(ctx context.Context, reply interface{}, err error) *Record {
+	r := &Record{}
+	tr, ok := transport.FromServerContext(ctx)
+	if !ok {
+		return r
+	}
+	r.Kind = tr.Kind().String()
+	r.UserAgent = tr.RequestHeader().Get("User-Agent")
+	if ht, ok := tr.(*http.Transport); ok && ht.Request() != nil {
+		req := ht.Request()
+		r.RemoteAddr = req.RemoteAddr
+		r.Method = req.Method
+		r.Path = req.URL.RequestURI()
+		r.Proto = req.Proto
+		r.Referer = req.Referer()
+		r.Status = errors.Code(err)
+	} else {
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			r.RemoteAddr = p.Addr.String()
+		}
+		r.Method = "POST"
+		r.Path = tr.Operation()
+		r.Proto = "HTTP/2.0"
+		r.Status = int(status.Code(err))
+	}
+	if err == nil {
+		r.Bytes = replySize(reply)
+	}
+	return r
+}
+
+// replySize returns the encoded size of the reply, the proto messages are
+// sized by the wire format and the others by the JSON encoding.
+func replySize(v interface{}) int {
+	if v == nil {
+		return 0
+	}
+	if m, ok := v.(proto.Message); ok {
+		return proto.Size(m)
+	}
+	if _, ok := v.(io.Reader); ok {
+		return -1
+	}
+	if t := reflect.TypeOf(v); t.Kind() == reflect.Chan {
+		return -1
+	}
+	data, err := encoding.GetCodec("json").Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
+
+func (f Format) line(r *Record) []byte {
+	if f == JSON {
+		data, err := json.Marshal(r)
+		if err != nil {
+			return nil
+		}
+		return append(data, '\n')
+	}
+	bytes := "-"
+	if r.Bytes > 0 {
+		bytes = strconv.Itoa(r.Bytes)
+	}
+	host := r.RemoteAddr
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return []byte(fmt.Sprintf("%s - - [%s] \"%s %s %s\" %d %s %s %s %.6f\n",
+		dash(host), r.Time.Format(combinedTimeLayout), r.Method, r.Path, r.Proto,
+		r.Status, bytes, strconv.Quote(dash(r.Referer)), strconv.Quote(dash(r.UserAgent)), r.Latency,
+	))
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
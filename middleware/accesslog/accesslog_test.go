@@ -0,0 +1,99 @@
+package accesslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/peer"
+)
+
+var testTime = time.Date(2021, 8, 1, 12, 0, 0, 0, time.UTC)
+
+func fixedNow(o *options) {
+	o.now = func() time.Time { return testTime }
+}
+
+func serveHTTP(t *testing.T, m middleware.Middleware, req *http.Request, err error) {
+	srv := khttp.NewServer(khttp.Middleware(m))
+	_, e := srv.Endpoint()
+	assert.NoError(t, e)
+	srv.Route("/").GET("/users/{id}", func(ctx khttp.Context) error {
+		h := ctx.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return map[string]string{"id": "1"}, err
+		})
+		_, err := h(ctx, nil)
+		return err
+	})
+	srv.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestServerHTTP(t *testing.T) {
+	var buf bytes.Buffer
+	req := httptest.NewRequest(http.MethodGet, "/users/1?v=1", nil)
+	req.Header.Set("User-Agent", "curl/7.64.1")
+	req.Header.Set("Referer", "http://example.com")
+	serveHTTP(t, Server(&buf, fixedNow), req, nil)
+	assert.Equal(t, "192.0.2.1 - - [01/Aug/2021:12:00:00 +0000] \"GET /users/1?v=1 HTTP/1.1\" 200 10 \"http://example.com\" \"curl/7.64.1\" 0.000000\n", buf.String())
+
+	buf.Reset()
+	serveHTTP(t, Server(&buf, fixedNow, WithFormat(JSON)), httptest.NewRequest(http.MethodGet, "/users/1", nil), errors.NotFound("USER_NOT_FOUND", "user not found"))
+	var r Record
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &r))
+	assert.Equal(t, Record{
+		Time:       testTime,
+		Kind:       "http",
+		RemoteAddr: "192.0.2.1:1234",
+		Method:     http.MethodGet,
+		Path:       "/users/1",
+		Proto:      "HTTP/1.1",
+		Status:     http.StatusNotFound,
+	}, r)
+}
+
+type testTransport struct {
+	header headerCarrier
+}
+
+func (tr *testTransport) Kind() transport.Kind            { return transport.KindGRPC }
+func (tr *testTransport) Endpoint() string                { return "" }
+func (tr *testTransport) Operation() string               { return "/helloworld.Greeter/SayHello" }
+func (tr *testTransport) RequestHeader() transport.Header { return tr.header }
+func (tr *testTransport) ReplyHeader() transport.Header   { return headerCarrier{} }
+
+type headerCarrier map[string]string
+
+func (h headerCarrier) Get(key string) string { return h[key] }
+func (h headerCarrier) Set(key, value string) { h[key] = value }
+func (h headerCarrier) Keys() []string        { return nil }
+
+func TestServerGRPC(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := transport.NewServerContext(context.Background(), &testTransport{header: headerCarrier{"User-Agent": "grpc-go/1.39.1"}})
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5000}})
+	_, _ = Server(&buf, fixedNow, WithFormat(JSON))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, errors.Unauthorized("UNAUTHORIZED", "")
+	})(ctx, nil)
+	var r Record
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &r))
+	assert.Equal(t, Record{
+		Time:       testTime,
+		Kind:       "grpc",
+		RemoteAddr: "10.0.0.1:5000",
+		Method:     http.MethodPost,
+		Path:       "/helloworld.Greeter/SayHello",
+		Proto:      "HTTP/2.0",
+		Status:     16,
+		UserAgent:  "grpc-go/1.39.1",
+	}, r)
+}
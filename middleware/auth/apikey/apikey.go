@@ -0,0 +1,99 @@
+package apikey
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+const (
+	// Reason is the error reason of the missing or invalid API keys.
+	Reason = "UNAUTHORIZED"
+
+	// defaultHeader is the default header of the API key.
+	defaultHeader = "x-api-key"
+)
+
+var (
+	// ErrMissingKey is returned when the request has no API key.
+	ErrMissingKey = errors.Unauthorized(Reason, "API key is missing")
+	// ErrInvalidKey is returned when the API key is rejected by the validator.
+	ErrInvalidKey = errors.Unauthorized(Reason, "API key is invalid")
+)
+
+// Validator validates the API key and returns the principal of the key,
+// the error is returned as ErrInvalidKey unless it is a kratos error.
+type Validator func(ctx context.Context, key string) (principal interface{}, err error)
+
+// Option is API key option.
+type Option func(*options)
+
+type options struct {
+	header string
+	skip   map[string]struct{}
+}
+
+// WithHeader with the request header or the gRPC metadata key of the API key, default is "x-api-key".
+func WithHeader(key string) Option {
+	return func(o *options) {
+		o.header = key
+	}
+}
+
+// WithSkip with the operations which are not authenticated, e.g. the health checks.
+func WithSkip(operations ...string) Option {
+	return func(o *options) {
+		for _, op := range operations {
+			o.skip[op] = struct{}{}
+		}
+	}
+}
+
+type principalKey struct{}
+
+// NewContext creates a new context with the principal.
+func NewContext(ctx context.Context, principal interface{}) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// FromContext returns the principal of the API key in ctx if it exists.
+func FromContext(ctx context.Context) (principal interface{}, ok bool) {
+	principal = ctx.Value(principalKey{})
+	return principal, principal != nil
+}
+
+// Server is a server middleware which authenticates the requests by the API key.
+func Server(validate Validator, opts ...Option) middleware.Middleware {
+	o := &options{
+		header: defaultHeader,
+		skip:   make(map[string]struct{}),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok {
+				return nil, ErrMissingKey
+			}
+			if _, ok := o.skip[tr.Operation()]; ok {
+				return handler(ctx, req)
+			}
+			key := tr.RequestHeader().Get(o.header)
+			if key == "" {
+				return nil, ErrMissingKey
+			}
+			principal, err := validate(ctx, key)
+			if err != nil {
+				if se := new(errors.Error); errors.As(err, &se) {
+					return nil, se
+				}
+				return nil, ErrInvalidKey
+			}
+			return handler(NewContext(ctx, principal), req)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/stretchr/testify/assert"
+)
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string { return http.Header(hc).Get(key) }
+
+func (hc headerCarrier) Set(key string, value string) { http.Header(hc).Set(key, value) }
+
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range http.Header(hc) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+type testTransport struct {
+	operation string
+	header    headerCarrier
+}
+
+func (tr *testTransport) Kind() transport.Kind            { return transport.KindHTTP }
+func (tr *testTransport) Endpoint() string                { return "" }
+func (tr *testTransport) Operation() string               { return tr.operation }
+func (tr *testTransport) RequestHeader() transport.Header { return tr.header }
+func (tr *testTransport) ReplyHeader() transport.Header   { return tr.header }
+
+func TestServer(t *testing.T) {
+	keys := map[string]string{"key1": "alice"}
+	validate := func(ctx context.Context, key string) (interface{}, error) {
+		if key == "banned" {
+			return nil, kerrors.Forbidden("BANNED", "banned")
+		}
+		if p, ok := keys[key]; ok {
+			return p, nil
+		}
+		return nil, errors.New("not found")
+	}
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		p, _ := FromContext(ctx)
+		return p, nil
+	}
+	h := Server(validate, WithHeader("X-Token"), WithSkip("/health"))(next)
+
+	tests := []struct {
+		name      string
+		operation string
+		key       string
+		reply     interface{}
+		code      int
+	}{
+		{"valid", "/api", "key1", "alice", 0},
+		{"missing", "/api", "", nil, 401},
+		{"invalid", "/api", "key2", nil, 401},
+		{"custom error", "/api", "banned", nil, 403},
+		{"skip", "/health", "", nil, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			header := headerCarrier{}
+			header.Set("X-Token", test.key)
+			ctx := transport.NewServerContext(context.Background(), &testTransport{operation: test.operation, header: header})
+			reply, err := h(ctx, nil)
+			assert.Equal(t, test.reply, reply)
+			if test.code == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, int32(test.code), kerrors.FromError(err).Code)
+		})
+	}
+}
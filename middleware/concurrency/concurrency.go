@@ -0,0 +1,158 @@
+package concurrency
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Reason is the error reason when the concurrency limit is exceeded.
+const Reason = "OVERLOADED"
+
+// ErrOverloaded is returned when the queue is full or the wait times out.
+var ErrOverloaded = errors.New(503, Reason, "service unavailable due to concurrency limit exceeded")
+
+// KeyFunc returns the key of the request, the requests of a key share a limit.
+type KeyFunc func(ctx context.Context, req interface{}) string
+
+// Option is concurrency limit option.
+type Option func(*options)
+
+type options struct {
+	max     int
+	queue   int
+	maxWait time.Duration
+	key     KeyFunc
+}
+
+// WithMax with the max in-flight requests of each key, default is 100.
+func WithMax(n int) Option {
+	return func(o *options) {
+		o.max = n
+	}
+}
+
+// WithQueue with the max requests of each key waiting for a slot, default is 0, which rejects
+// the requests beyond the max at once.
+func WithQueue(n int) Option {
+	return func(o *options) {
+		o.queue = n
+	}
+}
+
+// WithMaxWait with the max wait of a queued request, default is 0, which waits until the request context is done.
+func WithMaxWait(d time.Duration) Option {
+	return func(o *options) {
+		o.maxWait = d
+	}
+}
+
+// WithKey with the key function, default is a single limit for all requests.
+// The keys should be bounded, since the limit of a key is never dropped.
+func WithKey(f KeyFunc) Option {
+	return func(o *options) {
+		o.key = f
+	}
+}
+
+// KeyByOperation returns the key function which limits every operation separately.
+func KeyByOperation() KeyFunc {
+	return func(ctx context.Context, req interface{}) string {
+		if tr, ok := transport.FromServerContext(ctx); ok {
+			return tr.Operation()
+		}
+		return ""
+	}
+}
+
+// Server is a server middleware which caps the in-flight requests of each key, e.g. to protect a database
+// connection pool. The requests beyond the max are queued up to the queue size, and the requests
+// which find the queue full or exceed the max wait fail with an OVERLOADED error.
+func Server(opts ...Option) middleware.Middleware {
+	o := options{max: 100}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.max <= 0 {
+		o.max = 100
+	}
+	var (
+		lock     sync.Mutex
+		limiters = make(map[string]*limiter)
+	)
+	get := func(key string) *limiter {
+		lock.Lock()
+		defer lock.Unlock()
+		l, ok := limiters[key]
+		if !ok {
+			l = &limiter{slots: make(chan struct{}, o.max), queue: o.queue, maxWait: o.maxWait}
+			limiters[key] = l
+		}
+		return l
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			var key string
+			if o.key != nil {
+				key = o.key(ctx, req)
+			}
+			l := get(key)
+			if err := l.acquire(ctx); err != nil {
+				return nil, err
+			}
+			defer l.release()
+			return handler(ctx, req)
+		}
+	}
+}
+
+type limiter struct {
+	slots   chan struct{}
+	lock    sync.Mutex
+	waiting int
+	queue   int
+	maxWait time.Duration
+}
+
+// acquire takes a slot, waiting in the queue if all the slots are taken.
+func (l *limiter) acquire(ctx context.Context) error {
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	default:
+	}
+	l.lock.Lock()
+	if l.waiting >= l.queue {
+		l.lock.Unlock()
+		return ErrOverloaded
+	}
+	l.waiting++
+	l.lock.Unlock()
+	defer func() {
+		l.lock.Lock()
+		l.waiting--
+		l.lock.Unlock()
+	}()
+	var timeout <-chan time.Time
+	if l.maxWait > 0 {
+		timer := time.NewTimer(l.maxWait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-timeout:
+		return ErrOverloaded
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *limiter) release() {
+	<-l.slots
+}
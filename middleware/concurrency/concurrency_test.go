@@ -0,0 +1,64 @@
+package concurrency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 10)
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		started <- struct{}{}
+		<-release
+		return "reply", nil
+	}
+	h := Server(WithMax(1), WithQueue(1), WithMaxWait(time.Second), WithKey(func(ctx context.Context, req interface{}) string {
+		return req.(string)
+	}))(next)
+
+	done := make(chan error, 2)
+	go func() { _, err := h(context.Background(), "a"); done <- err }()
+	<-started
+	// the second request is queued, and the third one finds the queue full.
+	go func() { _, err := h(context.Background(), "a"); done <- err }()
+	time.Sleep(20 * time.Millisecond)
+	_, err := h(context.Background(), "a")
+	se := errors.FromError(err)
+	assert.Equal(t, int32(503), se.Code)
+	assert.Equal(t, Reason, se.Reason)
+
+	// the other key has its own limit.
+	go func() { _, _ = h(context.Background(), "b") }()
+	<-started
+
+	close(release)
+	assert.NoError(t, <-done)
+	assert.NoError(t, <-done)
+}
+
+func TestMaxWait(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		<-release
+		return nil, nil
+	}
+	h := Server(WithMax(1), WithQueue(1), WithMaxWait(10*time.Millisecond))(next)
+	go func() { _, _ = h(context.Background(), nil) }()
+	time.Sleep(10 * time.Millisecond)
+	_, err := h(context.Background(), nil)
+	assert.True(t, errors.Is(err, ErrOverloaded))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	h = Server(WithMax(1), WithQueue(1))(next)
+	go func() { _, _ = h(context.Background(), nil) }()
+	time.Sleep(10 * time.Millisecond)
+	_, err = h(ctx, nil)
+	assert.Equal(t, context.Canceled, err)
+}
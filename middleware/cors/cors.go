@@ -0,0 +1,193 @@
+package cors
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Option is CORS option.
+type Option func(*options)
+
+type options struct {
+	origins     []string
+	originFunc  func(origin string) bool
+	methods     []string
+	headers     []string
+	exposed     []string
+	credentials bool
+	maxAge      time.Duration
+}
+
+// WithOrigins with the allowed origins, e.g. "https://example.com", "https://*.example.com" or "*" for any origin.
+// The "*" is ignored if the credentials are allowed, so the credentialed requests are only allowed from the
+// listed or matched origins.
+func WithOrigins(origins ...string) Option {
+	return func(o *options) {
+		o.origins = origins
+	}
+}
+
+// WithOriginFunc with the matcher of the allowed origins, which is checked after the origins.
+func WithOriginFunc(fn func(origin string) bool) Option {
+	return func(o *options) {
+		o.originFunc = fn
+	}
+}
+
+// WithMethods with the allowed methods, default is GET, HEAD, POST, PUT, PATCH and DELETE.
+func WithMethods(methods ...string) Option {
+	return func(o *options) {
+		o.methods = methods
+	}
+}
+
+// WithHeaders with the allowed request headers, "*" allows any header,
+// default is Accept, Accept-Language, Content-Language, Content-Type and Authorization.
+func WithHeaders(headers ...string) Option {
+	return func(o *options) {
+		o.headers = headers
+	}
+}
+
+// WithExposedHeaders with the response headers which are exposed to the browser scripts.
+func WithExposedHeaders(headers ...string) Option {
+	return func(o *options) {
+		o.exposed = headers
+	}
+}
+
+// WithCredentials with whether the requests with the credentials, e.g. the cookies, are allowed.
+func WithCredentials(allow bool) Option {
+	return func(o *options) {
+		o.credentials = allow
+	}
+}
+
+// WithMaxAge with how long the preflight results may be cached, zero omits it.
+func WithMaxAge(maxAge time.Duration) Option {
+	return func(o *options) {
+		o.maxAge = maxAge
+	}
+}
+
+// Filter is an HTTP filter which handles the CORS requests, the preflight requests are answered
+// by the filter without reaching the handlers. The origins are not allowed by default.
+func Filter(opts ...Option) func(http.Handler) http.Handler {
+	o := &options{
+		methods: []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete},
+		headers: []string{"Accept", "Accept-Language", "Content-Language", "Content-Type", "Authorization"},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			origin := req.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, req)
+				return
+			}
+			h := w.Header()
+			if !o.anyOrigin() {
+				h.Add("Vary", "Origin")
+			}
+			if req.Method == http.MethodOptions && req.Header.Get("Access-Control-Request-Method") != "" {
+				o.preflight(h, req, origin)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			if o.allowOrigin(origin) {
+				o.setOrigin(h, origin)
+				if len(o.exposed) > 0 {
+					h.Set("Access-Control-Expose-Headers", strings.Join(o.exposed, ", "))
+				}
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+// preflight sets the headers of the preflight response, they are omitted if the request is not allowed.
+func (o *options) preflight(h http.Header, req *http.Request, origin string) {
+	h.Add("Vary", "Access-Control-Request-Method")
+	h.Add("Vary", "Access-Control-Request-Headers")
+	if !o.allowOrigin(origin) {
+		return
+	}
+	method := req.Header.Get("Access-Control-Request-Method")
+	if !contains(o.methods, method) {
+		return
+	}
+	var headers []string
+	for _, header := range strings.Split(req.Header.Get("Access-Control-Request-Headers"), ",") {
+		if header = strings.TrimSpace(header); header == "" {
+			continue
+		}
+		if !contains(o.headers, "*") && !contains(o.headers, header) {
+			return
+		}
+		headers = append(headers, header)
+	}
+	o.setOrigin(h, origin)
+	h.Set("Access-Control-Allow-Methods", strings.Join(o.methods, ", "))
+	if len(headers) > 0 {
+		// the requested headers are echoed, which also works for "*" with the credentials.
+		h.Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+	}
+	if o.maxAge > 0 {
+		h.Set("Access-Control-Max-Age", strconv.Itoa(int(o.maxAge/time.Second)))
+	}
+}
+
+func (o *options) setOrigin(h http.Header, origin string) {
+	if o.anyOrigin() {
+		h.Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+	h.Set("Access-Control-Allow-Origin", origin)
+	if o.credentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// anyOrigin reports whether any origin is allowed without the credentials.
+func (o *options) anyOrigin() bool {
+	return !o.credentials && contains(o.origins, "*")
+}
+
+func (o *options) allowOrigin(origin string) bool {
+	for _, allowed := range o.origins {
+		if allowed == "*" {
+			if !o.credentials {
+				return true
+			}
+			continue
+		}
+		if matchOrigin(allowed, origin) {
+			return true
+		}
+	}
+	return o.originFunc != nil && o.originFunc(origin)
+}
+
+// matchOrigin matches the origin with the pattern, the "*" of the pattern matches any subdomain.
+func matchOrigin(pattern, origin string) bool {
+	pattern, origin = strings.ToLower(pattern), strings.ToLower(origin)
+	i := strings.IndexByte(pattern, '*')
+	if i < 0 {
+		return pattern == origin
+	}
+	prefix, suffix := pattern[:i], pattern[i+1:]
+	return len(origin) > len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func serve(f func(http.Handler) http.Handler, method string, header http.Header) (*httptest.ResponseRecorder, bool) {
+	var reached bool
+	h := f(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+		w.WriteHeader(http.StatusOK)
+	}))
+	req := httptest.NewRequest(method, "/v1/hello", nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w, reached
+}
+
+func TestPreflight(t *testing.T) {
+	f := Filter(
+		WithOrigins("https://example.com", "https://*.kratos.dev"),
+		WithMethods(http.MethodGet, http.MethodPost),
+		WithHeaders("Content-Type", "X-Request-Id"),
+		WithMaxAge(time.Hour),
+	)
+	w, reached := serve(f, http.MethodOptions, http.Header{
+		"Origin":                         {"https://api.kratos.dev"},
+		"Access-Control-Request-Method":  {"POST"},
+		"Access-Control-Request-Headers": {"content-type, x-request-id"},
+	})
+	assert.False(t, reached)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://api.kratos.dev", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, POST", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "content-type, x-request-id", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "3600", w.Header().Get("Access-Control-Max-Age"))
+	assert.Contains(t, w.Header().Values("Vary"), "Origin")
+
+	for _, header := range []http.Header{
+		{"Origin": {"https://evil.com"}, "Access-Control-Request-Method": {"GET"}},
+		{"Origin": {"https://kratos.dev"}, "Access-Control-Request-Method": {"GET"}},
+		{"Origin": {"https://example.com"}, "Access-Control-Request-Method": {"DELETE"}},
+		{"Origin": {"https://example.com"}, "Access-Control-Request-Method": {"GET"}, "Access-Control-Request-Headers": {"X-Other"}},
+	} {
+		w, reached := serve(f, http.MethodOptions, header)
+		assert.False(t, reached)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"), header)
+	}
+}
+
+func TestActualRequest(t *testing.T) {
+	f := Filter(WithOrigins("*"), WithExposedHeaders("X-Total"))
+	w, reached := serve(f, http.MethodGet, http.Header{"Origin": {"https://any.com"}})
+	assert.True(t, reached)
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "X-Total", w.Header().Get("Access-Control-Expose-Headers"))
+	assert.Empty(t, w.Header().Get("Vary"))
+
+	// not a CORS request.
+	w, reached = serve(f, http.MethodOptions, nil)
+	assert.True(t, reached)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCredentials(t *testing.T) {
+	f := Filter(
+		WithOrigins("*"),
+		WithOriginFunc(func(origin string) bool { return strings.HasSuffix(origin, ".trusted.com") }),
+		WithCredentials(true),
+	)
+	// the wildcard origin is disallowed with the credentials.
+	w, reached := serve(f, http.MethodGet, http.Header{"Origin": {"https://any.com"}, "Cookie": {"session=1"}})
+	assert.True(t, reached)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+
+	w, _ = serve(f, http.MethodGet, http.Header{"Origin": {"https://app.trusted.com"}, "Cookie": {"session=1"}})
+	assert.Equal(t, "https://app.trusted.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "Origin", w.Header().Get("Vary"))
+
+	w, _ = serve(f, http.MethodOptions, http.Header{"Origin": {"https://any.com"}, "Access-Control-Request-Method": {"GET"}})
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
@@ -0,0 +1,235 @@
+package debugtap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+
+	// init encoding
+	_ "github.com/go-kratos/kratos/v2/encoding/json"
+)
+
+// Predicate reports whether the request is dumped.
+type Predicate func(ctx context.Context, req interface{}) bool
+
+// Entry is a dumped request and its reply.
+type Entry struct {
+	Kind      string            `json:"kind"`
+	Operation string            `json:"operation"`
+	Header    map[string]string `json:"header,omitempty"`
+	Request   []byte            `json:"-"`
+	Reply     []byte            `json:"-"`
+	Error     string            `json:"error,omitempty"`
+}
+
+// MarshalJSON marshals the entry, the request and the reply are embedded as is if they are JSON.
+func (e *Entry) MarshalJSON() ([]byte, error) {
+	type entry Entry
+	return json.Marshal(struct {
+		*entry
+		Request interface{} `json:"request,omitempty"`
+		Reply   interface{} `json:"reply,omitempty"`
+	}{
+		entry:   (*entry)(e),
+		Request: rawValue(e.Request),
+		Reply:   rawValue(e.Reply),
+	})
+}
+
+// Option is debug tap option.
+type Option func(*options)
+
+// WithWriter with the writer which the entries are written to as JSON lines, default is os.Stderr.
+func WithWriter(w io.Writer) Option {
+	return func(o *options) {
+		o.writer = w
+	}
+}
+
+// WithCallback with the callback which receives the entries instead of the writer, e.g. to send them to a channel.
+func WithCallback(fn func(ctx context.Context, e *Entry)) Option {
+	return func(o *options) {
+		o.callback = fn
+	}
+}
+
+type options struct {
+	mu       sync.Mutex
+	writer   io.Writer
+	callback func(ctx context.Context, e *Entry)
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{writer: os.Stderr}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+func (o *options) dump(ctx context.Context, e *Entry) {
+	if o.callback != nil {
+		o.callback(ctx, e)
+		return
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	_, _ = o.writer.Write(append(data, '\n'))
+}
+
+// Server is a server middleware which dumps the requests matching the predicate and their replies.
+func Server(match Predicate, opts ...Option) middleware.Middleware {
+	o := newOptions(opts)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if !match(ctx, req) {
+				return handler(ctx, req)
+			}
+			e := &Entry{Kind: "server"}
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				e.Operation = tr.Operation()
+				e.Header = headerMap(tr.RequestHeader())
+			}
+			reply, err := handler(ctx, req)
+			o.dump(ctx, complete(e, req, reply, err))
+			return reply, err
+		}
+	}
+}
+
+// Client is a client middleware which dumps the requests matching the predicate and their replies.
+func Client(match Predicate, opts ...Option) middleware.Middleware {
+	o := newOptions(opts)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if !match(ctx, req) {
+				return handler(ctx, req)
+			}
+			e := &Entry{Kind: "client"}
+			if tr, ok := transport.FromClientContext(ctx); ok {
+				e.Operation = tr.Operation()
+				e.Header = headerMap(tr.RequestHeader())
+			}
+			reply, err := handler(ctx, req)
+			o.dump(ctx, complete(e, req, reply, err))
+			return reply, err
+		}
+	}
+}
+
+// Filter is an HTTP filter which dumps the raw requests matching the predicate and their responses,
+// the request body is cloned so the handler still reads it.
+func Filter(match func(*http.Request) bool, opts ...Option) func(http.Handler) http.Handler {
+	o := newOptions(opts)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if !match(req) {
+				next.ServeHTTP(w, req)
+				return
+			}
+			e := &Entry{
+				Kind:      "server",
+				Operation: req.Method + " " + req.URL.RequestURI(),
+				Header:    make(map[string]string, len(req.Header)),
+			}
+			for k, v := range req.Header {
+				e.Header[k] = strings.Join(v, ", ")
+			}
+			if req.Body != nil && req.Body != http.NoBody {
+				body, err := ioutil.ReadAll(req.Body)
+				req.Body.Close()
+				if err != nil {
+					e.Error = err.Error()
+				}
+				e.Request = body
+				req.Body = ioutil.NopCloser(bytes.NewReader(body))
+			}
+			rw := &recorder{ResponseWriter: w, code: http.StatusOK}
+			next.ServeHTTP(rw, req)
+			e.Reply = rw.body.Bytes()
+			if rw.code >= http.StatusBadRequest && e.Error == "" {
+				e.Error = http.StatusText(rw.code)
+			}
+			o.dump(req.Context(), e)
+		})
+	}
+}
+
+type recorder struct {
+	http.ResponseWriter
+	code int
+	body bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(code int) {
+	r.code = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *recorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *recorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func complete(e *Entry, req, reply interface{}, err error) *Entry {
+	e.Request = marshal(req)
+	if err != nil {
+		e.Error = err.Error()
+		return e
+	}
+	e.Reply = marshal(reply)
+	return e
+}
+
+func headerMap(h transport.Header) map[string]string {
+	if h == nil {
+		return nil
+	}
+	m := make(map[string]string)
+	for _, k := range h.Keys() {
+		m[k] = h.Get(k)
+	}
+	return m
+}
+
+func marshal(v interface{}) []byte {
+	if v == nil {
+		return nil
+	}
+	data, err := encoding.GetCodec("json").Marshal(v)
+	if err != nil {
+		return []byte(fmt.Sprintf("%+v", v))
+	}
+	return data
+}
+
+func rawValue(data []byte) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	if json.Valid(data) {
+		return json.RawMessage(data)
+	}
+	return string(data)
+}
@@ -0,0 +1,69 @@
+package debugtap
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer(t *testing.T) {
+	buf := new(bytes.Buffer)
+	match := func(ctx context.Context, req interface{}) bool {
+		return req.(map[string]string)["name"] == "debug"
+	}
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		if req.(map[string]string)["fail"] != "" {
+			return nil, errors.New("boom")
+		}
+		return map[string]string{"reply": "ok"}, nil
+	}
+	h := Server(match, WithWriter(buf))(next)
+
+	_, err := h(context.Background(), map[string]string{"name": "other"})
+	assert.NoError(t, err)
+	assert.Empty(t, buf.String())
+
+	_, err = h(context.Background(), map[string]string{"name": "debug"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"kind":"server","operation":"","request":{"name":"debug"},"reply":{"reply":"ok"}}`+"\n", buf.String())
+
+	var entries []*Entry
+	h = Client(match, WithCallback(func(ctx context.Context, e *Entry) {
+		entries = append(entries, e)
+	}))(next)
+	_, err = h(context.Background(), map[string]string{"name": "debug", "fail": "1"})
+	assert.Error(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "client", entries[0].Kind)
+	assert.Equal(t, "boom", entries[0].Error)
+}
+
+func TestFilter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	h := Filter(func(r *http.Request) bool {
+		return r.Header.Get("X-Debug") != ""
+	}, WithWriter(buf))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/echo?a=1", strings.NewReader(`{"a":1}`))
+	req.Header.Set("X-Debug", "1")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	assert.Equal(t, `{"a":1}`, w.Body.String())
+	assert.Equal(t, `{"kind":"server","operation":"POST /echo?a=1","header":{"X-Debug":"1"},"request":{"a":1},"reply":{"a":1}}`+"\n", buf.String())
+
+	buf.Reset()
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader("raw")))
+	assert.Equal(t, "raw", w.Body.String())
+	assert.Empty(t, buf.String())
+}
@@ -3,16 +3,55 @@ package logging
 import (
 	"context"
 	"fmt"
+	"io"
+	"reflect"
 	"time"
 
+	"github.com/go-kratos/kratos/v2/encoding"
 	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/http"
+
+	"google.golang.org/protobuf/proto"
 )
 
+// Option is logging option.
+type Option func(*options)
+
+// WithSize with the request_size and reply_size fields, which are the encoded sizes of
+// the request and the reply in bytes, the size of a streaming reply is "stream".
+func WithSize(enable bool) Option {
+	return func(o *options) {
+		o.size = enable
+	}
+}
+
+// WithErrorAggregation with the aggregator of the errors, the identical errors beyond the first one of a window
+// are not logged individually, and their counts are logged once the window elapses. The successful requests
+// are always logged. The aggregator notifies the middleware, so it should not be shared by the middlewares.
+func WithErrorAggregation(a *errors.Aggregator) Option {
+	return func(o *options) {
+		o.aggregator = a
+	}
+}
+
+type options struct {
+	size       bool
+	redact     *redactor
+	aggregator *errors.Aggregator
+}
+
 // Server is an server logging middleware.
-func Server(logger log.Logger) middleware.Middleware {
+func Server(logger log.Logger, opts ...Option) middleware.Middleware {
+	options := options{
+		redact: newRedactor(DefaultRedactFields),
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	options.notify(logger, "server")
 	return func(handler middleware.Handler) middleware.Handler {
 		return func(ctx context.Context, req interface{}) (reply interface{}, err error) {
 			var (
@@ -27,28 +66,45 @@ func Server(logger log.Logger) middleware.Middleware {
 				operation = info.Operation()
 			}
 			reply, err = handler(ctx, req)
+			if !options.aggregate(ctx, logger, "server", err) {
+				return
+			}
 			if se := errors.FromError(err); se != nil {
 				code = se.Code
 				reason = se.Reason
 			}
 			level, stack := extractError(err)
-			_ = log.WithContext(ctx, logger).Log(level,
+			keyvals := []interface{}{
 				"kind", "server",
 				"component", kind,
 				"operation", operation,
-				"args", extractArgs(req),
+				"args", options.redact.args(req),
 				"code", code,
 				"reason", reason,
 				"stack", stack,
 				"latency", time.Since(startTime).Seconds(),
-			)
+			}
+			if options.size {
+				keyvals = append(keyvals,
+					"request_size", requestSize(ctx, req),
+					"reply_size", extractSize(reply),
+				)
+			}
+			_ = log.WithContext(ctx, logger).Log(level, keyvals...)
 			return
 		}
 	}
 }
 
 // Client is an client logging middleware.
-func Client(logger log.Logger) middleware.Middleware {
+func Client(logger log.Logger, opts ...Option) middleware.Middleware {
+	options := options{
+		redact: newRedactor(DefaultRedactFields),
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	options.notify(logger, "client")
 	return func(handler middleware.Handler) middleware.Handler {
 		return func(ctx context.Context, req interface{}) (reply interface{}, err error) {
 			var (
@@ -63,26 +119,68 @@ func Client(logger log.Logger) middleware.Middleware {
 				operation = info.Operation()
 			}
 			reply, err = handler(ctx, req)
+			if !options.aggregate(ctx, logger, "client", err) {
+				return
+			}
 			if se := errors.FromError(err); se != nil {
 				code = se.Code
 				reason = se.Reason
 			}
 			level, stack := extractError(err)
-			_ = log.WithContext(ctx, logger).Log(level,
+			keyvals := []interface{}{
 				"kind", "client",
 				"component", kind,
 				"operation", operation,
-				"args", extractArgs(req),
+				"args", options.redact.args(req),
 				"code", code,
 				"reason", reason,
 				"stack", stack,
 				"latency", time.Since(startTime).Seconds(),
-			)
+			}
+			if options.size {
+				keyvals = append(keyvals,
+					"request_size", extractSize(req),
+					"reply_size", extractSize(reply),
+				)
+			}
+			_ = log.WithContext(ctx, logger).Log(level, keyvals...)
 			return
 		}
 	}
 }
 
+// aggregate logs the summaries of the aggregated errors, and reports whether the request should be logged.
+func (o *options) aggregate(ctx context.Context, logger log.Logger, kind string, err error) bool {
+	if o.aggregator == nil {
+		return true
+	}
+	individual, summaries := o.aggregator.Add(err)
+	logSummaries(ctx, logger, kind, summaries)
+	return err == nil || individual
+}
+
+// notify logs the summaries of the aggregated errors once their window elapses.
+func (o *options) notify(logger log.Logger, kind string) {
+	if o.aggregator == nil {
+		return
+	}
+	o.aggregator.Notify(func(summaries []errors.Summary) {
+		logSummaries(context.Background(), logger, kind, summaries)
+	})
+}
+
+func logSummaries(ctx context.Context, logger log.Logger, kind string, summaries []errors.Summary) {
+	for _, s := range summaries {
+		_ = log.WithContext(ctx, logger).Log(log.LevelError,
+			"kind", kind,
+			"msg", "aggregated errors",
+			"code", s.Code,
+			"reason", s.Reason,
+			"count", s.Count,
+		)
+	}
+}
+
 // extractArgs returns the string of the req
 func extractArgs(req interface{}) string {
 	if stringer, ok := req.(fmt.Stringer); ok {
@@ -91,6 +189,38 @@ func extractArgs(req interface{}) string {
 	return fmt.Sprintf("%+v", req)
 }
 
+// requestSize returns the content length of the HTTP request if it is known, otherwise the size of the req.
+func requestSize(ctx context.Context, req interface{}) interface{} {
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		if ht, ok := tr.(*http.Transport); ok && ht.Request() != nil && ht.Request().ContentLength >= 0 {
+			return ht.Request().ContentLength
+		}
+	}
+	return extractSize(req)
+}
+
+// extractSize returns the encoded size of the message, the proto messages are
+// sized by the wire format and the others by the JSON encoding.
+func extractSize(v interface{}) interface{} {
+	if v == nil {
+		return 0
+	}
+	if m, ok := v.(proto.Message); ok {
+		return proto.Size(m)
+	}
+	if _, ok := v.(io.Reader); ok {
+		return "stream"
+	}
+	if t := reflect.TypeOf(v); t.Kind() == reflect.Chan {
+		return "stream"
+	}
+	data, err := encoding.GetCodec("json").Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
+
 // extractError returns the string of the error
 func extractError(err error) (log.Level, string) {
 	if err != nil {
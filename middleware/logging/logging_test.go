@@ -4,8 +4,12 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	kerrors "github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/transport"
@@ -44,7 +48,7 @@ func TestHTTP(t *testing.T) {
 
 	tests := []struct {
 		name string
-		kind func(logger log.Logger) middleware.Middleware
+		kind func(logger log.Logger, opts ...Option) middleware.Middleware
 		err  error
 		ctx  context.Context
 	}{
@@ -92,3 +96,86 @@ func TestHTTP(t *testing.T) {
 		})
 	}
 }
+
+func TestWithSize(t *testing.T) {
+	var bf = bytes.NewBuffer(nil)
+	var logger = log.NewStdLogger(bf)
+
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "reply", nil
+	}
+	ctx := transport.NewServerContext(context.Background(), &Transport{kind: transport.KindHTTP, endpoint: "endpoint", operation: "/package.service/method"})
+	if _, err := Server(logger, WithSize(true))(next)(ctx, "req.args"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(bf.String(), "request_size=10 reply_size=7") {
+		t.Fatalf("unexpected log %s", bf.String())
+	}
+
+	bf.Reset()
+	stream := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return make(<-chan int), nil
+	}
+	if _, err := Server(logger, WithSize(true))(stream)(ctx, "req.args"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(bf.String(), "reply_size=stream") {
+		t.Fatalf("unexpected log %s", bf.String())
+	}
+
+	bf.Reset()
+	if _, err := Client(logger)(next)(ctx, "req.args"); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(bf.String(), "request_size") {
+		t.Fatalf("unexpected log %s", bf.String())
+	}
+}
+
+// syncBuffer is a buffer which is written by the aggregator notifications concurrently.
+type syncBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.String()
+}
+
+func TestWithErrorAggregation(t *testing.T) {
+	var bf = &syncBuffer{}
+	var logger = log.NewStdLogger(bf)
+
+	var err error
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "reply", err
+	}
+	ctx := transport.NewServerContext(context.Background(), &Transport{kind: transport.KindHTTP, endpoint: "endpoint", operation: "/package.service/method"})
+	h := Server(logger, WithErrorAggregation(kerrors.NewAggregator(50*time.Millisecond, 10)))(next)
+	err = kerrors.ServiceUnavailable("DB", "database unavailable")
+	for i := 0; i < 5; i++ {
+		_, _ = h(ctx, "req.args")
+	}
+	err = nil
+	_, _ = h(ctx, "req.args")
+	if n := strings.Count(bf.String(), "\n"); n != 2 {
+		t.Fatalf("unexpected log %s", bf.String())
+	}
+
+	// the summary is logged once the window elapses without any request.
+	deadline := time.Now().Add(time.Second)
+	for !strings.Contains(bf.String(), `kind=server msg=aggregated errors code=503 reason=DB count=4`) {
+		if time.Now().After(deadline) {
+			t.Fatalf("unexpected log %s", bf.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,292 @@
+package logging
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// redactMask is the value of the redacted fields.
+const redactMask = "***"
+
+// DefaultRedactFields is the fields redacted by default.
+var DefaultRedactFields = []string{
+	"password",
+	"passwd",
+	"secret",
+	"token",
+	"access_token",
+	"refresh_token",
+	"authorization",
+}
+
+// WithRedact with the fields which are masked with "***" before the args are logged, default is DefaultRedactFields.
+// A field is either a name, e.g. "password", which matches the fields of the name at any depth,
+// or a path, e.g. "user.password". The proto messages are matched by the field names and the
+// JSON names, the other args are matched by the JSON keys. No field is redacted if fields is empty.
+func WithRedact(fields ...string) Option {
+	return func(o *options) {
+		o.redact = newRedactor(fields)
+	}
+}
+
+var (
+	jsonMarshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+type redactor struct {
+	fields map[string]struct{}
+	// paths reports whether a field is a path.
+	paths bool
+	// types caches whether the args of a type may have a redacted field, which saves the JSON
+	// round trip of the args which never have one.
+	types sync.Map
+}
+
+func newRedactor(fields []string) *redactor {
+	r := &redactor{fields: make(map[string]struct{}, len(fields))}
+	for _, f := range fields {
+		r.fields[f] = struct{}{}
+		r.paths = r.paths || strings.Contains(f, ".")
+	}
+	return r
+}
+
+func (r *redactor) match(path string, names ...string) bool {
+	if _, ok := r.fields[path]; ok {
+		return true
+	}
+	for _, name := range names {
+		if _, ok := r.fields[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// args returns the string of the req with the fields redacted, which is the same as extractArgs
+// if no field is redacted.
+func (r *redactor) args(req interface{}) string {
+	if len(r.fields) == 0 {
+		return extractArgs(req)
+	}
+	if m, ok := req.(proto.Message); ok {
+		// the message is only cloned if it has a redacted field.
+		if !r.message(m.ProtoReflect(), "", false) {
+			return extractArgs(req)
+		}
+		m = proto.Clone(m)
+		r.message(m.ProtoReflect(), "", true)
+		return extractArgs(m)
+	}
+	if !isObject(req) || !r.mayRedact(reflect.TypeOf(req)) {
+		return extractArgs(req)
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return extractArgs(req)
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return extractArgs(req)
+	}
+	v, redacted := r.value(v, "")
+	if !redacted {
+		return extractArgs(req)
+	}
+	if data, err = json.Marshal(v); err != nil {
+		return extractArgs(req)
+	}
+	return string(data)
+}
+
+// message reports whether the message has a redacted field, which is masked if mask is true.
+func (r *redactor) message(m protoreflect.Message, prefix string, mask bool) bool {
+	var found bool
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		path := joinPath(prefix, string(fd.Name()))
+		if r.match(path, string(fd.Name()), fd.JSONName()) {
+			found = true
+			if mask {
+				maskField(m, fd, v)
+			}
+			return mask
+		}
+		switch {
+		case fd.IsList() && fd.Message() != nil:
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				if r.message(list.Get(i).Message(), path, mask) {
+					found = true
+				}
+			}
+		case fd.IsMap() && fd.MapValue().Message() != nil:
+			v.Map().Range(func(_ protoreflect.MapKey, v protoreflect.Value) bool {
+				if r.message(v.Message(), path, mask) {
+					found = true
+				}
+				return true
+			})
+		case !fd.IsList() && !fd.IsMap() && fd.Message() != nil:
+			if r.message(v.Message(), path, mask) {
+				found = true
+			}
+		}
+		return mask || !found
+	})
+	return found
+}
+
+// maskField masks the string and bytes fields, the other fields are cleared.
+func maskField(m protoreflect.Message, fd protoreflect.FieldDescriptor, v protoreflect.Value) {
+	mask, ok := maskValue(fd.Kind())
+	switch {
+	case !ok || fd.IsMap():
+		m.Clear(fd)
+	case fd.IsList():
+		list := v.List()
+		for i := 0; i < list.Len(); i++ {
+			list.Set(i, mask)
+		}
+	default:
+		m.Set(fd, mask)
+	}
+}
+
+func maskValue(kind protoreflect.Kind) (protoreflect.Value, bool) {
+	switch kind {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(redactMask), true
+	case protoreflect.BytesKind:
+		return protoreflect.ValueOfBytes([]byte(redactMask)), true
+	}
+	return protoreflect.Value{}, false
+}
+
+// value returns the v with the fields redacted, and whether a field is redacted.
+func (r *redactor) value(v interface{}, prefix string) (interface{}, bool) {
+	var redacted bool
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			path := joinPath(prefix, k)
+			if r.match(path, k) {
+				v[k] = redactMask
+				redacted = true
+				continue
+			}
+			var ok bool
+			if v[k], ok = r.value(e, path); ok {
+				redacted = true
+			}
+		}
+	case []interface{}:
+		for i, e := range v {
+			var ok bool
+			if v[i], ok = r.value(e, prefix); ok {
+				redacted = true
+			}
+		}
+	}
+	return v, redacted
+}
+
+// mayRedact reports whether the args of the type may have a redacted field, the result is cached by the type.
+func (r *redactor) mayRedact(t reflect.Type) bool {
+	if v, ok := r.types.Load(t); ok {
+		return v.(bool)
+	}
+	may := r.typ(t, "", map[reflect.Type]bool{})
+	r.types.Store(t, may)
+	return may
+}
+
+// typ reports whether the JSON of the type may have a redacted field. The maps, the interfaces
+// and the json.Marshaler types are only known by their values, so they may always have one.
+func (r *redactor) typ(t reflect.Type, prefix string, visiting map[reflect.Type]bool) bool {
+	if t.Implements(jsonMarshaler) || reflect.PtrTo(t).Implements(jsonMarshaler) {
+		// the json.Marshaler types which are also the encoding.TextMarshaler ones, e.g. time.Time,
+		// are taken as the strings.
+		return !t.Implements(textMarshaler) && !reflect.PtrTo(t).Implements(textMarshaler)
+	}
+	if t.Implements(textMarshaler) || reflect.PtrTo(t).Implements(textMarshaler) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return r.typ(t.Elem(), prefix, visiting)
+	case reflect.Map, reflect.Interface:
+		return true
+	case reflect.Struct:
+		if visiting[t] {
+			// the names of the recursive type are already matched, only the paths go deeper.
+			return r.paths
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, named, ok := jsonName(f)
+			if !ok {
+				continue
+			}
+			if f.Anonymous && !named && indirect(f.Type).Kind() == reflect.Struct {
+				// the fields of the embedded struct are promoted.
+				if r.typ(f.Type, prefix, visiting) {
+					return true
+				}
+				continue
+			}
+			path := joinPath(prefix, name)
+			if r.match(path, name) || r.typ(f.Type, path, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jsonName returns the JSON key of the struct field, whether it is named by the tag, and false if
+// the field is not encoded.
+func jsonName(f reflect.StructField) (string, bool, bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false, false
+	}
+	if f.PkgPath != "" && !(f.Anonymous && indirect(f.Type).Kind() == reflect.Struct) {
+		return "", false, false
+	}
+	if name := strings.Split(tag, ",")[0]; name != "" {
+		return name, true, true
+	}
+	return f.Name, false, true
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// isObject reports whether the v is encoded as a JSON object.
+func isObject(v interface{}) bool {
+	if v == nil {
+		return false
+	}
+	t := indirect(reflect.TypeOf(v))
+	return t.Kind() == reflect.Struct || t.Kind() == reflect.Map
+}
+
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
@@ -0,0 +1,129 @@
+package logging
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestRedactProto(t *testing.T) {
+	req := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("user.proto"),
+		Package: proto.String("secret.package"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("Password")},
+		},
+		Dependency: []string{"a.proto", "b.proto"},
+	}
+	args := newRedactor([]string{"package", "message_type.name", "dependency"}).args(req)
+	if strings.Contains(args, "secret.package") || strings.Contains(args, "Password") || strings.Contains(args, "a.proto") {
+		t.Fatalf("unexpected args %s", args)
+	}
+	if !strings.Contains(args, "user.proto") || strings.Count(args, redactMask) != 4 {
+		t.Fatalf("unexpected args %s", args)
+	}
+	if req.GetPackage() != "secret.package" {
+		t.Fatalf("the req is modified: %v", req)
+	}
+}
+
+func TestRedactJSON(t *testing.T) {
+	type user struct {
+		Name     string `json:"name"`
+		Password string `json:"password"`
+	}
+	req := map[string]interface{}{
+		"users": []user{{Name: "kratos", Password: "123456"}},
+		"auth":  map[string]string{"token": "abc"},
+		"email": "kratos@example.com",
+	}
+	args := newRedactor(append(DefaultRedactFields, "email")).args(req)
+	if args != `{"auth":{"token":"***"},"email":"***","users":[{"name":"kratos","password":"***"}]}` {
+		t.Fatalf("unexpected args %s", args)
+	}
+	if args := newRedactor(nil).args("password"); args != "password" {
+		t.Fatalf("unexpected args %s", args)
+	}
+}
+
+func TestRedactUnmatched(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+	r := newRedactor(DefaultRedactFields)
+	req := &user{Name: "kratos"}
+	if args := r.args(req); args != extractArgs(req) {
+		t.Fatalf("unexpected args %s", args)
+	}
+	msg := &descriptorpb.FileDescriptorProto{Name: proto.String("user.proto")}
+	if args := r.args(msg); args != extractArgs(msg) {
+		t.Fatalf("unexpected args %s", args)
+	}
+}
+
+func TestRedactTypes(t *testing.T) {
+	type base struct {
+		Token string `json:"token"`
+	}
+	type node struct {
+		Name     string `json:"name"`
+		Children []*node
+	}
+	type user struct {
+		Name     string    `json:"name"`
+		Password string    `json:"-"`
+		Created  time.Time `json:"created"`
+		secret   string
+	}
+	type login struct {
+		User     user   `json:"user"`
+		Password string `json:"pass"`
+	}
+	type embedded struct {
+		base
+		Name string
+	}
+	type attrs struct {
+		Attrs map[string]string
+	}
+	r := newRedactor(DefaultRedactFields)
+	tests := []struct {
+		typ  interface{}
+		want bool
+	}{
+		{user{}, false},
+		{&user{}, false},
+		{[]*user{}, false},
+		{node{}, false},
+		{login{}, false},
+		{embedded{}, true},
+		{attrs{}, true},
+		{map[string]string{}, true},
+	}
+	for _, test := range tests {
+		if may := r.mayRedact(reflect.TypeOf(test.typ)); may != test.want {
+			t.Errorf("%T: want %v but got %v", test.typ, test.want, may)
+		}
+	}
+	// the path matches deeper in the recursive type.
+	if !newRedactor([]string{"Children.Children.name"}).mayRedact(reflect.TypeOf(node{})) {
+		t.Errorf("want the recursive path redacted")
+	}
+	if newRedactor([]string{"user.pass"}).mayRedact(reflect.TypeOf(login{})) {
+		t.Errorf("want the path unmatched")
+	}
+	if !newRedactor([]string{"user.name"}).mayRedact(reflect.TypeOf(login{})) {
+		t.Errorf("want the path redacted")
+	}
+	// the cached type is still redacted by its values.
+	req := &embedded{base: base{Token: "abc"}, Name: "kratos"}
+	for i := 0; i < 2; i++ {
+		if args := r.args(req); args != `{"Name":"kratos","token":"***"}` {
+			t.Fatalf("unexpected args %s", args)
+		}
+	}
+}
@@ -15,6 +15,9 @@ type Option func(*options)
 type options struct {
 	prefix []string
 	md     metadata.Metadata
+	keys   map[string]struct{}
+	rename map[string]string
+	drop   map[string]struct{}
 }
 
 func (o *options) hasPrefix(key string) bool {
@@ -27,6 +30,30 @@ func (o *options) hasPrefix(key string) bool {
 	return false
 }
 
+// propagated reports whether the key is stored in the server metadata by the server, and propagated by the client.
+func (o *options) propagated(key string) bool {
+	k := strings.ToLower(key)
+	if _, ok := o.keys[k]; ok {
+		return true
+	}
+	if _, ok := o.rename[k]; ok {
+		return true
+	}
+	return o.hasPrefix(k)
+}
+
+// outbound returns the outbound key of the key, it returns false if the key is dropped.
+func (o *options) outbound(key string) (string, bool) {
+	k := strings.ToLower(key)
+	if _, ok := o.drop[k]; ok {
+		return "", false
+	}
+	if to, ok := o.rename[k]; ok {
+		return to, true
+	}
+	return key, true
+}
+
 // WithConstants with constant metadata key value.
 func WithConstants(md metadata.Metadata) Option {
 	return func(o *options) {
@@ -41,6 +68,39 @@ func WithPropagatedPrefix(prefix ...string) Option {
 	}
 }
 
+// WithPropagatedKeys with the keys of the server metadata which are propagated by the client besides the prefixes,
+// the Server stores the keys of the request headers in the server metadata with the same option.
+func WithPropagatedKeys(keys ...string) Option {
+	return func(o *options) {
+		o.keys = make(map[string]struct{}, len(keys))
+		for _, k := range keys {
+			o.keys[strings.ToLower(k)] = struct{}{}
+		}
+	}
+}
+
+// WithRename with the outbound keys keyed by the inbound keys, e.g. {"x-md-global-uid": "x-user-id"},
+// the client propagates the inbound keys of the server metadata as the outbound keys, and the Server stores
+// the inbound keys of the request headers in the server metadata with the same option.
+func WithRename(rename map[string]string) Option {
+	return func(o *options) {
+		o.rename = make(map[string]string, len(rename))
+		for from, to := range rename {
+			o.rename[strings.ToLower(from)] = to
+		}
+	}
+}
+
+// WithDropped with the keys which are never propagated by the client, the constants are always sent.
+func WithDropped(keys ...string) Option {
+	return func(o *options) {
+		o.drop = make(map[string]struct{}, len(keys))
+		for _, k := range keys {
+			o.drop[strings.ToLower(k)] = struct{}{}
+		}
+	}
+}
+
 // Server is middleware server-side metadata.
 func Server(opts ...Option) middleware.Middleware {
 	options := &options{
@@ -55,7 +115,7 @@ func Server(opts ...Option) middleware.Middleware {
 				md := options.md.Clone()
 				header := tr.RequestHeader()
 				for _, k := range header.Keys() {
-					if options.hasPrefix(k) {
+					if options.propagated(k) {
 						md.Set(k, header.Get(k))
 					}
 				}
@@ -84,13 +144,18 @@ func Client(opts ...Option) middleware.Middleware {
 				}
 				if md, ok := metadata.FromClientContext(ctx); ok {
 					for k, v := range md {
-						header.Set(k, v)
+						if k, ok := options.outbound(k); ok {
+							header.Set(k, v)
+						}
 					}
 				}
 				// x-md-global-
 				if md, ok := metadata.FromServerContext(ctx); ok {
 					for k, v := range md {
-						if options.hasPrefix(k) {
+						if !options.propagated(k) {
+							continue
+						}
+						if k, ok := options.outbound(k); ok {
 							header.Set(k, v)
 						}
 					}
@@ -126,3 +126,80 @@ func TestClient(t *testing.T) {
 		t.Fatalf("want foo got %v", reply)
 	}
 }
+
+func TestClientRules(t *testing.T) {
+	serverMD := metadata.New(map[string]string{
+		"x-md-global-uid":   "1",
+		"x-md-global-token": "secret",
+		"x-md-local-trace":  "abc",
+		"x-request-id":      "req",
+		"x-other":           "other",
+	})
+	ctx := metadata.NewServerContext(context.Background(), serverMD)
+	ctx = metadata.NewClientContext(ctx, metadata.New(map[string]string{"x-md-global-token": "client"}))
+	header := headerCarrier{}
+	ctx = transport.NewClientContext(ctx, &testTransport{header})
+
+	hs := func(ctx context.Context, in interface{}) (interface{}, error) { return in, nil }
+	_, err := Client(
+		WithConstants(metadata.New(map[string]string{"x-source-service": "kratos"})),
+		WithPropagatedKeys("X-Request-Id"),
+		WithRename(map[string]string{"x-md-global-uid": "x-user-id", "x-md-local-trace": "x-trace"}),
+		WithDropped("x-md-global-token"),
+	)(hs)(ctx, "bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"x-source-service": "kratos",
+		"x-request-id":     "req",
+		"x-user-id":        "1",
+		"x-trace":          "abc",
+	}
+	for k, v := range want {
+		if header.Get(k) != v {
+			t.Errorf("%s: want %s got %s", k, v, header.Get(k))
+		}
+	}
+	for _, k := range []string{"x-md-global-uid", "x-md-global-token", "x-md-local-trace", "x-other"} {
+		if header.Get(k) != "" {
+			t.Errorf("%s: want empty got %s", k, header.Get(k))
+		}
+	}
+}
+
+func TestServerClient(t *testing.T) {
+	opts := []Option{
+		WithPropagatedKeys("x-request-id"),
+		WithRename(map[string]string{"x-legacy-uid": "x-user-id"}),
+	}
+	inbound := headerCarrier{}
+	inbound.Set("x-request-id", "req")
+	inbound.Set("x-legacy-uid", "1")
+	inbound.Set("x-md-global-name", "kratos")
+	inbound.Set("x-other", "other")
+	outbound := headerCarrier{}
+
+	client := Client(opts...)(func(ctx context.Context, in interface{}) (interface{}, error) { return in, nil })
+	server := Server(opts...)(func(ctx context.Context, in interface{}) (interface{}, error) {
+		return client(transport.NewClientContext(ctx, &testTransport{outbound}), in)
+	})
+	if _, err := server(transport.NewServerContext(context.Background(), &testTransport{inbound}), "foo"); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"x-request-id":     "req",
+		"x-user-id":        "1",
+		"x-md-global-name": "kratos",
+	}
+	for k, v := range want {
+		if outbound.Get(k) != v {
+			t.Errorf("%s: want %s got %s", k, v, outbound.Get(k))
+		}
+	}
+	for _, k := range []string{"x-legacy-uid", "x-other"} {
+		if outbound.Get(k) != "" {
+			t.Errorf("%s: want empty got %s", k, outbound.Get(k))
+		}
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/go-kratos/kratos/v2/metrics"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/transport"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Option is metrics option.
@@ -21,7 +23,8 @@ func WithRequests(c metrics.Counter) Option {
 	}
 }
 
-// WithSeconds with seconds histogram.
+// WithSeconds with seconds histogram, the trace_id and span_id of a sampled span in the context are attached
+// as the exemplar if the observer implements metrics.ExemplarObserver.
 func WithSeconds(c metrics.Observer) Option {
 	return func(o *options) {
 		o.seconds = c
@@ -63,7 +66,7 @@ func Server(opts ...Option) middleware.Middleware {
 				options.requests.With(kind, operation, strconv.Itoa(code), reason).Inc()
 			}
 			if options.seconds != nil {
-				options.seconds.With(kind, operation).Observe(time.Since(startTime).Seconds())
+				observe(ctx, options.seconds.With(kind, operation), time.Since(startTime).Seconds())
 			}
 			return reply, err
 		}
@@ -98,9 +101,24 @@ func Client(opts ...Option) middleware.Middleware {
 				options.requests.With(kind, operation, strconv.Itoa(code), reason).Inc()
 			}
 			if options.seconds != nil {
-				options.seconds.With(kind, operation).Observe(time.Since(startTime).Seconds())
+				observe(ctx, options.seconds.With(kind, operation), time.Since(startTime).Seconds())
 			}
 			return reply, err
 		}
 	}
 }
+
+// observe observes the value with the exemplar of the span in the context,
+// it falls back to Observe if there is no sampled span or the observer doesn't support the exemplars.
+func observe(ctx context.Context, o metrics.Observer, v float64) {
+	if eo, ok := o.(metrics.ExemplarObserver); ok {
+		if span := trace.SpanContextFromContext(ctx); span.IsValid() && span.IsSampled() {
+			eo.ObserveWithExemplar(v, map[string]string{
+				"trace_id": span.TraceID().String(),
+				"span_id":  span.SpanID().String(),
+			})
+			return
+		}
+	}
+	o.Observe(v)
+}
@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/metrics"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type observer struct {
+	values    []float64
+	exemplars []map[string]string
+}
+
+func (o *observer) With(lvs ...string) metrics.Observer { return o }
+
+func (o *observer) Observe(v float64) {
+	o.values = append(o.values, v)
+	o.exemplars = append(o.exemplars, nil)
+}
+
+type exemplarObserver struct {
+	observer
+}
+
+func (o *exemplarObserver) With(lvs ...string) metrics.Observer { return o }
+
+func (o *exemplarObserver) ObserveWithExemplar(v float64, exemplar map[string]string) {
+	o.values = append(o.values, v)
+	o.exemplars = append(o.exemplars, exemplar)
+}
+
+func spanContext(flags trace.TraceFlags) context.Context {
+	traceID, _ := trace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	spanID, _ := trace.SpanIDFromHex("0102030405060708")
+	return trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: flags,
+	}))
+}
+
+func TestServerExemplar(t *testing.T) {
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "reply", nil }
+	o := &exemplarObserver{}
+	h := Server(WithSeconds(o))(handler)
+
+	_, err := h(spanContext(trace.FlagsSampled), nil)
+	assert.NoError(t, err)
+	_, err = h(spanContext(0), nil)
+	assert.NoError(t, err)
+	_, err = h(context.Background(), nil)
+	assert.NoError(t, err)
+
+	assert.Len(t, o.values, 3)
+	assert.Equal(t, []map[string]string{
+		{"trace_id": "0102030405060708090a0b0c0d0e0f10", "span_id": "0102030405060708"},
+		nil,
+		nil,
+	}, o.exemplars)
+}
+
+func TestClientExemplarUnsupported(t *testing.T) {
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "reply", nil }
+	o := &observer{}
+	_, err := Client(WithSeconds(o))(handler)(spanContext(trace.FlagsSampled), nil)
+	assert.NoError(t, err)
+	assert.Len(t, o.values, 1)
+	assert.Equal(t, []map[string]string{nil}, o.exemplars)
+}
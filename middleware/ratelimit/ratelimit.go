@@ -0,0 +1,246 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/http"
+
+	"google.golang.org/grpc/peer"
+)
+
+// Reason is the error reason when the rate limit is exceeded.
+const Reason = "RATE_LIMITED"
+
+// ErrLimitExceed is returned when the bucket of the key is empty.
+var ErrLimitExceed = errors.New(429, Reason, "service unavailable due to rate limit exceeded")
+
+// KeyFunc returns the key of the request, the requests of a key share a bucket.
+type KeyFunc func(ctx context.Context, req interface{}) string
+
+// Option is rate limit option.
+type Option func(*options)
+
+// Stat is the state of the limiter when a request is allowed or rejected,
+// the Key and Allowed of the request are empty in the Stats of the limiter.
+type Stat struct {
+	// Key is the key of the request.
+	Key string
+	// Allowed reports whether the request is allowed.
+	Allowed bool
+	// InFlight is the number of the allowed requests being handled.
+	InFlight int64
+	// Rejected is the total number of the rejected requests.
+	Rejected int64
+}
+
+type options struct {
+	rate    float64
+	burst   int
+	key     KeyFunc
+	idle    time.Duration
+	stat    func(Stat)
+	limiter *Limiter
+}
+
+// WithRate with the requests per second of each key, default is 100.
+func WithRate(r float64) Option {
+	return func(o *options) {
+		o.rate = r
+	}
+}
+
+// WithBurst with the max requests of each key at once, default is the rate.
+func WithBurst(n int) Option {
+	return func(o *options) {
+		o.burst = n
+	}
+}
+
+// WithKey with the key function, default is a single bucket for all requests.
+func WithKey(f KeyFunc) Option {
+	return func(o *options) {
+		o.key = f
+	}
+}
+
+// WithIdle with the duration after which the bucket of an idle key is dropped, default is 10m.
+func WithIdle(d time.Duration) Option {
+	return func(o *options) {
+		o.idle = d
+	}
+}
+
+// WithStat with the callback which is invoked on each decision, e.g. to alarm when the limiter engages.
+func WithStat(f func(Stat)) Option {
+	return func(o *options) {
+		o.stat = f
+	}
+}
+
+// WithLimiter with the limiter of the requests, e.g. the one of NewLimiter to read its Stats,
+// the rate, burst and idle options are the ones the limiter is created with.
+func WithLimiter(l *Limiter) Option {
+	return func(o *options) {
+		o.limiter = l
+	}
+}
+
+// KeyByHeader returns the key function which keys the requests by the request header, e.g. "x-api-key".
+func KeyByHeader(key string) KeyFunc {
+	return func(ctx context.Context, req interface{}) string {
+		if tr, ok := transport.FromServerContext(ctx); ok {
+			return tr.RequestHeader().Get(key)
+		}
+		return ""
+	}
+}
+
+// KeyByRemoteIP returns the key function which keys the requests by the IP of the caller.
+func KeyByRemoteIP() KeyFunc {
+	return func(ctx context.Context, req interface{}) string {
+		var addr string
+		if tr, ok := transport.FromServerContext(ctx); ok {
+			if ht, ok := tr.(*http.Transport); ok && ht.Request() != nil {
+				addr = ht.Request().RemoteAddr
+			}
+		}
+		if p, ok := peer.FromContext(ctx); ok && addr == "" {
+			addr = p.Addr.String()
+		}
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			return host
+		}
+		return addr
+	}
+}
+
+// Server is a server middleware which limits the requests of each key by a token bucket,
+// the rejected requests fail with a RATE_LIMITED error and a Retry-After hint in seconds.
+func Server(opts ...Option) middleware.Middleware {
+	o := newOptions(opts)
+	l := o.limiter
+	if l == nil {
+		l = newLimiter(o)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			var key string
+			if o.key != nil {
+				key = o.key(ctx, req)
+			}
+			wait, ok := l.allow(key)
+			if ok {
+				atomic.AddInt64(&l.inflight, 1)
+				defer atomic.AddInt64(&l.inflight, -1)
+			} else {
+				atomic.AddInt64(&l.rejected, 1)
+			}
+			if o.stat != nil {
+				s := l.Stats()
+				s.Key, s.Allowed = key, ok
+				o.stat(s)
+			}
+			if !ok {
+				retryAfter := strconv.Itoa(int(math.Ceil(wait.Seconds())))
+				if tr, ok := transport.FromServerContext(ctx); ok {
+					tr.ReplyHeader().Set("Retry-After", retryAfter)
+				}
+				return nil, ErrLimitExceed.WithMetadata(map[string]string{"retry_after": retryAfter})
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		rate: 100,
+		idle: 10 * time.Minute,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.burst <= 0 {
+		o.burst = int(math.Max(1, o.rate))
+	}
+	return o
+}
+
+// Limiter limits the requests of each key by a token bucket, and counts the requests it handles.
+type Limiter struct {
+	// inflight and rejected are first for the 64-bit alignment of the atomic operations.
+	inflight int64
+	rejected int64
+
+	lock    sync.Mutex
+	opts    options
+	buckets map[string]*bucket
+	sweepAt time.Time
+	now     func() time.Time
+}
+
+// NewLimiter returns a limiter with the rate, burst and idle options, which is shared by WithLimiter.
+func NewLimiter(opts ...Option) *Limiter {
+	return newLimiter(newOptions(opts))
+}
+
+func newLimiter(o options) *Limiter {
+	return &Limiter{
+		opts:    o,
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Stats returns the current in-flight and rejected requests of the limiter.
+func (l *Limiter) Stats() Stat {
+	return Stat{InFlight: atomic.LoadInt64(&l.inflight), Rejected: atomic.LoadInt64(&l.rejected)}
+}
+
+// allow takes a token of the key, it returns the wait until the next token if the bucket is empty.
+func (l *Limiter) allow(key string) (time.Duration, bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	now := l.now()
+	if now.After(l.sweepAt) {
+		l.sweep(now)
+		l.sweepAt = now.Add(l.opts.idle)
+	}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.opts.burst), last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(l.opts.burst), b.tokens+now.Sub(b.last).Seconds()*l.opts.rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+	if l.opts.rate <= 0 {
+		return l.opts.idle, false
+	}
+	return time.Duration((1 - b.tokens) / l.opts.rate * float64(time.Second)), false
+}
+
+func (l *Limiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if now.Sub(b.last) > l.opts.idle {
+			delete(l.buckets, key)
+		}
+	}
+}
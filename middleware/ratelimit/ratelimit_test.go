@@ -0,0 +1,91 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer(t *testing.T) {
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "reply", nil
+	}
+	h := Server(WithRate(1), WithBurst(2), WithKey(func(ctx context.Context, req interface{}) string {
+		return req.(string)
+	}))(next)
+
+	for i := 0; i < 2; i++ {
+		_, err := h(context.Background(), "a")
+		assert.NoError(t, err)
+	}
+	_, err := h(context.Background(), "a")
+	se := errors.FromError(err)
+	assert.Equal(t, int32(429), se.Code)
+	assert.Equal(t, Reason, se.Reason)
+	assert.Equal(t, "1", se.Metadata["retry_after"])
+
+	// the other key has its own bucket.
+	_, err = h(context.Background(), "b")
+	assert.NoError(t, err)
+}
+
+func TestLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := newLimiter(options{rate: 10, burst: 1, idle: time.Minute})
+	l.now = func() time.Time { return now }
+
+	_, ok := l.allow("a")
+	assert.True(t, ok)
+	wait, ok := l.allow("a")
+	assert.False(t, ok)
+	assert.Equal(t, 100*time.Millisecond, wait)
+
+	now = now.Add(100 * time.Millisecond)
+	_, ok = l.allow("a")
+	assert.True(t, ok)
+
+	// the idle buckets expire.
+	now = now.Add(2 * time.Minute)
+	l.allow("b")
+	assert.Len(t, l.buckets, 1)
+}
+
+func TestWithStat(t *testing.T) {
+	var stats []Stat
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "reply", nil
+	}
+	h := Server(WithRate(1), WithBurst(1), WithStat(func(s Stat) {
+		stats = append(stats, s)
+	}))(next)
+	_, _ = h(context.Background(), "a")
+	_, _ = h(context.Background(), "a")
+	assert.Equal(t, []Stat{
+		{Allowed: true, InFlight: 1},
+		{Allowed: false, InFlight: 0, Rejected: 1},
+	}, stats)
+}
+
+func TestLimiterStats(t *testing.T) {
+	l := NewLimiter(WithRate(1), WithBurst(1))
+	var inflight Stat
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		inflight = l.Stats()
+		return "reply", nil
+	}
+	h := Server(WithLimiter(l))(next)
+	_, err := h(context.Background(), "a")
+	assert.NoError(t, err)
+	assert.Equal(t, Stat{InFlight: 1}, inflight)
+	_, err = h(context.Background(), "a")
+	assert.Error(t, err)
+	assert.Equal(t, Stat{Rejected: 1}, l.Stats())
+
+	// the middlewares of the limiter share its bucket and counters.
+	_, err = Server(WithLimiter(l))(next)(context.Background(), "a")
+	assert.Error(t, err)
+	assert.Equal(t, Stat{Rejected: 2}, l.Stats())
+}
@@ -8,11 +8,30 @@ import (
 	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
 )
 
 // HandlerFunc is recovery handler func.
 type HandlerFunc func(ctx context.Context, req, err interface{}) error
 
+// Panic is the recovered panic, it is in the context of the recovery handler.
+type Panic struct {
+	// Value is the value passed to panic.
+	Value interface{}
+	// Operation is the operation of the request which panics.
+	Operation string
+	// Stack is the stack trace of the goroutine when it panics.
+	Stack []byte
+}
+
+type panicKey struct{}
+
+// FromContext returns the recovered panic in the context of the recovery handler.
+func FromContext(ctx context.Context) (*Panic, bool) {
+	p, ok := ctx.Value(panicKey{}).(*Panic)
+	return p, ok
+}
+
 // Option is recovery option.
 type Option func(*options)
 
@@ -21,7 +40,8 @@ type options struct {
 	logger  log.Logger
 }
 
-// WithHandler with recovery handler.
+// WithHandler with recovery handler, which maps the panic to the returned error,
+// e.g. to report the panic of FromContext(ctx) to an alerting system.
 func WithHandler(h HandlerFunc) Option {
 	return func(o *options) {
 		o.handler = h
@@ -46,17 +66,25 @@ func Recovery(opts ...Option) middleware.Middleware {
 	for _, o := range opts {
 		o(&options)
 	}
-	logger := log.NewHelper(options.logger)
 	return func(handler middleware.Handler) middleware.Handler {
 		return func(ctx context.Context, req interface{}) (reply interface{}, err error) {
 			defer func() {
 				if rerr := recover(); rerr != nil {
+					// the deferred call runs on top of the panicking frames, so the stack has the panic site.
 					buf := make([]byte, 64<<10)
 					n := runtime.Stack(buf, false)
-					buf = buf[:n]
-					logger.Errorf("%v: %+v\n%s\n", rerr, req, buf)
-
-					err = options.handler(ctx, req, rerr)
+					p := &Panic{Value: rerr, Stack: buf[:n]}
+					if tr, ok := transport.FromServerContext(ctx); ok {
+						p.Operation = tr.Operation()
+					}
+					_ = log.WithContext(ctx, options.logger).Log(log.LevelError,
+						"msg", "panic recovered",
+						"operation", p.Operation,
+						"panic", rerr,
+						"args", fmt.Sprintf("%+v", req),
+						"stack", string(p.Stack),
+					)
+					err = options.handler(context.WithValue(ctx, panicKey{}, p), req, rerr)
 				}
 			}()
 			return handler(ctx, req)
@@ -1,8 +1,14 @@
 package recovery
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"strings"
 	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
 )
 
 func TestOnce(t *testing.T) {
@@ -18,3 +24,25 @@ func TestOnce(t *testing.T) {
 	_, e := Recovery()(next)(context.Background(), "panic")
 	t.Logf("succ and reason is %v", e)
 }
+
+func TestWithHandler(t *testing.T) {
+	var buf bytes.Buffer
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("panic reason")
+	}
+	var p *Panic
+	h := Recovery(WithLogger(log.NewStdLogger(&buf)), WithHandler(func(ctx context.Context, req, err interface{}) error {
+		p, _ = FromContext(ctx)
+		return errors.ServiceUnavailable("PANIC", fmt.Sprint(err))
+	}))(next)
+	_, err := h(context.Background(), "req")
+	if se := errors.FromError(err); se.Reason != "PANIC" || se.Message != "panic reason" {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if p == nil || p.Value != "panic reason" || !strings.Contains(string(p.Stack), "TestWithHandler") {
+		t.Fatalf("unexpected panic %+v", p)
+	}
+	if !strings.Contains(buf.String(), "msg=panic recovered") || !strings.Contains(buf.String(), "panic=panic reason") {
+		t.Fatalf("unexpected log %s", buf.String())
+	}
+}
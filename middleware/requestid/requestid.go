@@ -0,0 +1,96 @@
+package requestid
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/google/uuid"
+)
+
+const defaultHeader = "x-request-id"
+
+type requestIDKey struct{}
+
+// Option is request id option.
+type Option func(*options)
+
+type options struct {
+	header    string
+	generator func() string
+}
+
+// WithHeader with the header key of the request id, default is x-request-id.
+func WithHeader(key string) Option {
+	return func(o *options) {
+		o.header = key
+	}
+}
+
+// WithGenerator with the generator of the request ids, default is a random UUID.
+func WithGenerator(fn func() string) Option {
+	return func(o *options) {
+		o.generator = fn
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		header:    defaultHeader,
+		generator: func() string { return uuid.New().String() },
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Server is a server middleware which reads the request id of the request header, or generates one if it is absent.
+// The request id is stored in the context and set to the reply header.
+func Server(opts ...Option) middleware.Middleware {
+	o := newOptions(opts)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (reply interface{}, err error) {
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				id := tr.RequestHeader().Get(o.header)
+				if id == "" {
+					id = o.generator()
+				}
+				tr.ReplyHeader().Set(o.header, id)
+				ctx = context.WithValue(ctx, requestIDKey{}, id)
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// Client is a client middleware which forwards the request id of the context to the request header,
+// the request id already set to the request header is kept.
+func Client(opts ...Option) middleware.Middleware {
+	o := newOptions(opts)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (reply interface{}, err error) {
+			if tr, ok := transport.FromClientContext(ctx); ok {
+				if id, ok := RequestID(ctx); ok && tr.RequestHeader().Get(o.header) == "" {
+					tr.RequestHeader().Set(o.header, id)
+				}
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// RequestID returns the request id of the server context.
+func RequestID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok
+}
+
+// Valuer returns a request id log valuer.
+func Valuer() log.Valuer {
+	return func(ctx context.Context) interface{} {
+		id, _ := RequestID(ctx)
+		return id
+	}
+}
@@ -0,0 +1,98 @@
+package requestid
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/stretchr/testify/assert"
+)
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string { return http.Header(hc).Get(key) }
+
+func (hc headerCarrier) Set(key string, value string) { http.Header(hc).Set(key, value) }
+
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range http.Header(hc) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+type testTransport struct {
+	reqHeader   headerCarrier
+	replyHeader headerCarrier
+}
+
+func (tr *testTransport) Kind() transport.Kind            { return transport.KindHTTP }
+func (tr *testTransport) Endpoint() string                { return "" }
+func (tr *testTransport) Operation() string               { return "" }
+func (tr *testTransport) RequestHeader() transport.Header { return tr.reqHeader }
+func (tr *testTransport) ReplyHeader() transport.Header   { return tr.replyHeader }
+
+func newTransport(header http.Header) *testTransport {
+	return &testTransport{reqHeader: headerCarrier(header), replyHeader: headerCarrier{}}
+}
+
+func TestServer(t *testing.T) {
+	var id string
+	h := Server(WithGenerator(func() string { return "generated" }))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		id, _ = RequestID(ctx)
+		return nil, nil
+	})
+
+	tr := newTransport(http.Header{"X-Request-Id": {"foo"}})
+	_, err := h(transport.NewServerContext(context.Background(), tr), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", id)
+	assert.Equal(t, "foo", tr.replyHeader.Get("x-request-id"))
+
+	tr = newTransport(http.Header{})
+	_, err = h(transport.NewServerContext(context.Background(), tr), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "generated", id)
+	assert.Equal(t, "generated", tr.replyHeader.Get("x-request-id"))
+
+	_, ok := RequestID(context.Background())
+	assert.False(t, ok)
+}
+
+func TestServerDefaultGenerator(t *testing.T) {
+	var ids []string
+	h := Server()(func(ctx context.Context, req interface{}) (interface{}, error) {
+		ids = append(ids, Valuer()(ctx).(string))
+		return nil, nil
+	})
+	for i := 0; i < 2; i++ {
+		_, err := h(transport.NewServerContext(context.Background(), newTransport(http.Header{})), nil)
+		assert.NoError(t, err)
+	}
+	assert.Len(t, ids[0], 36)
+	assert.NotEqual(t, ids[0], ids[1])
+}
+
+func TestClient(t *testing.T) {
+	var out *testTransport
+	h := Server(WithHeader("x-trace"))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		out = newTransport(http.Header{})
+		return Client(WithHeader("x-trace"))(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, nil
+		})(transport.NewClientContext(ctx, out), req)
+	})
+	_, err := h(transport.NewServerContext(context.Background(), newTransport(http.Header{"X-Trace": {"foo"}})), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", out.reqHeader.Get("x-trace"))
+
+	// the request id set by the caller is kept.
+	out = newTransport(http.Header{"X-Trace": {"bar"}})
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "foo")
+	_, err = Client(WithHeader("x-trace"))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})(transport.NewClientContext(ctx, out), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", out.reqHeader.Get("x-trace"))
+}
@@ -0,0 +1,176 @@
+package shadow
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/metadata"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/http"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Result is the result of a mirrored request along with the one of the primary request.
+type Result struct {
+	Operation string
+	Request   interface{}
+	// Reply and Err are the result of the primary request.
+	Reply interface{}
+	Err   error
+	// ShadowReply and ShadowErr are the result of the shadow request, the reply is decoded into a new value
+	// of the type of the primary reply if it is a pointer, otherwise into a generic value, e.g. map[string]interface{}.
+	ShadowReply interface{}
+	ShadowErr   error
+}
+
+// Option is shadow option.
+type Option func(*options)
+
+type options struct {
+	rate       float64
+	predicate  func(ctx context.Context, req interface{}) bool
+	comparator func(ctx context.Context, r Result)
+	timeout    time.Duration
+	maxPending int64
+	random     func() float64
+}
+
+// WithRate with the sampling rate of the mirrored requests in [0, 1], default is 1, which mirrors all requests.
+func WithRate(rate float64) Option {
+	return func(o *options) {
+		o.rate = rate
+	}
+}
+
+// WithPredicate with the predicate of the mirrored requests, which is checked before the sampling,
+// default is all requests.
+func WithPredicate(f func(ctx context.Context, req interface{}) bool) Option {
+	return func(o *options) {
+		o.predicate = f
+	}
+}
+
+// WithComparator with the hook which is called with the results of every mirrored request, e.g. to record
+// the mismatches between the primary and the shadow replies. It is called in the shadow goroutine,
+// and the context is the detached context of the shadow request.
+func WithComparator(f func(ctx context.Context, r Result)) Option {
+	return func(o *options) {
+		o.comparator = f
+	}
+}
+
+// WithTimeout with the timeout of the shadow requests, default is 1 second.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// WithMaxPending with the max shadow requests in flight, the requests beyond it are not mirrored,
+// default is 100.
+func WithMaxPending(n int) Option {
+	return func(o *options) {
+		o.maxPending = int64(n)
+	}
+}
+
+// Server is a server middleware which mirrors the HTTP requests to the shadow backend of the client asynchronously,
+// e.g. to validate a rewrite of a service, the requests of the other transports are not mirrored.
+// The shadow request is sent with the method and the URI of the primary request after the primary request is served,
+// its reply is only passed to the comparator, and it never slows or fails the primary request. The proto request is cloned before
+// it is served, the other requests must not be modified by the handler.
+func Server(client *http.Client, opts ...Option) middleware.Middleware {
+	o := &options{
+		rate:       1,
+		timeout:    time.Second,
+		maxPending: 100,
+		random:     rand.Float64,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	var pending int64
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok {
+				return handler(ctx, req)
+			}
+			ht, ok := tr.(*http.Transport)
+			if !ok || ht.Request() == nil || !o.sample(ctx, req) {
+				return handler(ctx, req)
+			}
+			if atomic.AddInt64(&pending, 1) > o.maxPending {
+				atomic.AddInt64(&pending, -1)
+				return handler(ctx, req)
+			}
+			var (
+				r      = ht.Request()
+				method = r.Method
+				uri    = r.URL.RequestURI()
+				ct     = r.Header.Get("Content-Type")
+				args   = clone(req)
+				sctx   = detach(ctx)
+			)
+			reply, err := handler(ctx, req)
+			go func() {
+				defer atomic.AddInt64(&pending, -1)
+				sctx, cancel := context.WithTimeout(sctx, o.timeout)
+				defer cancel()
+				var callOpts []http.CallOption
+				if args != nil && ct != "" {
+					callOpts = append(callOpts, http.ContentType(ct))
+				}
+				res := Result{Operation: tr.Operation(), Request: args, Reply: reply, Err: err}
+				if out := newReply(reply); out != nil {
+					res.ShadowErr = client.Invoke(sctx, method, uri, args, out, callOpts...)
+					res.ShadowReply = out
+				} else {
+					var out interface{}
+					res.ShadowErr = client.Invoke(sctx, method, uri, args, &out, callOpts...)
+					res.ShadowReply = out
+				}
+				if o.comparator != nil {
+					o.comparator(sctx, res)
+				}
+			}()
+			return reply, err
+		}
+	}
+}
+
+func (o *options) sample(ctx context.Context, req interface{}) bool {
+	if o.predicate != nil && !o.predicate(ctx, req) {
+		return false
+	}
+	return o.rate >= 1 || (o.rate > 0 && o.random() < o.rate)
+}
+
+// detach returns a context which is not canceled with the primary request, the metadata is kept.
+func detach(ctx context.Context) context.Context {
+	sctx := context.Background()
+	if md, ok := metadata.FromServerContext(ctx); ok {
+		sctx = metadata.NewClientContext(sctx, md.Clone())
+	}
+	return sctx
+}
+
+func clone(req interface{}) interface{} {
+	if m, ok := req.(proto.Message); ok {
+		return proto.Clone(m)
+	}
+	return req
+}
+
+// newReply returns a new value of the type of the reply, or nil if the reply is not a pointer.
+func newReply(reply interface{}) interface{} {
+	if t := reflect.TypeOf(reply); t != nil && t.Kind() == reflect.Ptr {
+		return reflect.New(t.Elem()).Interface()
+	}
+	return nil
+}
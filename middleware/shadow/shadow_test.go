@@ -0,0 +1,123 @@
+package shadow
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+	"github.com/stretchr/testify/assert"
+)
+
+type user struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func newShadow(t *testing.T, name string) (*khttp.Client, chan *http.Request) {
+	reqs := make(chan *http.Request, 10)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqs <- r
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(&user{ID: "1", Name: name})
+	}))
+	t.Cleanup(s.Close)
+	client, err := khttp.NewClient(context.Background(), khttp.WithEndpoint(strings.TrimPrefix(s.URL, "http://")))
+	assert.NoError(t, err)
+	return client, reqs
+}
+
+func serveHTTP(t *testing.T, m middleware.Middleware, req *http.Request) *httptest.ResponseRecorder {
+	srv := khttp.NewServer(khttp.Middleware(m))
+	_, err := srv.Endpoint()
+	assert.NoError(t, err)
+	srv.Route("/").POST("/users/{id}", func(ctx khttp.Context) error {
+		var in user
+		if err := ctx.Bind(&in); err != nil {
+			return err
+		}
+		h := ctx.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			time.Sleep(10 * time.Millisecond)
+			return &user{ID: "1", Name: "primary"}, nil
+		})
+		reply, err := h(ctx, &in)
+		if err != nil {
+			return err
+		}
+		return ctx.Result(200, reply)
+	})
+	res := httptest.NewRecorder()
+	srv.ServeHTTP(res, req)
+	return res
+}
+
+func newRequest(uri, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, uri, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestServer(t *testing.T) {
+	client, reqs := newShadow(t, "shadow")
+	results := make(chan Result, 1)
+	m := Server(client, WithComparator(func(ctx context.Context, r Result) {
+		results <- r
+	}))
+	res := serveHTTP(t, m, newRequest("/users/1?v=1", `{"id":"1","name":"kratos"}`))
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	select {
+	case r := <-results:
+		assert.Equal(t, "/users/{id}", r.Operation)
+		assert.Equal(t, &user{ID: "1", Name: "kratos"}, r.Request)
+		assert.Equal(t, &user{ID: "1", Name: "primary"}, r.Reply)
+		assert.NoError(t, r.ShadowErr)
+		assert.Equal(t, &user{ID: "1", Name: "shadow"}, r.ShadowReply)
+	case <-time.After(time.Second):
+		t.Fatal("no shadow result")
+	}
+	sr := <-reqs
+	assert.Equal(t, http.MethodPost, sr.Method)
+	assert.Equal(t, "/users/1?v=1", sr.URL.RequestURI())
+	assert.Equal(t, "application/json", sr.Header.Get("Content-Type"))
+}
+
+func TestServerSampling(t *testing.T) {
+	client, reqs := newShadow(t, "shadow")
+	tests := []Option{
+		WithRate(0),
+		WithPredicate(func(ctx context.Context, req interface{}) bool { return false }),
+		func(o *options) {
+			o.rate = 0.5
+			o.random = func() float64 { return 0.9 }
+		},
+	}
+	for _, opt := range tests {
+		res := serveHTTP(t, Server(client, opt), newRequest("/users/1", `{"id":"1"}`))
+		assert.Equal(t, http.StatusOK, res.Code)
+	}
+	select {
+	case <-reqs:
+		t.Fatal("unexpected shadow request")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestServerShadowFailure(t *testing.T) {
+	client, err := khttp.NewClient(context.Background(), khttp.WithEndpoint("127.0.0.1:1"))
+	assert.NoError(t, err)
+	results := make(chan Result, 1)
+	m := Server(client, WithTimeout(100*time.Millisecond), WithComparator(func(ctx context.Context, r Result) {
+		results <- r
+	}))
+	res := serveHTTP(t, m, newRequest("/users/1", `{"id":"1"}`))
+	assert.Equal(t, http.StatusOK, res.Code)
+	r := <-results
+	assert.NoError(t, r.Err)
+	assert.Error(t, r.ShadowErr)
+}
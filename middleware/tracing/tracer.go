@@ -57,6 +57,13 @@ func (t *Tracer) Start(ctx context.Context, operation string, carrier propagatio
 	return ctx, span
 }
 
+// setAttributes adds the attributes of the request to the span.
+func (t *Tracer) setAttributes(ctx context.Context, span trace.Span, req interface{}) {
+	if t.opt.attributes != nil {
+		span.SetAttributes(t.opt.attributes(ctx, req)...)
+	}
+}
+
 // End finish tracing span
 func (t *Tracer) End(ctx context.Context, span trace.Span, m interface{}, err error) {
 	if err != nil {
@@ -5,6 +5,7 @@ import (
 
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/transport"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -15,9 +16,11 @@ type Option func(*options)
 type options struct {
 	tracerProvider trace.TracerProvider
 	propagator     propagation.TextMapPropagator
+	attributes     func(ctx context.Context, req interface{}) []attribute.KeyValue
 }
 
-// WithPropagator with tracer propagator.
+// WithPropagator with tracer propagator, the default propagator propagates
+// the kratos metadata, the W3C trace context and the W3C baggage.
 func WithPropagator(propagator propagation.TextMapPropagator) Option {
 	return func(opts *options) {
 		opts.propagator = propagator
@@ -32,6 +35,14 @@ func WithTracerProvider(provider trace.TracerProvider) Option {
 	}
 }
 
+// WithSpanAttributes with the attributes derived from the request, e.g. the tenant ID of the metadata or the baggage,
+// which are added to the span before the handler runs.
+func WithSpanAttributes(f func(ctx context.Context, req interface{}) []attribute.KeyValue) Option {
+	return func(opts *options) {
+		opts.attributes = f
+	}
+}
+
 // Server returns a new server middleware for OpenTelemetry.
 func Server(opts ...Option) middleware.Middleware {
 	tracer := NewTracer(trace.SpanKindServer, opts...)
@@ -41,6 +52,7 @@ func Server(opts ...Option) middleware.Middleware {
 				var span trace.Span
 				ctx, span = tracer.Start(ctx, tr.Operation(), tr.RequestHeader())
 				setServerSpan(ctx, span, req)
+				tracer.setAttributes(ctx, span, req)
 				defer func() { tracer.End(ctx, span, reply, err) }()
 			}
 			return handler(ctx, req)
@@ -57,6 +69,7 @@ func Client(opts ...Option) middleware.Middleware {
 				var span trace.Span
 				ctx, span = tracer.Start(ctx, tr.Operation(), tr.RequestHeader())
 				setClientSpan(ctx, span, req)
+				tracer.setAttributes(ctx, span, req)
 				defer func() { tracer.End(ctx, span, reply, err) }()
 			}
 			return handler(ctx, req)
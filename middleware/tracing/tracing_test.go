@@ -6,8 +6,12 @@ import (
 	"testing"
 
 	"github.com/go-kratos/kratos/v2/transport"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
 	"go.opentelemetry.io/otel/propagation"
 	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -75,3 +79,59 @@ func TestTracing(t *testing.T) {
 		t.Fatalf("traceHeader failed to deliver")
 	}
 }
+
+func TestBaggageAndSpanAttributes(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := tracesdk.NewTracerProvider(tracesdk.WithSyncer(exporter))
+	otel.SetTracerProvider(tp)
+
+	tenant, err := baggage.NewMember("tenant", "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bag, err := baggage.New(tenant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attrs := WithSpanAttributes(func(ctx context.Context, req interface{}) []attribute.KeyValue {
+		return []attribute.KeyValue{attribute.String("tenant", baggage.FromContext(ctx).Member("tenant").Value())}
+	})
+
+	// the client injects the baggage into the carrier.
+	carrier := headerCarrier{}
+	ctx := baggage.ContextWithBaggage(context.Background(), bag)
+	ctx = transport.NewClientContext(ctx, &Transport{kind: transport.KindHTTP, operation: "/client", header: carrier})
+	_, err = Client(attrs)(func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil })(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the server extracts the baggage from the carrier before the handler runs.
+	var got string
+	ctx = transport.NewServerContext(context.Background(), &Transport{kind: transport.KindHTTP, operation: "/server", header: carrier})
+	_, err = Server(attrs)(func(ctx context.Context, req interface{}) (interface{}, error) {
+		got = baggage.FromContext(ctx).Member("tenant").Value()
+		return nil, nil
+	})(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "t1" {
+		t.Fatalf("want the baggage t1, got %q", got)
+	}
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("want 2 spans, got %d", len(spans))
+	}
+	for _, span := range spans {
+		var found bool
+		for _, kv := range span.Attributes {
+			if kv.Key == "tenant" && kv.Value.AsString() == "t1" {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("span %s has no tenant attribute", span.Name)
+		}
+	}
+}
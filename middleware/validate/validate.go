@@ -2,21 +2,75 @@ package validate
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/middleware"
+
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 type validator interface {
 	Validate() error
 }
 
+// allValidator is the request which reports the errors of all fields, which is generated by protoc-gen-validate.
+type allValidator interface {
+	ValidateAll() error
+}
+
+// updateMasker is the request with a conventional update_mask field.
+type updateMasker interface {
+	GetUpdateMask() *fieldmaskpb.FieldMask
+}
+
+// fieldError is the validation error of a field, which is generated by protoc-gen-validate.
+type fieldError interface {
+	Field() string
+	Cause() error
+}
+
+// multiError is the validation errors of all fields, which is returned by ValidateAll.
+type multiError interface {
+	AllErrors() []error
+}
+
+// Option is validator option.
+type Option func(*options)
+
+type options struct {
+	fieldMask bool
+}
+
+// WithFieldMask with the partial update validation, the validation errors of the fields
+// which are not in the update_mask of the request are ignored, the requests without
+// an update_mask are fully validated. The requests are validated by ValidateAll if they have it,
+// so that an error of an ignored field doesn't hide the errors of the updated fields.
+func WithFieldMask() Option {
+	return func(o *options) {
+		o.fieldMask = true
+	}
+}
+
 // Validator is a validator middleware.
-func Validator() middleware.Middleware {
+func Validator(opts ...Option) middleware.Middleware {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
 	return func(handler middleware.Handler) middleware.Handler {
 		return func(ctx context.Context, req interface{}) (reply interface{}, err error) {
 			if v, ok := req.(validator); ok {
-				if err := v.Validate(); err != nil {
+				err := v.Validate()
+				if o.fieldMask {
+					// the first error may be of an unmasked field, so all fields are validated.
+					if va, ok := req.(allValidator); ok {
+						err = va.ValidateAll()
+					}
+					err = maskErrors(req, err)
+				}
+				if err != nil {
 					return nil, errors.BadRequest("VALIDATOR", err.Error())
 				}
 			}
@@ -24,3 +78,131 @@ func Validator() middleware.Middleware {
 		}
 	}
 }
+
+// maskErrors drops the validation errors of the fields which are not in the update mask.
+func maskErrors(req interface{}, err error) error {
+	if err == nil {
+		return nil
+	}
+	m, ok := req.(updateMasker)
+	if !ok || len(m.GetUpdateMask().GetPaths()) == 0 {
+		return err
+	}
+	// the errors report the Go field names, e.g. "Article.Title" for the mask path "article.title".
+	paths := make([]string, 0, len(m.GetUpdateMask().GetPaths()))
+	for _, p := range m.GetUpdateMask().GetPaths() {
+		paths = append(paths, goFieldPath(p))
+	}
+	errs := errorPaths("", err)
+	var kept []error
+	for _, e := range errs {
+		if e.path == "" || e.path == "UpdateMask" || masked(paths, e.path) {
+			kept = append(kept, e.err)
+		}
+	}
+	switch len(kept) {
+	case 0:
+		return nil
+	case len(errs):
+		return err
+	case 1:
+		return kept[0]
+	}
+	msgs := make([]string, 0, len(kept))
+	for _, e := range kept {
+		msgs = append(msgs, e.Error())
+	}
+	return fmt.Errorf("%s", strings.Join(msgs, "; "))
+}
+
+// pathError is a validation error with the Go field path, the path is empty if the error is not of a field.
+type pathError struct {
+	path string
+	err  error
+}
+
+// errorPaths flattens the validation error into the errors of the leaf fields, e.g. "User.Email",
+// the errors of the embedded messages validated by ValidateAll are flattened into their fields.
+func errorPaths(prefix string, err error) []pathError {
+	path := prefix
+	for cause := err; cause != nil; {
+		if me, ok := cause.(multiError); ok {
+			var errs []pathError
+			for _, e := range me.AllErrors() {
+				errs = append(errs, errorPaths(path, e)...)
+			}
+			return errs
+		}
+		fe, ok := cause.(fieldError)
+		if !ok {
+			break
+		}
+		path = joinPath(path, fieldName(fe.Field()))
+		cause = fe.Cause()
+	}
+	return []pathError{{path: path, err: err}}
+}
+
+// fieldName strips the index of the repeated and map items, e.g. "Items[0]".
+func fieldName(field string) string {
+	if i := strings.IndexByte(field, '['); i >= 0 {
+		return field[:i]
+	}
+	return field
+}
+
+func joinPath(prefix, field string) string {
+	if prefix == "" {
+		return field
+	}
+	return prefix + "." + field
+}
+
+// goFieldPath converts the proto field names of the mask path into the Go field names, e.g. "update_mask" into "UpdateMask".
+func goFieldPath(path string) string {
+	fields := strings.Split(path, ".")
+	for i, f := range fields {
+		fields[i] = goCamelCase(f)
+	}
+	return strings.Join(fields, ".")
+}
+
+// goCamelCase is the Go field name of the proto field name, the same as protoc-gen-go.
+func goCamelCase(s string) string {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '_' && i == 0:
+			b = append(b, 'X')
+		case c == '_' && i+1 < len(s) && isASCIILower(s[i+1]):
+			// skip over '_' in "_{{lowercase}}".
+		case isASCIIDigit(c):
+			b = append(b, c)
+		default:
+			if isASCIILower(c) {
+				c -= 'a' - 'A'
+			}
+			b = append(b, c)
+			for ; i+1 < len(s) && isASCIILower(s[i+1]); i++ {
+				b = append(b, s[i+1])
+			}
+		}
+	}
+	return string(b)
+}
+
+func isASCIILower(c byte) bool { return 'a' <= c && c <= 'z' }
+
+func isASCIIDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+// masked reports whether the path is updated by the mask paths, the path is updated
+// by itself, its ancestors and its descendants in the mask.
+func masked(paths []string, path string) bool {
+	for _, p := range paths {
+		if p == path || strings.HasPrefix(path, p+".") || strings.HasPrefix(p, path+".") {
+			return true
+		}
+	}
+	return false
+}
@@ -7,6 +7,10 @@ import (
 
 	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/middleware"
+
+	"github.com/go-kratos/kratos/v2/internal/testproto/update"
+
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 // protoVali implement validate.validator
@@ -41,3 +45,94 @@ func TestTable(t *testing.T) {
 		})
 	}
 }
+
+type fieldErr struct {
+	field string
+	cause error
+}
+
+func (e fieldErr) Field() string { return e.field }
+func (e fieldErr) Cause() error  { return e.cause }
+func (e fieldErr) Error() string { return "invalid " + e.field }
+
+type multiErr []error
+
+func (e multiErr) AllErrors() []error { return e }
+func (e multiErr) Error() string      { return fmt.Sprint([]error(e)) }
+
+type updateReq struct {
+	mask *fieldmaskpb.FieldMask
+	errs multiErr
+}
+
+func (r updateReq) GetUpdateMask() *fieldmaskpb.FieldMask { return r.mask }
+func (r updateReq) Validate() error {
+	if len(r.errs) == 0 {
+		return nil
+	}
+	return r.errs
+}
+
+func TestWithFieldMask(t *testing.T) {
+	var mock middleware.Handler = func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+	errs := multiErr{
+		fieldErr{field: "Name"},
+		fieldErr{field: "User", cause: fieldErr{field: "Email"}},
+	}
+	tests := []struct {
+		name  string
+		req   updateReq
+		isErr bool
+	}{
+		{"no mask", updateReq{errs: errs}, true},
+		{"unmasked", updateReq{mask: &fieldmaskpb.FieldMask{Paths: []string{"age"}}, errs: errs}, false},
+		{"masked", updateReq{mask: &fieldmaskpb.FieldMask{Paths: []string{"name"}}, errs: errs}, true},
+		{"nested", updateReq{mask: &fieldmaskpb.FieldMask{Paths: []string{"user.email"}}, errs: errs}, true},
+		{"parent", updateReq{mask: &fieldmaskpb.FieldMask{Paths: []string{"user"}}, errs: errs}, true},
+		{"sibling", updateReq{mask: &fieldmaskpb.FieldMask{Paths: []string{"user.phone"}}, errs: errs}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := Validator(WithFieldMask())(mock)
+			_, err := v(context.Background(), test.req)
+			if want, have := test.isErr, errors.IsBadRequest(err); want != have {
+				t.Errorf("want %v, have %v: %v", want, have, err)
+			}
+		})
+	}
+	_, err := Validator()(mock)(context.Background(), updateReq{mask: &fieldmaskpb.FieldMask{Paths: []string{"age"}}, errs: errs})
+	if !errors.IsBadRequest(err) {
+		t.Errorf("want the full validation without the option, have %v", err)
+	}
+}
+
+func TestWithFieldMaskGenerated(t *testing.T) {
+	var mock middleware.Handler = func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+	// the id and the content are invalid, the first error of the id must not stop the validation of the masked fields.
+	req := func(title string, paths ...string) *update.UpdateArticleRequest {
+		return &update.UpdateArticleRequest{
+			Article:    &update.Article{Title: title},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: paths},
+		}
+	}
+	tests := []struct {
+		name  string
+		req   *update.UpdateArticleRequest
+		isErr bool
+	}{
+		{"masked invalid", req("bad", "article.title"), true},
+		{"masked valid", req("kratos", "article.title"), false},
+		{"masked content", req("kratos", "article.content"), true},
+		{"parent", req("kratos", "article"), true},
+		{"no update mask", &update.UpdateArticleRequest{Id: 1, Article: &update.Article{Title: "kratos", Content: "c"}}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := Validator(WithFieldMask())(mock)
+			_, err := v(context.Background(), test.req)
+			if want, have := test.isErr, errors.IsBadRequest(err); want != have {
+				t.Errorf("want %v, have %v: %v", want, have, err)
+			}
+		})
+	}
+}
@@ -22,13 +22,20 @@ type options struct {
 	metadata  map[string]string
 	endpoints []*url.URL
 
-	ctx  context.Context
-	sigs []os.Signal
+	ctx     context.Context
+	sigs    []os.Signal
+	reloads map[os.Signal]func() error
 
 	logger           *log.Helper
 	registrar        registry.Registrar
 	registrarTimeout time.Duration
 	servers          []transport.Server
+	dependencies     map[transport.Server][]transport.Server
+	// debugAddr is empty unless the debug server is enabled.
+	debugAddr string
+
+	beforeReady []func(context.Context) error
+	afterReady  []func(context.Context) error
 }
 
 // ID with service id.
@@ -73,11 +80,34 @@ func Server(srv ...transport.Server) Option {
 	return func(o *options) { o.servers = srv }
 }
 
+// DependsOn with the servers which must be up before the server starts, a server is up once
+// its endpoint is listening. The servers are started in the dependency order and stopped
+// in the reverse order, the servers without any dependency are started concurrently.
+func DependsOn(srv transport.Server, deps ...transport.Server) Option {
+	return func(o *options) {
+		if o.dependencies == nil {
+			o.dependencies = make(map[transport.Server][]transport.Server)
+		}
+		o.dependencies[srv] = append(o.dependencies[srv], deps...)
+	}
+}
+
 // Signal with exit signals.
 func Signal(sigs ...os.Signal) Option {
 	return func(o *options) { o.sigs = sigs }
 }
 
+// ReloadSignal with the reload signal, e.g. syscall.SIGHUP, the reload runs on the signal
+// instead of stopping the app, and its error is logged.
+func ReloadSignal(sig os.Signal, reload func() error) Option {
+	return func(o *options) {
+		if o.reloads == nil {
+			o.reloads = make(map[os.Signal]func() error)
+		}
+		o.reloads[sig] = reload
+	}
+}
+
 // Registrar with service registry.
 func Registrar(r registry.Registrar) Option {
 	return func(o *options) { o.registrar = r }
@@ -87,3 +117,14 @@ func Registrar(r registry.Registrar) Option {
 func RegistrarTimeout(t time.Duration) Option {
 	return func(o *options) { o.registrarTimeout = t }
 }
+
+// BeforeReady with the hooks which run after the servers are listening and the service is registered,
+// the app is not ready if any hook fails.
+func BeforeReady(fn func(context.Context) error) Option {
+	return func(o *options) { o.beforeReady = append(o.beforeReady, fn) }
+}
+
+// AfterReady with the hooks which run after the app is ready.
+func AfterReady(fn func(context.Context) error) Option {
+	return func(o *options) { o.afterReady = append(o.afterReady, fn) }
+}
@@ -119,3 +119,18 @@ func TestRegistrarTimeout(t *testing.T) {
 	RegistrarTimeout(v)(o)
 	assert.Equal(t, v, o.registrarTimeout)
 }
+
+func TestDependsOn(t *testing.T) {
+	o := &options{}
+	a, b, c := &orderedServer{name: "a"}, &orderedServer{name: "b"}, &orderedServer{name: "c"}
+	DependsOn(a, b)(o)
+	DependsOn(a, c)(o)
+	assert.Equal(t, []transport.Server{b, c}, o.dependencies[a])
+}
+
+func TestReloadSignal(t *testing.T) {
+	o := &options{}
+	sig := &mockSignal{}
+	ReloadSignal(sig, func() error { return nil })(o)
+	assert.NotNil(t, o.reloads[sig])
+}
@@ -0,0 +1,132 @@
+package registry
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+)
+
+var _ Watcher = (*DiffWatcher)(nil)
+
+// EventType is the type of an instance event.
+type EventType int
+
+const (
+	// EventAdd is an instance which is not in the previous snapshot.
+	EventAdd EventType = iota
+	// EventUpdate is an instance whose fields changed since the previous snapshot.
+	EventUpdate
+	// EventRemove is an instance which is not in the current snapshot.
+	EventRemove
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventAdd:
+		return "add"
+	case EventUpdate:
+		return "update"
+	case EventRemove:
+		return "remove"
+	}
+	return "unknown"
+}
+
+// Event is the change of an instance between two snapshots of a watcher, the instances are matched by ID.
+type Event struct {
+	Type EventType
+	// Instance is the current instance, or the removed one.
+	Instance *ServiceInstance
+	// Previous is the instance of the previous snapshot, it is nil unless the event is an update.
+	Previous *ServiceInstance
+	// Changed is the changed fields of an update, e.g. "version", "endpoints", or "metadata.draining"
+	// for a metadata key, in the sorted order.
+	Changed []string
+}
+
+// DiffWatcher is a watcher which also reports the changes between the snapshots of the wrapped watcher.
+type DiffWatcher struct {
+	watcher Watcher
+	lock    sync.Mutex
+	prev    map[string]*ServiceInstance
+}
+
+// NewDiffWatcher returns a DiffWatcher of the watcher, the first snapshot is reported as added instances.
+func NewDiffWatcher(w Watcher) *DiffWatcher {
+	return &DiffWatcher{watcher: w}
+}
+
+// Next returns the next snapshot of the wrapped watcher, and it is remembered as the previous snapshot.
+func (w *DiffWatcher) Next() ([]*ServiceInstance, error) {
+	_, instances, err := w.NextEvents()
+	return instances, err
+}
+
+// NextEvents returns the changes since the previous snapshot along with the next snapshot,
+// the events are in the order of the snapshot, followed by the removed instances in the ID order.
+func (w *DiffWatcher) NextEvents() ([]Event, []*ServiceInstance, error) {
+	instances, err := w.watcher.Next()
+	if err != nil {
+		return nil, nil, err
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	next := make(map[string]*ServiceInstance, len(instances))
+	var events []Event
+	for _, ins := range instances {
+		if _, ok := next[ins.ID]; ok {
+			continue
+		}
+		next[ins.ID] = ins
+		prev, ok := w.prev[ins.ID]
+		if !ok {
+			events = append(events, Event{Type: EventAdd, Instance: ins})
+			continue
+		}
+		if changed := diffInstance(prev, ins); len(changed) > 0 {
+			events = append(events, Event{Type: EventUpdate, Instance: ins, Previous: prev, Changed: changed})
+		}
+	}
+	var removed []string
+	for id := range w.prev {
+		if _, ok := next[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+	sort.Strings(removed)
+	for _, id := range removed {
+		events = append(events, Event{Type: EventRemove, Instance: w.prev[id]})
+	}
+	w.prev = next
+	return events, instances, nil
+}
+
+// Stop stops the wrapped watcher.
+func (w *DiffWatcher) Stop() error {
+	return w.watcher.Stop()
+}
+
+func diffInstance(prev, next *ServiceInstance) []string {
+	var changed []string
+	if prev.Name != next.Name {
+		changed = append(changed, "name")
+	}
+	if prev.Version != next.Version {
+		changed = append(changed, "version")
+	}
+	if (len(prev.Endpoints) > 0 || len(next.Endpoints) > 0) && !reflect.DeepEqual(prev.Endpoints, next.Endpoints) {
+		changed = append(changed, "endpoints")
+	}
+	for k, v := range prev.Metadata {
+		if nv, ok := next.Metadata[k]; !ok || nv != v {
+			changed = append(changed, "metadata."+k)
+		}
+	}
+	for k := range next.Metadata {
+		if _, ok := prev.Metadata[k]; !ok {
+			changed = append(changed, "metadata."+k)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
@@ -0,0 +1,53 @@
+package registry
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestDiffWatcher(t *testing.T) {
+	updates := make(chan []*ServiceInstance, 3)
+	w := NewDiffWatcher(&testWatcher{ctx: context.Background(), updates: updates})
+	a := &ServiceInstance{ID: "a", Version: "v1", Endpoints: []string{"http://127.0.0.1:8000"}}
+	b := &ServiceInstance{ID: "b", Version: "v1", Endpoints: []string{"http://127.0.0.1:8001"}}
+	updates <- []*ServiceInstance{a, b}
+	events, ins, err := w.NextEvents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ins) != 2 || len(events) != 2 || events[0].Type != EventAdd || events[0].Instance != a || events[1].Instance != b {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+
+	drained := &ServiceInstance{ID: "a", Version: "v1", Endpoints: a.Endpoints, Metadata: map[string]string{"draining": "true"}}
+	updates <- []*ServiceInstance{drained, b}
+	events, _, err = w.NextEvents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Event{{Type: EventUpdate, Instance: drained, Previous: a, Changed: []string{"metadata.draining"}}}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("got %+v want %+v", events, want)
+	}
+
+	c := &ServiceInstance{ID: "c", Version: "v2", Endpoints: []string{"http://127.0.0.1:8002"}}
+	updates <- []*ServiceInstance{c}
+	ins, err = w.Next()
+	if err != nil || len(ins) != 1 || ins[0] != c {
+		t.Fatalf("unexpected snapshot: %+v %v", ins, err)
+	}
+
+	updates <- []*ServiceInstance{{ID: "c", Version: "v3", Endpoints: []string{"http://127.0.0.1:8003"}}}
+	events, _, _ = w.NextEvents()
+	if len(events) != 1 || !reflect.DeepEqual(events[0].Changed, []string{"endpoints", "version"}) {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+
+	updates <- nil
+	events, _, _ = w.NextEvents()
+	if len(events) != 1 || events[0].Type != EventRemove || events[0].Instance.Version != "v3" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	_ = w.Stop()
+}
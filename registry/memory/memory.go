@@ -0,0 +1,182 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+var (
+	_ registry.Registrar = (*Registry)(nil)
+	_ registry.Discovery = (*Registry)(nil)
+	_ registry.Watcher   = (*watcher)(nil)
+)
+
+// ErrNotRegistered is returned by Deregister if the instance is not registered.
+var ErrNotRegistered = errors.New("memory: instance is not registered")
+
+// Registry is an in-memory registrar and discovery for the tests, the instances of a service are unique by ID.
+// Every change is queued to the watchers of the service as a snapshot, so the watchers observe all the changes in order.
+type Registry struct {
+	lock     sync.Mutex
+	services map[string][]*registry.ServiceInstance
+	watchers map[string]map[*watcher]struct{}
+}
+
+// New creates an in-memory registry.
+func New() *Registry {
+	return &Registry{
+		services: make(map[string][]*registry.ServiceInstance),
+		watchers: make(map[string]map[*watcher]struct{}),
+	}
+}
+
+// Register registers the instance, or replaces the registered instance of the same ID.
+func (r *Registry) Register(ctx context.Context, service *registry.ServiceInstance) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	instances := r.services[service.Name]
+	updated := make([]*registry.ServiceInstance, 0, len(instances)+1)
+	replaced := false
+	for _, ins := range instances {
+		if ins.ID == service.ID {
+			ins, replaced = service, true
+		}
+		updated = append(updated, ins)
+	}
+	if !replaced {
+		updated = append(updated, service)
+	}
+	r.set(service.Name, updated)
+	return nil
+}
+
+// Deregister deregisters the instance of the same ID.
+func (r *Registry) Deregister(ctx context.Context, service *registry.ServiceInstance) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	instances := r.services[service.Name]
+	updated := make([]*registry.ServiceInstance, 0, len(instances))
+	for _, ins := range instances {
+		if ins.ID != service.ID {
+			updated = append(updated, ins)
+		}
+	}
+	if len(updated) == len(instances) {
+		return ErrNotRegistered
+	}
+	r.set(service.Name, updated)
+	return nil
+}
+
+// Set replaces all the instances of the service in one change, e.g. with no instance to test the zero endpoint paths.
+func (r *Registry) Set(name string, instances ...*registry.ServiceInstance) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.set(name, append([]*registry.ServiceInstance(nil), instances...))
+}
+
+// set stores the instances and queues them to the watchers, the lock must be held.
+func (r *Registry) set(name string, instances []*registry.ServiceInstance) {
+	r.services[name] = instances
+	for w := range r.watchers[name] {
+		w.push(instances)
+	}
+}
+
+// Instances returns a copy of the registered instances of the service in the registration order.
+func (r *Registry) Instances(name string) []*registry.ServiceInstance {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]*registry.ServiceInstance(nil), r.services[name]...)
+}
+
+// Watchers returns the number of the active watchers of the service.
+func (r *Registry) Watchers(name string) int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return len(r.watchers[name])
+}
+
+// GetService returns the registered instances of the service.
+func (r *Registry) GetService(ctx context.Context, name string) ([]*registry.ServiceInstance, error) {
+	return r.Instances(name), nil
+}
+
+// Watch creates a watcher of the service, its first Next returns the current instances if there is any.
+func (r *Registry) Watch(ctx context.Context, name string) (registry.Watcher, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	ctx, cancel := context.WithCancel(ctx)
+	w := &watcher{
+		ctx:    ctx,
+		cancel: cancel,
+		notify: make(chan struct{}, 1),
+		stop: func(w *watcher) {
+			r.lock.Lock()
+			delete(r.watchers[name], w)
+			r.lock.Unlock()
+		},
+	}
+	if instances := r.services[name]; len(instances) > 0 {
+		w.push(instances)
+	}
+	if r.watchers[name] == nil {
+		r.watchers[name] = make(map[*watcher]struct{})
+	}
+	r.watchers[name][w] = struct{}{}
+	return w, nil
+}
+
+type watcher struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	lock   sync.Mutex
+	queue  [][]*registry.ServiceInstance
+	notify chan struct{}
+	stop   func(*watcher)
+	once   sync.Once
+}
+
+func (w *watcher) push(instances []*registry.ServiceInstance) {
+	w.lock.Lock()
+	w.queue = append(w.queue, append([]*registry.ServiceInstance(nil), instances...))
+	w.lock.Unlock()
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Next returns the next queued snapshot, it blocks until there is one or the watcher is stopped.
+func (w *watcher) Next() ([]*registry.ServiceInstance, error) {
+	for {
+		if err := w.ctx.Err(); err != nil {
+			return nil, err
+		}
+		w.lock.Lock()
+		if len(w.queue) > 0 {
+			instances := w.queue[0]
+			w.queue = w.queue[1:]
+			w.lock.Unlock()
+			return instances, nil
+		}
+		w.lock.Unlock()
+		select {
+		case <-w.notify:
+		case <-w.ctx.Done():
+			return nil, w.ctx.Err()
+		}
+	}
+}
+
+// Stop stops the watcher, the blocked Next returns context.Canceled.
+func (w *watcher) Stop() error {
+	w.once.Do(func() {
+		w.cancel()
+		w.stop(w)
+	})
+	return nil
+}
@@ -0,0 +1,100 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+func ids(ins []*registry.ServiceInstance) string {
+	var s string
+	for _, in := range ins {
+		s += in.ID
+	}
+	return s
+}
+
+func TestRegistry(t *testing.T) {
+	ctx := context.Background()
+	r := New()
+	a := &registry.ServiceInstance{ID: "a", Name: "demo", Endpoints: []string{"http://127.0.0.1:8000"}}
+	b := &registry.ServiceInstance{ID: "b", Name: "demo", Endpoints: []string{"http://127.0.0.1:8001"}}
+	if err := r.Register(ctx, a); err != nil {
+		t.Fatal(err)
+	}
+	w, err := r.Watch(ctx, "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Watchers("demo") != 1 {
+		t.Fatalf("want 1 watcher, got %d", r.Watchers("demo"))
+	}
+	if err = r.Register(ctx, b); err != nil {
+		t.Fatal(err)
+	}
+	a2 := &registry.ServiceInstance{ID: "a", Name: "demo", Version: "v2"}
+	if err = r.Register(ctx, a2); err != nil {
+		t.Fatal(err)
+	}
+	if err = r.Deregister(ctx, b); err != nil {
+		t.Fatal(err)
+	}
+	if err = r.Deregister(ctx, b); !errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("want ErrNotRegistered, got %v", err)
+	}
+	r.Set("demo")
+
+	// the watcher observes every change in order.
+	for _, want := range []string{"a", "ab", "ab", "a", ""} {
+		ins, err := w.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ids(ins); got != want {
+			t.Fatalf("want %q, got %q", want, got)
+		}
+	}
+	if ins := r.Instances("demo"); len(ins) != 0 {
+		t.Fatalf("unexpected instances: %v", ins)
+	}
+	r.Set("demo", a2)
+	if ins, _ := r.GetService(ctx, "demo"); len(ins) != 1 || ins[0] != a2 {
+		t.Fatalf("unexpected instances: %v", ins)
+	}
+
+	if err = w.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.Next(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("want context.Canceled, got %v", err)
+	}
+	if r.Watchers("demo") != 0 {
+		t.Fatalf("want no watcher, got %d", r.Watchers("demo"))
+	}
+}
+
+func TestWatchEmpty(t *testing.T) {
+	r := New()
+	w, err := r.Watch(context.Background(), "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan []*registry.ServiceInstance)
+	go func() {
+		ins, _ := w.Next()
+		done <- ins
+	}()
+	select {
+	case <-done:
+		t.Fatal("the first Next must block without instance")
+	default:
+	}
+	in := &registry.ServiceInstance{ID: "a", Name: "demo"}
+	_ = r.Register(context.Background(), in)
+	if ins := <-done; len(ins) != 1 || ins[0] != in {
+		t.Fatalf("unexpected instances: %v", ins)
+	}
+	_ = w.Stop()
+}
@@ -0,0 +1,148 @@
+package registry
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+var _ Discovery = (*multiDiscovery)(nil)
+
+type multiDiscovery struct {
+	discoveries []Discovery
+}
+
+// MultiDiscovery returns a discovery which merges the instances of the discoveries, e.g.
+// during a migration between registries. The instances are deduplicated by ID, and an
+// instance in several discoveries is taken from the first one in the argument order.
+func MultiDiscovery(discoveries ...Discovery) Discovery {
+	return &multiDiscovery{discoveries: discoveries}
+}
+
+// GetService returns the merged instances, it fails only if all the discoveries fail.
+func (d *multiDiscovery) GetService(ctx context.Context, serviceName string) ([]*ServiceInstance, error) {
+	var (
+		lists    = make([][]*ServiceInstance, len(d.discoveries))
+		errs     = make([]error, len(d.discoveries))
+		wg       sync.WaitGroup
+		firstErr error
+	)
+	for i, discovery := range d.discoveries {
+		wg.Add(1)
+		go func(i int, discovery Discovery) {
+			defer wg.Done()
+			lists[i], errs[i] = discovery.GetService(ctx, serviceName)
+		}(i, discovery)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err == nil {
+			return mergeInstances(lists), nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
+}
+
+// Watch returns a watcher which emits the merged instances whenever any discovery pushes an update.
+func (d *multiDiscovery) Watch(ctx context.Context, serviceName string) (Watcher, error) {
+	watchers := make([]Watcher, 0, len(d.discoveries))
+	for _, discovery := range d.discoveries {
+		w, err := discovery.Watch(ctx, serviceName)
+		if err != nil {
+			for _, w := range watchers {
+				_ = w.Stop()
+			}
+			return nil, err
+		}
+		watchers = append(watchers, w)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	w := &multiWatcher{
+		ctx:      ctx,
+		cancel:   cancel,
+		watchers: watchers,
+		lists:    make([][]*ServiceInstance, len(watchers)),
+		updated:  make(chan struct{}, 1),
+	}
+	for i, watcher := range watchers {
+		go w.watch(i, watcher)
+	}
+	return w, nil
+}
+
+type multiWatcher struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	watchers []Watcher
+	lock     sync.Mutex
+	lists    [][]*ServiceInstance
+	updated  chan struct{}
+}
+
+func (w *multiWatcher) watch(i int, watcher Watcher) {
+	for {
+		ins, err := watcher.Next()
+		if w.ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			// the failed discovery keeps its last instances until it recovers.
+			select {
+			case <-w.ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+		w.lock.Lock()
+		w.lists[i] = ins
+		w.lock.Unlock()
+		select {
+		case w.updated <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Next returns the merged instances once any discovery pushes an update.
+func (w *multiWatcher) Next() ([]*ServiceInstance, error) {
+	select {
+	case <-w.ctx.Done():
+		return nil, w.ctx.Err()
+	case <-w.updated:
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return mergeInstances(w.lists), nil
+}
+
+// Stop stops all the watchers.
+func (w *multiWatcher) Stop() error {
+	w.cancel()
+	var err error
+	for _, watcher := range w.watchers {
+		if e := watcher.Stop(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// mergeInstances merges the instance lists in order, the first instance of an ID wins.
+func mergeInstances(lists [][]*ServiceInstance) []*ServiceInstance {
+	seen := make(map[string]struct{})
+	merged := make([]*ServiceInstance, 0)
+	for _, list := range lists {
+		for _, in := range list {
+			if _, ok := seen[in.ID]; ok {
+				continue
+			}
+			seen[in.ID] = struct{}{}
+			merged = append(merged, in)
+		}
+	}
+	return merged
+}
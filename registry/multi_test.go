@@ -0,0 +1,104 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type testDiscovery struct {
+	instances []*ServiceInstance
+	err       error
+	updates   chan []*ServiceInstance
+}
+
+func (d *testDiscovery) GetService(ctx context.Context, serviceName string) ([]*ServiceInstance, error) {
+	return d.instances, d.err
+}
+
+func (d *testDiscovery) Watch(ctx context.Context, serviceName string) (Watcher, error) {
+	return &testWatcher{ctx: ctx, updates: d.updates}, nil
+}
+
+type testWatcher struct {
+	ctx     context.Context
+	updates chan []*ServiceInstance
+}
+
+func (w *testWatcher) Next() ([]*ServiceInstance, error) {
+	select {
+	case <-w.ctx.Done():
+		return nil, w.ctx.Err()
+	case ins := <-w.updates:
+		return ins, nil
+	}
+}
+
+func (w *testWatcher) Stop() error { return nil }
+
+func ids(ins []*ServiceInstance) []string {
+	var s []string
+	for _, in := range ins {
+		s = append(s, in.ID+"@"+in.Endpoints[0])
+	}
+	return s
+}
+
+func TestMultiDiscoveryGetService(t *testing.T) {
+	consul := &testDiscovery{instances: []*ServiceInstance{
+		{ID: "1", Endpoints: []string{"consul"}},
+		{ID: "2", Endpoints: []string{"consul"}},
+	}}
+	etcd := &testDiscovery{instances: []*ServiceInstance{
+		{ID: "2", Endpoints: []string{"etcd"}},
+		{ID: "3", Endpoints: []string{"etcd"}},
+	}}
+	ins, err := MultiDiscovery(consul, etcd).GetService(context.Background(), "svc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(ins); len(got) != 3 || got[0] != "1@consul" || got[1] != "2@consul" || got[2] != "3@etcd" {
+		t.Fatalf("unexpected instances %v", got)
+	}
+
+	failed := &testDiscovery{err: errors.New("unavailable")}
+	if ins, err = MultiDiscovery(failed, etcd).GetService(context.Background(), "svc"); err != nil || len(ins) != 2 {
+		t.Fatalf("unexpected instances %v %v", ins, err)
+	}
+	if _, err = MultiDiscovery(failed, failed).GetService(context.Background(), "svc"); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestMultiDiscoveryWatch(t *testing.T) {
+	consul := &testDiscovery{updates: make(chan []*ServiceInstance)}
+	etcd := &testDiscovery{updates: make(chan []*ServiceInstance)}
+	w, err := MultiDiscovery(consul, etcd).Watch(context.Background(), "svc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	next := func() []string {
+		ins, err := w.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ids(ins)
+	}
+	etcd.updates <- []*ServiceInstance{{ID: "1", Endpoints: []string{"etcd"}}}
+	if got := next(); len(got) != 1 || got[0] != "1@etcd" {
+		t.Fatalf("unexpected instances %v", got)
+	}
+	consul.updates <- []*ServiceInstance{{ID: "1", Endpoints: []string{"consul"}}, {ID: "2", Endpoints: []string{"consul"}}}
+	if got := next(); len(got) != 2 || got[0] != "1@consul" || got[1] != "2@consul" {
+		t.Fatalf("unexpected instances %v", got)
+	}
+
+	if err := w.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Next(); err == nil {
+		t.Fatal("expected error after stop")
+	}
+}
@@ -0,0 +1,87 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+var _ Registrar = (*retryRegistrar)(nil)
+
+// RetryOption is retry registrar option.
+type RetryOption func(*retryRegistrar)
+
+// RetryBackoff with the wait before the attempt, the attempt starts from 1,
+// default is an exponential backoff from 100ms up to 10s.
+func RetryBackoff(backoff func(attempt int) time.Duration) RetryOption {
+	return func(r *retryRegistrar) {
+		r.backoff = backoff
+	}
+}
+
+// RetryLogger with the logger of the failed attempts.
+func RetryLogger(logger log.Logger) RetryOption {
+	return func(r *retryRegistrar) {
+		r.log = log.NewHelper(logger)
+	}
+}
+
+type retryRegistrar struct {
+	registrar Registrar
+	backoff   func(attempt int) time.Duration
+	log       *log.Helper
+}
+
+// RetryRegistrar returns a registrar which retries the registration and the deregistration
+// until they succeed or the context is done, e.g. while the registry is starting,
+// the retries of an app are bounded by its RegistrarTimeout.
+func RetryRegistrar(registrar Registrar, opts ...RetryOption) Registrar {
+	r := &retryRegistrar{
+		registrar: registrar,
+		backoff:   defaultBackoff,
+		log:       log.NewHelper(log.DefaultLogger),
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
+}
+
+func defaultBackoff(attempt int) time.Duration {
+	d := 100 * time.Millisecond
+	for i := 1; i < attempt && d < 10*time.Second; i++ {
+		d *= 2
+	}
+	if d > 10*time.Second {
+		d = 10 * time.Second
+	}
+	return d
+}
+
+func (r *retryRegistrar) Register(ctx context.Context, service *ServiceInstance) error {
+	return r.retry(ctx, "register", service, r.registrar.Register)
+}
+
+func (r *retryRegistrar) Deregister(ctx context.Context, service *ServiceInstance) error {
+	return r.retry(ctx, "deregister", service, r.registrar.Deregister)
+}
+
+func (r *retryRegistrar) retry(ctx context.Context, op string, service *ServiceInstance, fn func(context.Context, *ServiceInstance) error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx, service)
+		if err == nil {
+			return nil
+		}
+		wait := r.backoff(attempt)
+		r.log.Errorf("failed to %s service %s (attempt %d), retry in %v: %v", op, service.ID, attempt, wait, err)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%s service %s: %w: %v", op, service.ID, ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+type testRegistrar struct {
+	fails int
+	calls int
+}
+
+func (r *testRegistrar) Register(ctx context.Context, service *ServiceInstance) error {
+	r.calls++
+	if r.calls <= r.fails {
+		return errors.New("unavailable")
+	}
+	return nil
+}
+
+func (r *testRegistrar) Deregister(ctx context.Context, service *ServiceInstance) error {
+	return r.Register(ctx, service)
+}
+
+func TestRetryRegistrar(t *testing.T) {
+	backoff := RetryBackoff(func(int) time.Duration { return time.Millisecond })
+	logger := RetryLogger(log.NewStdLogger(ioutil.Discard))
+	service := &ServiceInstance{ID: "1"}
+
+	r := &testRegistrar{fails: 3}
+	if err := RetryRegistrar(r, backoff, logger).Register(context.Background(), service); err != nil {
+		t.Fatal(err)
+	}
+	if r.calls != 4 {
+		t.Fatalf("want 4 calls, got %d", r.calls)
+	}
+
+	r = &testRegistrar{fails: 1}
+	if err := RetryRegistrar(r, backoff, logger).Deregister(context.Background(), service); err != nil || r.calls != 2 {
+		t.Fatalf("unexpected deregister %v %d", err, r.calls)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := RetryRegistrar(&testRegistrar{fails: 1 << 30}, backoff, logger).Register(ctx, service)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want deadline exceeded, got %v", err)
+	}
+}
+
+func TestDefaultBackoff(t *testing.T) {
+	if d := defaultBackoff(1); d != 100*time.Millisecond {
+		t.Fatalf("unexpected backoff %v", d)
+	}
+	if d := defaultBackoff(3); d != 400*time.Millisecond {
+		t.Fatalf("unexpected backoff %v", d)
+	}
+	if d := defaultBackoff(100); d != 10*time.Second {
+		t.Fatalf("unexpected backoff %v", d)
+	}
+}
@@ -0,0 +1,184 @@
+package registry
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// DefaultHeartbeatKey is the default metadata key of the last heartbeat time.
+const DefaultHeartbeatKey = "heartbeat"
+
+var _ Discovery = (*ttlDiscovery)(nil)
+
+// TTLOption is TTL discovery option.
+type TTLOption func(*ttlDiscovery)
+
+// HeartbeatKey with the metadata key of the last heartbeat time, default is DefaultHeartbeatKey.
+// The time is either the unix seconds or an RFC 3339 time.
+func HeartbeatKey(key string) TTLOption {
+	return func(d *ttlDiscovery) {
+		d.key = key
+	}
+}
+
+type ttlDiscovery struct {
+	discovery Discovery
+	ttl       time.Duration
+	key       string
+	now       func() time.Time
+}
+
+// TTLDiscovery returns a discovery which filters out the instances whose last heartbeat is older than the ttl,
+// the instances without a heartbeat are kept. The watcher emits the instances again once any of them expires,
+// so the stale instances disappear without an update of the discovery.
+func TTLDiscovery(discovery Discovery, ttl time.Duration, opts ...TTLOption) Discovery {
+	d := &ttlDiscovery{
+		discovery: discovery,
+		ttl:       ttl,
+		key:       DefaultHeartbeatKey,
+		now:       time.Now,
+	}
+	for _, o := range opts {
+		o(d)
+	}
+	return d
+}
+
+func (d *ttlDiscovery) GetService(ctx context.Context, serviceName string) ([]*ServiceInstance, error) {
+	ins, err := d.discovery.GetService(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	alive, _ := d.filter(ins)
+	return alive, nil
+}
+
+func (d *ttlDiscovery) Watch(ctx context.Context, serviceName string) (Watcher, error) {
+	w, err := d.discovery.Watch(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	tw := &ttlWatcher{
+		ctx:       ctx,
+		cancel:    cancel,
+		watcher:   w,
+		discovery: d,
+		updates:   make(chan watchResult),
+	}
+	go tw.watch()
+	return tw, nil
+}
+
+// filter returns the alive instances and the earliest time one of them expires.
+func (d *ttlDiscovery) filter(ins []*ServiceInstance) ([]*ServiceInstance, time.Time) {
+	var (
+		now    = d.now()
+		expiry time.Time
+		alive  = make([]*ServiceInstance, 0, len(ins))
+	)
+	for _, in := range ins {
+		beat, ok := d.heartbeat(in)
+		if !ok {
+			alive = append(alive, in)
+			continue
+		}
+		at := beat.Add(d.ttl)
+		if !at.After(now) {
+			continue
+		}
+		alive = append(alive, in)
+		if expiry.IsZero() || at.Before(expiry) {
+			expiry = at
+		}
+	}
+	return alive, expiry
+}
+
+func (d *ttlDiscovery) heartbeat(in *ServiceInstance) (time.Time, bool) {
+	v, ok := in.Metadata[d.key]
+	if !ok {
+		return time.Time{}, false
+	}
+	if sec, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(sec, 0), true
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+type watchResult struct {
+	ins []*ServiceInstance
+	err error
+}
+
+type ttlWatcher struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	watcher   Watcher
+	discovery *ttlDiscovery
+	updates   chan watchResult
+	// last is the last instances of the discovery, and alive is the last emitted instances.
+	last  []*ServiceInstance
+	alive int
+}
+
+func (w *ttlWatcher) watch() {
+	for {
+		ins, err := w.watcher.Next()
+		select {
+		case w.updates <- watchResult{ins: ins, err: err}:
+		case <-w.ctx.Done():
+			return
+		}
+	}
+}
+
+// Next returns the alive instances once the discovery pushes an update or any alive instance expires.
+func (w *ttlWatcher) Next() ([]*ServiceInstance, error) {
+	for {
+		var (
+			timer   *time.Timer
+			expired <-chan time.Time
+		)
+		if _, expiry := w.discovery.filter(w.last); !expiry.IsZero() {
+			timer = time.NewTimer(expiry.Sub(w.discovery.now()))
+			expired = timer.C
+		}
+		var (
+			r       watchResult
+			updated bool
+		)
+		select {
+		case <-w.ctx.Done():
+			r.err = w.ctx.Err()
+		case r = <-w.updates:
+			updated = true
+		case <-expired:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if r.err != nil {
+			return nil, r.err
+		}
+		if updated {
+			w.last = r.ins
+		}
+		alive, _ := w.discovery.filter(w.last)
+		if !updated && len(alive) == w.alive {
+			// no instance expired since the last emit.
+			continue
+		}
+		w.alive = len(alive)
+		return alive, nil
+	}
+}
+
+func (w *ttlWatcher) Stop() error {
+	w.cancel()
+	return w.watcher.Stop()
+}
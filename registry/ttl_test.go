@@ -0,0 +1,60 @@
+package registry
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestTTLDiscovery(t *testing.T) {
+	now := time.Now()
+	d := &testDiscovery{instances: []*ServiceInstance{
+		{ID: "1", Endpoints: []string{"a"}, Metadata: map[string]string{"beat": strconv.FormatInt(now.Unix(), 10)}},
+		{ID: "2", Endpoints: []string{"a"}, Metadata: map[string]string{"beat": now.Add(-time.Minute).Format(time.RFC3339)}},
+		{ID: "3", Endpoints: []string{"a"}},
+	}}
+	ins, err := TTLDiscovery(d, 30*time.Second, HeartbeatKey("beat")).GetService(context.Background(), "svc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(ins); len(got) != 2 || got[0] != "1@a" || got[1] != "3@a" {
+		t.Fatalf("unexpected instances %v", got)
+	}
+}
+
+func TestTTLDiscoveryWatch(t *testing.T) {
+	d := &testDiscovery{updates: make(chan []*ServiceInstance)}
+	w, err := TTLDiscovery(d, 100*time.Millisecond).Watch(context.Background(), "svc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	beat := time.Now().Format(time.RFC3339Nano)
+	go func() {
+		d.updates <- []*ServiceInstance{
+			{ID: "1", Endpoints: []string{"a"}, Metadata: map[string]string{DefaultHeartbeatKey: beat}},
+			{ID: "2", Endpoints: []string{"a"}},
+		}
+	}()
+	ins, err := w.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ins) != 2 {
+		t.Fatalf("unexpected instances %v", ids(ins))
+	}
+
+	// the stale instance disappears without an update.
+	start := time.Now()
+	if ins, err = w.Next(); err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(ins); len(got) != 1 || got[0] != "2@a" {
+		t.Fatalf("unexpected instances %v", got)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatalf("the instance expired too late")
+	}
+}
@@ -0,0 +1,232 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNotAllowed is returned by Allow when the circuit is open.
+var ErrNotAllowed = errors.New("circuitbreaker: not allowed for circuit open")
+
+// Breaker is a circuit breaker.
+type Breaker interface {
+	// Allow returns ErrNotAllowed if the request should be rejected.
+	Allow() error
+	// MarkSuccess records a succeeded request.
+	MarkSuccess()
+	// MarkFailed records a failed request.
+	MarkFailed()
+}
+
+// Option is circuit breaker option.
+type Option func(*options)
+
+type options struct {
+	window   time.Duration
+	buckets  int
+	ratio    float64
+	request  int64
+	coolDown time.Duration
+	breaker  func() Breaker
+}
+
+// WithWindow with the stat window of each node, default is 10s.
+func WithWindow(d time.Duration) Option {
+	return func(o *options) {
+		o.window = d
+	}
+}
+
+// WithBuckets with the bucket count of the stat window, default is 10.
+func WithBuckets(n int) Option {
+	return func(o *options) {
+		o.buckets = n
+	}
+}
+
+// WithRatio with the failure ratio which trips the breaker, default is 0.5.
+func WithRatio(r float64) Option {
+	return func(o *options) {
+		o.ratio = r
+	}
+}
+
+// WithRequest with the min request count in the window before the breaker can trip, default is 20.
+func WithRequest(n int64) Option {
+	return func(o *options) {
+		o.request = n
+	}
+}
+
+// WithCoolDown with the duration the tripped breaker rejects requests, default is 5s.
+func WithCoolDown(d time.Duration) Option {
+	return func(o *options) {
+		o.coolDown = d
+	}
+}
+
+// WithBreaker with the factory of the node breakers, which overrides the ratio breaker options.
+func WithBreaker(f func() Breaker) Option {
+	return func(o *options) {
+		o.breaker = f
+	}
+}
+
+// idleTimeout is the duration after which an unused breaker is dropped.
+const idleTimeout = 10 * time.Minute
+
+type entry struct {
+	breaker Breaker
+	used    time.Time
+}
+
+// Group is a set of breakers keyed by the node address,
+// so that an unhealthy node is isolated while the others keep serving.
+// A Group can be shared by the HTTP and gRPC clients.
+type Group struct {
+	lock     sync.Mutex
+	new      func() Breaker
+	breakers map[string]*entry
+	sweepAt  int
+	now      func() time.Time
+}
+
+// NewGroup creates a breaker group.
+func NewGroup(opts ...Option) *Group {
+	o := options{
+		window:   10 * time.Second,
+		buckets:  10,
+		ratio:    0.5,
+		request:  20,
+		coolDown: 5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	g := &Group{
+		new:      o.breaker,
+		breakers: make(map[string]*entry),
+		sweepAt:  64,
+		now:      time.Now,
+	}
+	if g.new == nil {
+		g.new = func() Breaker { return newRatioBreaker(o) }
+	}
+	return g
+}
+
+// Get returns the breaker of the node address, it is created on first use,
+// and dropped once it has not been used for a while.
+func (g *Group) Get(addr string) Breaker {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	now := g.now()
+	e, ok := g.breakers[addr]
+	if !ok {
+		if len(g.breakers) >= g.sweepAt {
+			g.sweep(now)
+		}
+		e = &entry{breaker: g.new()}
+		g.breakers[addr] = e
+	}
+	e.used = now
+	return e.breaker
+}
+
+func (g *Group) sweep(now time.Time) {
+	for addr, e := range g.breakers {
+		if now.Sub(e.used) > idleTimeout {
+			delete(g.breakers, addr)
+		}
+	}
+	if g.sweepAt < 2*len(g.breakers) {
+		g.sweepAt = 2 * len(g.breakers)
+	}
+}
+
+type bucket struct {
+	total  int64
+	failed int64
+	start  time.Time
+}
+
+// ratioBreaker trips when the failure ratio in the window exceeds the ratio,
+// and rejects all requests until the cool down ends.
+type ratioBreaker struct {
+	lock      sync.Mutex
+	opts      options
+	size      time.Duration
+	buckets   []bucket
+	openUntil time.Time
+	now       func() time.Time
+}
+
+func newRatioBreaker(o options) *ratioBreaker {
+	if o.buckets <= 0 {
+		o.buckets = 1
+	}
+	if o.window < time.Duration(o.buckets) {
+		o.window = 10 * time.Second
+	}
+	return &ratioBreaker{
+		opts:    o,
+		size:    o.window / time.Duration(o.buckets),
+		buckets: make([]bucket, o.buckets),
+		now:     time.Now,
+	}
+}
+
+func (b *ratioBreaker) Allow() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.now().Before(b.openUntil) {
+		return ErrNotAllowed
+	}
+	return nil
+}
+
+func (b *ratioBreaker) MarkSuccess() {
+	b.mark(false)
+}
+
+func (b *ratioBreaker) MarkFailed() {
+	b.mark(true)
+}
+
+func (b *ratioBreaker) mark(failed bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	now := b.now()
+	cur := b.current(now)
+	cur.total++
+	if failed {
+		cur.failed++
+	}
+	if !failed || now.Before(b.openUntil) {
+		return
+	}
+	var total, fails int64
+	for _, bk := range b.buckets {
+		if now.Sub(bk.start) < b.opts.window {
+			total += bk.total
+			fails += bk.failed
+		}
+	}
+	if total >= b.opts.request && float64(fails)/float64(total) >= b.opts.ratio {
+		b.openUntil = now.Add(b.opts.coolDown)
+		// the stats are reset so that the node gets a fresh window after the cool down.
+		for i := range b.buckets {
+			b.buckets[i] = bucket{}
+		}
+	}
+}
+
+func (b *ratioBreaker) current(now time.Time) *bucket {
+	idx := int(now.UnixNano()/int64(b.size)) % len(b.buckets)
+	start := now.Truncate(b.size)
+	if !b.buckets[idx].start.Equal(start) {
+		b.buckets[idx] = bucket{start: start}
+	}
+	return &b.buckets[idx]
+}
@@ -0,0 +1,76 @@
+package circuitbreaker
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRatioBreaker(t *testing.T) {
+	now := time.Unix(1000, 0)
+	b := newRatioBreaker(options{window: 10 * time.Second, buckets: 10, ratio: 0.5, request: 4, coolDown: 5 * time.Second})
+	b.now = func() time.Time { return now }
+
+	// not enough requests to trip.
+	b.MarkFailed()
+	b.MarkFailed()
+	b.MarkSuccess()
+	assert.NoError(t, b.Allow())
+
+	b.MarkFailed()
+	assert.Equal(t, ErrNotAllowed, b.Allow())
+
+	now = now.Add(5 * time.Second)
+	assert.NoError(t, b.Allow())
+	// the window was reset on trip.
+	b.MarkFailed()
+	assert.NoError(t, b.Allow())
+}
+
+func TestRatioBreakerWindow(t *testing.T) {
+	now := time.Unix(1000, 0)
+	b := newRatioBreaker(options{window: 10 * time.Second, buckets: 10, ratio: 0.5, request: 2, coolDown: time.Second})
+	b.now = func() time.Time { return now }
+
+	b.MarkFailed()
+	// the first failure expires out of the window.
+	now = now.Add(11 * time.Second)
+	b.MarkSuccess()
+	b.MarkSuccess()
+	b.MarkFailed()
+	assert.NoError(t, b.Allow())
+}
+
+func TestGroup(t *testing.T) {
+	g := NewGroup(WithRequest(1), WithRatio(1))
+	g.Get("a").MarkFailed()
+	assert.Equal(t, ErrNotAllowed, g.Get("a").Allow())
+	assert.NoError(t, g.Get("b").Allow())
+	assert.Same(t, g.Get("a"), g.Get("a"))
+}
+
+func TestGroupSweep(t *testing.T) {
+	now := time.Unix(1000, 0)
+	g := NewGroup()
+	g.now = func() time.Time { return now }
+	for i := 0; i < 64; i++ {
+		g.Get(strconv.Itoa(i))
+	}
+	now = now.Add(idleTimeout + time.Second)
+	g.Get("0")
+	g.Get("new")
+	assert.Len(t, g.breakers, 2)
+}
+
+type mockBreaker struct{}
+
+func (mockBreaker) Allow() error { return ErrNotAllowed }
+func (mockBreaker) MarkSuccess() {}
+func (mockBreaker) MarkFailed()  {}
+
+func TestWithBreaker(t *testing.T) {
+	g := NewGroup(WithBreaker(func() Breaker { return mockBreaker{} }))
+	assert.Equal(t, ErrNotAllowed, g.Get("a").Allow())
+}
@@ -0,0 +1,190 @@
+package circuitbreaker
+
+import (
+	"sync"
+	"time"
+)
+
+// State is the state of a half-open breaker.
+type State int
+
+const (
+	// StateClosed allows all requests.
+	StateClosed State = iota
+	// StateOpen rejects all requests until the open timeout ends.
+	StateOpen
+	// StateHalfOpen allows a limited number of probe requests.
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+var _ Breaker = (*HalfOpenBreaker)(nil)
+
+// HalfOpenOption is half-open breaker option.
+type HalfOpenOption func(*HalfOpenBreaker)
+
+// HalfOpenFailures with the consecutive failures which trip the closed breaker, default is 5.
+func HalfOpenFailures(n int) HalfOpenOption {
+	return func(b *HalfOpenBreaker) {
+		b.failures = n
+	}
+}
+
+// HalfOpenTimeout with the duration the open breaker rejects requests before it is half-open, default is 5s.
+func HalfOpenTimeout(d time.Duration) HalfOpenOption {
+	return func(b *HalfOpenBreaker) {
+		b.timeout = d
+	}
+}
+
+// HalfOpenProbes with the number of the probe requests allowed by the half-open breaker, default is 1.
+// The breaker is closed once all probes succeed, and opened again once any probe fails.
+func HalfOpenProbes(n int) HalfOpenOption {
+	return func(b *HalfOpenBreaker) {
+		b.probes = n
+	}
+}
+
+// OnStateChange with the callback which is invoked when the state of the breaker changes, e.g. for logging and metrics.
+func OnStateChange(f func(from, to State)) HalfOpenOption {
+	return func(b *HalfOpenBreaker) {
+		b.onChange = f
+	}
+}
+
+// HalfOpenBreaker is a breaker with the explicit closed, open and half-open states,
+// it can be used by the groups with WithBreaker, e.g.
+// circuitbreaker.NewGroup(circuitbreaker.WithBreaker(func() circuitbreaker.Breaker {
+// return circuitbreaker.NewHalfOpenBreaker() })).
+type HalfOpenBreaker struct {
+	lock     sync.Mutex
+	failures int
+	timeout  time.Duration
+	probes   int
+	onChange func(from, to State)
+
+	state     State
+	failed    int
+	allowed   int
+	succeeded int
+	openedAt  time.Time
+	now       func() time.Time
+}
+
+// NewHalfOpenBreaker creates a half-open breaker.
+func NewHalfOpenBreaker(opts ...HalfOpenOption) *HalfOpenBreaker {
+	b := &HalfOpenBreaker{
+		failures: 5,
+		timeout:  5 * time.Second,
+		probes:   1,
+		now:      time.Now,
+	}
+	for _, o := range opts {
+		o(b)
+	}
+	if b.failures <= 0 {
+		b.failures = 1
+	}
+	if b.probes <= 0 {
+		b.probes = 1
+	}
+	return b
+}
+
+// State returns the current state of the breaker.
+func (b *HalfOpenBreaker) State() State {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.timeout {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+// Allow returns ErrNotAllowed if the breaker is open or the half-open breaker has no probe left.
+func (b *HalfOpenBreaker) Allow() error {
+	b.lock.Lock()
+	from := b.state
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.timeout {
+		b.setState(StateHalfOpen)
+	}
+	var err error
+	switch b.state {
+	case StateOpen:
+		err = ErrNotAllowed
+	case StateHalfOpen:
+		if b.allowed >= b.probes {
+			err = ErrNotAllowed
+		} else {
+			b.allowed++
+		}
+	}
+	to := b.state
+	b.lock.Unlock()
+	b.notify(from, to)
+	return err
+}
+
+// MarkSuccess records a succeeded request.
+func (b *HalfOpenBreaker) MarkSuccess() {
+	b.lock.Lock()
+	from := b.state
+	switch b.state {
+	case StateClosed:
+		b.failed = 0
+	case StateHalfOpen:
+		b.succeeded++
+		if b.succeeded >= b.probes {
+			b.setState(StateClosed)
+		}
+	}
+	to := b.state
+	b.lock.Unlock()
+	b.notify(from, to)
+}
+
+// MarkFailed records a failed request.
+func (b *HalfOpenBreaker) MarkFailed() {
+	b.lock.Lock()
+	from := b.state
+	switch b.state {
+	case StateClosed:
+		b.failed++
+		if b.failed >= b.failures {
+			b.setState(StateOpen)
+		}
+	case StateHalfOpen:
+		b.setState(StateOpen)
+	}
+	to := b.state
+	b.lock.Unlock()
+	b.notify(from, to)
+}
+
+// setState resets the counters of the new state, it must be called with the lock held.
+func (b *HalfOpenBreaker) setState(s State) {
+	b.state = s
+	b.failed = 0
+	b.allowed = 0
+	b.succeeded = 0
+	if s == StateOpen {
+		b.openedAt = b.now()
+	}
+}
+
+func (b *HalfOpenBreaker) notify(from, to State) {
+	if from != to && b.onChange != nil {
+		b.onChange(from, to)
+	}
+}
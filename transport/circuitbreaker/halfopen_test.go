@@ -0,0 +1,67 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHalfOpenBreaker(t *testing.T) {
+	now := time.Unix(1000, 0)
+	var changes []string
+	b := NewHalfOpenBreaker(
+		HalfOpenFailures(2),
+		HalfOpenTimeout(time.Second),
+		HalfOpenProbes(2),
+		OnStateChange(func(from, to State) {
+			changes = append(changes, from.String()+"->"+to.String())
+		}),
+	)
+	b.now = func() time.Time { return now }
+
+	b.MarkFailed()
+	b.MarkSuccess()
+	b.MarkFailed()
+	assert.Equal(t, StateClosed, b.State())
+	b.MarkFailed()
+	assert.Equal(t, StateOpen, b.State())
+	assert.Equal(t, ErrNotAllowed, b.Allow())
+
+	// the probes are limited when half-open.
+	now = now.Add(time.Second)
+	assert.Equal(t, StateHalfOpen, b.State())
+	assert.NoError(t, b.Allow())
+	assert.NoError(t, b.Allow())
+	assert.Equal(t, ErrNotAllowed, b.Allow())
+
+	// a failed probe opens the breaker again.
+	b.MarkSuccess()
+	b.MarkFailed()
+	assert.Equal(t, ErrNotAllowed, b.Allow())
+
+	now = now.Add(time.Second)
+	assert.NoError(t, b.Allow())
+	assert.NoError(t, b.Allow())
+	b.MarkSuccess()
+	b.MarkSuccess()
+	assert.Equal(t, StateClosed, b.State())
+	assert.NoError(t, b.Allow())
+
+	assert.Equal(t, []string{
+		"closed->open",
+		"open->half-open",
+		"half-open->open",
+		"open->half-open",
+		"half-open->closed",
+	}, changes)
+}
+
+func TestHalfOpenBreakerGroup(t *testing.T) {
+	g := NewGroup(WithBreaker(func() Breaker {
+		return NewHalfOpenBreaker(HalfOpenFailures(1))
+	}))
+	g.Get("a").MarkFailed()
+	assert.Equal(t, ErrNotAllowed, g.Get("a").Allow())
+	assert.NoError(t, g.Get("b").Allow())
+}
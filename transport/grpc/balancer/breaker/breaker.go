@@ -0,0 +1,87 @@
+package breaker
+
+import (
+	"sync/atomic"
+
+	"github.com/go-kratos/kratos/v2/transport/circuitbreaker"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Name is the name of breaker balancer.
+const Name = "breaker"
+
+func init() {
+	Register(circuitbreaker.NewGroup())
+}
+
+// Register registers the breaker balancer builder with the breaker group, it replaces the registered one,
+// so it must be called before dialing. The group can be shared with the HTTP clients.
+func Register(group *circuitbreaker.Group) {
+	balancer.Register(NewBuilder(group))
+}
+
+// NewBuilder creates a round-robin balancer builder which skips the addresses
+// whose breaker in the group is open, the result of each rpc is attributed to its address.
+func NewBuilder(group *circuitbreaker.Group) balancer.Builder {
+	return base.NewBalancerBuilder(Name, &pickerBuilder{group: group}, base.Config{HealthCheck: true})
+}
+
+type pickerBuilder struct {
+	group *circuitbreaker.Group
+}
+
+func (b *pickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+	p := &picker{group: b.group}
+	for sc, sci := range info.ReadySCs {
+		p.subConns = append(p.subConns, sc)
+		p.addrs = append(p.addrs, sci.Address.Addr)
+	}
+	return p
+}
+
+type picker struct {
+	group    *circuitbreaker.Group
+	subConns []balancer.SubConn
+	addrs    []string
+	next     uint32
+}
+
+func (p *picker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	start := atomic.AddUint32(&p.next, 1)
+	for i := 0; i < len(p.subConns); i++ {
+		idx := (int(start%uint32(len(p.subConns))) + i) % len(p.subConns)
+		cb := p.group.Get(p.addrs[idx])
+		if cb.Allow() != nil {
+			continue
+		}
+		return balancer.PickResult{
+			SubConn: p.subConns[idx],
+			Done: func(di balancer.DoneInfo) {
+				if isFailure(di.Err) {
+					cb.MarkFailed()
+				} else {
+					cb.MarkSuccess()
+				}
+			},
+		}, nil
+	}
+	return balancer.PickResult{}, status.Error(codes.Unavailable, circuitbreaker.ErrNotAllowed.Error())
+}
+
+// isFailure reports whether the rpc error counts as an address failure.
+func isFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	switch status.Code(err) {
+	case codes.Unknown, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Internal, codes.Unavailable, codes.DataLoss:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package breaker
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/transport/circuitbreaker"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/status"
+)
+
+type mockSubConn struct {
+	balancer.SubConn
+	id int
+}
+
+func TestPicker(t *testing.T) {
+	info := base.PickerBuildInfo{ReadySCs: map[balancer.SubConn]base.SubConnInfo{}}
+	for i := 0; i < 2; i++ {
+		info.ReadySCs[&mockSubConn{id: i}] = base.SubConnInfo{Address: resolver.Address{Addr: "127.0.0.1:900" + strconv.Itoa(i)}}
+	}
+	g := circuitbreaker.NewGroup(circuitbreaker.WithRequest(1), circuitbreaker.WithRatio(0.5))
+	p := (&pickerBuilder{group: g}).Build(info)
+
+	first, err := p.Pick(balancer.PickInfo{Ctx: context.Background()})
+	assert.NoError(t, err)
+	// the client errors don't trip the breaker.
+	first.Done(balancer.DoneInfo{Err: status.Error(codes.InvalidArgument, "")})
+	first.Done(balancer.DoneInfo{Err: status.Error(codes.Unavailable, "")})
+	for i := 0; i < 4; i++ {
+		res, err := p.Pick(balancer.PickInfo{Ctx: context.Background()})
+		assert.NoError(t, err)
+		assert.NotEqual(t, first.SubConn, res.SubConn)
+		res.Done(balancer.DoneInfo{})
+	}
+
+	_, err = (&pickerBuilder{group: g}).Build(base.PickerBuildInfo{}).Pick(balancer.PickInfo{Ctx: context.Background()})
+	assert.Equal(t, balancer.ErrNoSubConnAvailable, err)
+}
+
+func TestPickerAllOpen(t *testing.T) {
+	info := base.PickerBuildInfo{ReadySCs: map[balancer.SubConn]base.SubConnInfo{
+		&mockSubConn{}: {Address: resolver.Address{Addr: "127.0.0.1:9000"}},
+	}}
+	g := circuitbreaker.NewGroup(circuitbreaker.WithRequest(1), circuitbreaker.WithRatio(0.5))
+	g.Get("127.0.0.1:9000").MarkFailed()
+	_, err := (&pickerBuilder{group: g}).Build(info).Pick(balancer.PickInfo{Ctx: context.Background()})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestPickerWrap(t *testing.T) {
+	p := &picker{
+		group:    circuitbreaker.NewGroup(),
+		subConns: []balancer.SubConn{&mockSubConn{id: 0}, &mockSubConn{id: 1}, &mockSubConn{id: 2}},
+		addrs:    []string{"127.0.0.1:9000", "127.0.0.1:9001", "127.0.0.1:9002"},
+		next:     math.MaxUint32 - 1,
+	}
+	// the counter wraps around, and the index stays in range on the 32-bit platforms.
+	for i := 0; i < 4; i++ {
+		_, err := p.Pick(balancer.PickInfo{Ctx: context.Background()})
+		assert.NoError(t, err)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register(circuitbreaker.NewGroup())
+	assert.NotNil(t, balancer.Get(Name))
+}
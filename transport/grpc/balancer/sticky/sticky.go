@@ -0,0 +1,99 @@
+package sticky
+
+import (
+	"context"
+	"math/rand"
+
+	"github.com/go-kratos/kratos/v2/internal/rendezvous"
+	"github.com/go-kratos/kratos/v2/transport"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/metadata"
+)
+
+// Name is the name of sticky balancer.
+const Name = "sticky"
+
+// KeyFunc extracts the sticky key from the rpc context.
+type KeyFunc func(ctx context.Context) string
+
+// HeaderKey returns a KeyFunc which extracts the key from the request header,
+// or from the outgoing metadata if the context carries no transport.
+func HeaderKey(name string) KeyFunc {
+	return func(ctx context.Context) string {
+		if tr, ok := transport.FromClientContext(ctx); ok {
+			if v := tr.RequestHeader().Get(name); v != "" {
+				return v
+			}
+		}
+		if md, ok := metadata.FromOutgoingContext(ctx); ok {
+			if vs := md.Get(name); len(vs) > 0 {
+				return vs[0]
+			}
+		}
+		return ""
+	}
+}
+
+// Option is sticky balancer option.
+type Option func(*pickerBuilder)
+
+// WithKey with the sticky key extractor.
+func WithKey(key KeyFunc) Option {
+	return func(b *pickerBuilder) {
+		b.key = key
+	}
+}
+
+func init() {
+	Register()
+}
+
+// Register registers the sticky balancer builder, it replaces the registered one,
+// so it must be called before dialing, e.g. sticky.Register(sticky.WithKey(sticky.HeaderKey("x-user-id"))).
+func Register(opts ...Option) {
+	balancer.Register(NewBuilder(opts...))
+}
+
+// NewBuilder creates a sticky balancer builder which picks the same address
+// for the same key by rendezvous hashing, the rpcs without key are balanced randomly.
+func NewBuilder(opts ...Option) balancer.Builder {
+	b := &pickerBuilder{}
+	for _, o := range opts {
+		o(b)
+	}
+	return base.NewBalancerBuilder(Name, b, base.Config{HealthCheck: true})
+}
+
+type pickerBuilder struct {
+	key KeyFunc
+}
+
+func (b *pickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+	p := &picker{key: b.key}
+	for sc, sci := range info.ReadySCs {
+		p.subConns = append(p.subConns, sc)
+		p.addrs = append(p.addrs, sci.Address.Addr)
+	}
+	return p
+}
+
+type picker struct {
+	key      KeyFunc
+	subConns []balancer.SubConn
+	addrs    []string
+}
+
+func (p *picker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	var key string
+	if p.key != nil {
+		key = p.key(info.Ctx)
+	}
+	if key == "" {
+		return balancer.PickResult{SubConn: p.subConns[rand.Intn(len(p.subConns))]}, nil
+	}
+	return balancer.PickResult{SubConn: p.subConns[rendezvous.Pick(key, p.addrs)]}, nil
+}
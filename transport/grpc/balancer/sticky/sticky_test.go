@@ -0,0 +1,46 @@
+package sticky
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/resolver"
+)
+
+type mockSubConn struct {
+	balancer.SubConn
+	id int
+}
+
+func TestPicker(t *testing.T) {
+	info := base.PickerBuildInfo{ReadySCs: map[balancer.SubConn]base.SubConnInfo{}}
+	for i := 0; i < 5; i++ {
+		info.ReadySCs[&mockSubConn{id: i}] = base.SubConnInfo{Address: resolver.Address{Addr: "127.0.0.1:900" + strconv.Itoa(i)}}
+	}
+	b := &pickerBuilder{key: HeaderKey("x-user-id")}
+	p := b.Build(info)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-user-id", "user-1")
+	first, err := p.Pick(balancer.PickInfo{Ctx: ctx})
+	assert.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		res, err := p.Pick(balancer.PickInfo{Ctx: ctx})
+		assert.NoError(t, err)
+		assert.Equal(t, first.SubConn, res.SubConn)
+	}
+	_, err = p.Pick(balancer.PickInfo{Ctx: context.Background()})
+	assert.NoError(t, err)
+
+	_, err = b.Build(base.PickerBuildInfo{}).Pick(balancer.PickInfo{Ctx: ctx})
+	assert.Equal(t, balancer.ErrNoSubConnAvailable, err)
+}
+
+func TestRegister(t *testing.T) {
+	Register(WithKey(HeaderKey("x-user-id")))
+	assert.NotNil(t, balancer.Get(Name))
+}
@@ -3,9 +3,12 @@ package grpc
 import (
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/registry"
 	"github.com/go-kratos/kratos/v2/transport"
@@ -17,9 +20,14 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/balancer/roundrobin"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
 	grpcmd "google.golang.org/grpc/metadata"
 )
 
+// RequestInterceptor inspects or modifies an outbound call just before it is sent, e.g. to sign it,
+// md is the outgoing metadata of the call which may be modified.
+type RequestInterceptor func(ctx context.Context, method string, req interface{}, md grpcmd.MD) error
+
 // ClientOption is gRPC client option.
 type ClientOption func(o *clientOptions)
 
@@ -65,6 +73,14 @@ func WithUnaryInterceptor(in ...grpc.UnaryClientInterceptor) ClientOption {
 	}
 }
 
+// WithRequestInterceptor with the interceptors of the outbound calls, which are invoked in order after the middleware,
+// with the outgoing metadata carrying the request header. The call is aborted if an interceptor returns an error.
+func WithRequestInterceptor(in ...RequestInterceptor) ClientOption {
+	return func(o *clientOptions) {
+		o.interceptors = append(o.interceptors, in...)
+	}
+}
+
 // WithOptions with gRPC options.
 func WithOptions(opts ...grpc.DialOption) ClientOption {
 	return func(o *clientOptions) {
@@ -72,15 +88,68 @@ func WithOptions(opts ...grpc.DialOption) ClientOption {
 	}
 }
 
+// WithBalancerName with the name of the registered gRPC load balancing policy, e.g. sticky.Name.
+func WithBalancerName(name string) ClientOption {
+	return func(o *clientOptions) {
+		o.balancerName = name
+	}
+}
+
+// WithServiceConfig with the default gRPC service config in JSON, e.g. the method-level retryPolicy and timeout,
+// see https://github.com/grpc/grpc/blob/master/doc/service_config.md. The loadBalancingPolicy defaults to
+// the WithBalancerName, and the config is validated by Dial. Note that the retryPolicy is only applied
+// if GRPC_GO_RETRY=on is set for this gRPC version, and the hedgingPolicy is not supported by grpc-go.
+func WithServiceConfig(config string) ClientOption {
+	return func(o *clientOptions) {
+		o.serviceConfig = config
+	}
+}
+
+// WithMaxRecvMsgSize with the max message size in bytes the client can receive.
+func WithMaxRecvMsgSize(size int) ClientOption {
+	return func(o *clientOptions) {
+		o.maxRecvMsgSize = size
+	}
+}
+
+// WithMaxSendMsgSize with the max message size in bytes the client can send.
+func WithMaxSendMsgSize(size int) ClientOption {
+	return func(o *clientOptions) {
+		o.maxSendMsgSize = size
+	}
+}
+
+// WithKeepaliveParams with the keepalive pings of the connection, a ping is sent after the interval without any activity,
+// and the connection is closed if the ping is not acknowledged within timeout. The pings are also sent without
+// any active stream if permitWithoutStream is true. The server must permit the pings by KeepaliveEnforcementPolicy.
+func WithKeepaliveParams(interval, timeout time.Duration, permitWithoutStream bool) ClientOption {
+	return func(o *clientOptions) {
+		o.keepalive = &keepalive.ClientParameters{
+			Time:                interval,
+			Timeout:             timeout,
+			PermitWithoutStream: permitWithoutStream,
+		}
+	}
+}
+
 // clientOptions is gRPC Client
 type clientOptions struct {
-	endpoint   string
-	tlsConf    *tls.Config
-	timeout    time.Duration
-	discovery  registry.Discovery
-	middleware []middleware.Middleware
-	ints       []grpc.UnaryClientInterceptor
-	grpcOpts   []grpc.DialOption
+	endpoint     string
+	balancerName string
+	tlsConf      *tls.Config
+	timeout      time.Duration
+	discovery    registry.Discovery
+	middleware   []middleware.Middleware
+	ints         []grpc.UnaryClientInterceptor
+	grpcOpts     []grpc.DialOption
+	// maxRecvMsgSize and maxSendMsgSize are the message size limits, zero is the gRPC default.
+	maxRecvMsgSize int
+	maxSendMsgSize int
+	// keepalive is nil unless the keepalive pings are enabled.
+	keepalive    *keepalive.ClientParameters
+	interceptors []RequestInterceptor
+	// serviceConfig is the JSON service config, empty if it is not configured.
+	serviceConfig string
 }
 
 // Dial returns a GRPC connection.
@@ -95,21 +164,39 @@ func DialInsecure(ctx context.Context, opts ...ClientOption) (*grpc.ClientConn,
 
 func dial(ctx context.Context, insecure bool, opts ...ClientOption) (*grpc.ClientConn, error) {
 	options := clientOptions{
-		timeout: 2000 * time.Millisecond,
+		timeout:      2000 * time.Millisecond,
+		balancerName: roundrobin.Name,
 	}
 	for _, o := range opts {
 		o(&options)
 	}
 	var ints = []grpc.UnaryClientInterceptor{
-		unaryClientInterceptor(options.middleware, options.timeout),
+		unaryClientInterceptor(options.middleware, options.timeout, options.interceptors...),
 	}
 	if len(options.ints) > 0 {
 		ints = append(ints, options.ints...)
 	}
+	sc, err := serviceConfig(options.balancerName, options.serviceConfig)
+	if err != nil {
+		return nil, err
+	}
 	var grpcOpts = []grpc.DialOption{
-		grpc.WithDefaultServiceConfig(fmt.Sprintf(`{"LoadBalancingPolicy": "%s"}`, roundrobin.Name)),
+		grpc.WithDefaultServiceConfig(sc),
 		grpc.WithChainUnaryInterceptor(ints...),
 	}
+	var callOpts []grpc.CallOption
+	if options.maxRecvMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallRecvMsgSize(options.maxRecvMsgSize))
+	}
+	if options.maxSendMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallSendMsgSize(options.maxSendMsgSize))
+	}
+	if len(callOpts) > 0 {
+		grpcOpts = append(grpcOpts, grpc.WithDefaultCallOptions(callOpts...))
+	}
+	if options.keepalive != nil {
+		grpcOpts = append(grpcOpts, grpc.WithKeepaliveParams(*options.keepalive))
+	}
 	if options.discovery != nil {
 		grpcOpts = append(grpcOpts, grpc.WithResolvers(discovery.NewBuilder(options.discovery, discovery.WithInsecure(insecure))))
 	}
@@ -125,7 +212,33 @@ func dial(ctx context.Context, insecure bool, opts ...ClientOption) (*grpc.Clien
 	return grpc.DialContext(ctx, options.endpoint, grpcOpts...)
 }
 
-func unaryClientInterceptor(ms []middleware.Middleware, timeout time.Duration) grpc.UnaryClientInterceptor {
+// serviceConfig returns the service config of the load balancing policy, which is merged into the JSON config if any.
+func serviceConfig(balancerName, config string) (string, error) {
+	if config == "" {
+		return fmt.Sprintf(`{"LoadBalancingPolicy": "%s"}`, balancerName), nil
+	}
+	var sc map[string]interface{}
+	if err := json.Unmarshal([]byte(config), &sc); err != nil {
+		return "", fmt.Errorf("grpc: invalid service config: %w", err)
+	}
+	if sc == nil {
+		return "", fmt.Errorf("grpc: invalid service config: %s is not an object", config)
+	}
+	// the keys are matched case-insensitively as the gRPC parser does.
+	for k := range sc {
+		if strings.EqualFold(k, "loadBalancingPolicy") || strings.EqualFold(k, "loadBalancingConfig") {
+			return config, nil
+		}
+	}
+	sc["loadBalancingPolicy"] = balancerName
+	data, err := json.Marshal(sc)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func unaryClientInterceptor(ms []middleware.Middleware, timeout time.Duration, interceptors ...RequestInterceptor) grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
 		ctx = transport.NewClientContext(ctx, &Transport{
 			endpoint:  cc.Target(),
@@ -147,7 +260,18 @@ func unaryClientInterceptor(ms []middleware.Middleware, timeout time.Duration) g
 				}
 				ctx = grpcmd.AppendToOutgoingContext(ctx, keyvals...)
 			}
-			return reply, invoker(ctx, method, req, reply, cc, opts...)
+			if len(interceptors) > 0 {
+				md, _ := grpcmd.FromOutgoingContext(ctx)
+				md = md.Copy()
+				for _, in := range interceptors {
+					if err := in(ctx, method, req, md); err != nil {
+						return nil, err
+					}
+				}
+				ctx = grpcmd.NewOutgoingContext(ctx, md)
+			}
+			// the cause chain of the server errors is reconstructed if it is in the status details.
+			return reply, errors.FromStatusWithCause(invoker(ctx, method, req, reply, cc, opts...))
 		}
 		if len(ms) > 0 {
 			h = middleware.Chain(ms...)(h)
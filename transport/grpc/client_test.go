@@ -3,13 +3,17 @@ package grpc
 import (
 	"context"
 	"crypto/tls"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/transport"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+	grpcmd "google.golang.org/grpc/metadata"
 )
 
 func TestWithEndpoint(t *testing.T) {
@@ -77,6 +81,39 @@ func TestUnaryClientInterceptor(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestWithRequestInterceptor(t *testing.T) {
+	o := &clientOptions{}
+	sign := func(ctx context.Context, method string, req interface{}, md grpcmd.MD) error {
+		md.Set("x-signature", method+":"+md.Get("x-md-tenant")[0])
+		return nil
+	}
+	WithRequestInterceptor(sign)(o)
+	assert.Equal(t, 1, len(o.interceptors))
+
+	f := unaryClientInterceptor([]middleware.Middleware{func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if tr, ok := transport.FromClientContext(ctx); ok {
+				tr.RequestHeader().Set("x-md-tenant", "kratos")
+			}
+			return handler(ctx, req)
+		}
+	}}, 0, o.interceptors...)
+	err := f(context.TODO(), "/hello", nil, nil, &grpc.ClientConn{}, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ := grpcmd.FromOutgoingContext(ctx)
+		assert.Equal(t, []string{"/hello:kratos"}, md.Get("x-signature"))
+		return nil
+	})
+	assert.NoError(t, err)
+
+	errSign := fmt.Errorf("no credentials")
+	f = unaryClientInterceptor(nil, 0, func(context.Context, string, interface{}, grpcmd.MD) error { return errSign })
+	err = f(context.TODO(), "/hello", nil, nil, &grpc.ClientConn{}, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		t.Fatal("the call must be aborted")
+		return nil
+	})
+	assert.Equal(t, errSign, err)
+}
+
 func TestWithUnaryInterceptor(t *testing.T) {
 	o := &clientOptions{}
 	v := []grpc.UnaryClientInterceptor{
@@ -102,6 +139,21 @@ func TestWithOptions(t *testing.T) {
 	assert.Equal(t, v, o.grpcOpts)
 }
 
+func TestWithBalancerName(t *testing.T) {
+	o := &clientOptions{}
+	v := "sticky"
+	WithBalancerName(v)(o)
+	assert.Equal(t, v, o.balancerName)
+}
+
+func TestWithMaxMsgSize(t *testing.T) {
+	o := &clientOptions{}
+	WithMaxRecvMsgSize(8 << 20)(o)
+	WithMaxSendMsgSize(16 << 20)(o)
+	assert.Equal(t, 8<<20, o.maxRecvMsgSize)
+	assert.Equal(t, 16<<20, o.maxSendMsgSize)
+}
+
 func TestDial(t *testing.T) {
 	o := &clientOptions{}
 	v := []grpc.DialOption{
@@ -110,3 +162,35 @@ func TestDial(t *testing.T) {
 	WithOptions(v...)(o)
 	assert.Equal(t, v, o.grpcOpts)
 }
+
+func TestWithKeepaliveParams(t *testing.T) {
+	o := &clientOptions{}
+	WithKeepaliveParams(time.Minute, 10*time.Second, true)(o)
+	assert.Equal(t, &keepalive.ClientParameters{Time: time.Minute, Timeout: 10 * time.Second, PermitWithoutStream: true}, o.keepalive)
+}
+
+func TestWithServiceConfig(t *testing.T) {
+	const retry = `{"methodConfig": [{"name": [{"service": "helloworld.Greeter"}], "timeout": "1s",
+		"retryPolicy": {"maxAttempts": 3, "initialBackoff": "0.1s", "maxBackoff": "1s",
+		"backoffMultiplier": 2, "retryableStatusCodes": ["UNAVAILABLE"]}}]}`
+	o := &clientOptions{}
+	WithServiceConfig(retry)(o)
+	assert.Equal(t, retry, o.serviceConfig)
+
+	sc, err := serviceConfig("round_robin", retry)
+	assert.NoError(t, err)
+	assert.Contains(t, sc, `"loadBalancingPolicy":"round_robin"`)
+	assert.Contains(t, sc, `"retryPolicy"`)
+	sc, err = serviceConfig("round_robin", `{"LoadBalancingPolicy": "pick_first"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"LoadBalancingPolicy": "pick_first"}`, sc)
+
+	conn, err := DialInsecure(context.Background(), WithEndpoint("127.0.0.1:0"), WithServiceConfig(retry))
+	assert.NoError(t, err)
+	conn.Close()
+
+	for _, config := range []string{`{"methodConfig": [`, `null`, `{"methodConfig": [{"timeout": 1}]}`} {
+		_, err = DialInsecure(context.Background(), WithEndpoint("127.0.0.1:0"), WithServiceConfig(config))
+		assert.Error(t, err, config)
+	}
+}
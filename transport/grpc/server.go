@@ -5,12 +5,14 @@ import (
 	"crypto/tls"
 	"net"
 	"net/url"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-kratos/kratos/v2/internal/endpoint"
 
 	apimd "github.com/go-kratos/kratos/v2/api/metadata"
+	"github.com/go-kratos/kratos/v2/errors"
 	ic "github.com/go-kratos/kratos/v2/internal/context"
 	"github.com/go-kratos/kratos/v2/internal/host"
 	"github.com/go-kratos/kratos/v2/log"
@@ -21,6 +23,7 @@ import (
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
 	grpcmd "google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/reflection"
 )
@@ -80,6 +83,13 @@ func UnaryInterceptor(in ...grpc.UnaryServerInterceptor) ServerOption {
 	}
 }
 
+// StreamInterceptor returns a ServerOption that sets the StreamServerInterceptor for the server.
+func StreamInterceptor(in ...grpc.StreamServerInterceptor) ServerOption {
+	return func(s *Server) {
+		s.streamInts = in
+	}
+}
+
 // Options with grpc options.
 func Options(opts ...grpc.ServerOption) ServerOption {
 	return func(s *Server) {
@@ -87,6 +97,69 @@ func Options(opts ...grpc.ServerOption) ServerOption {
 	}
 }
 
+// MethodTimeout with per-method timeouts keyed by full method name, e.g. "/pkg.Service/Method",
+// or "/pkg.Service/*" for all methods of a service. An exact entry takes precedence over
+// a wildcard entry, and the server timeout is used if no entry matches.
+func MethodTimeout(timeouts map[string]time.Duration) ServerOption {
+	return func(s *Server) {
+		s.methodTimeouts = timeouts
+	}
+}
+
+// MaxRecvMsgSize with the max message size in bytes the server can receive.
+func MaxRecvMsgSize(size int) ServerOption {
+	return func(s *Server) {
+		s.maxRecvMsgSize = size
+	}
+}
+
+// MaxSendMsgSize with the max message size in bytes the server can send.
+func MaxSendMsgSize(size int) ServerOption {
+	return func(s *Server) {
+		s.maxSendMsgSize = size
+	}
+}
+
+// KeepaliveParams with the keepalive pings of the server, a ping is sent after the interval without any activity,
+// and the connection is closed if the ping is not acknowledged within timeout.
+func KeepaliveParams(interval, timeout time.Duration) ServerOption {
+	return func(s *Server) {
+		s.keepalive = &keepalive.ServerParameters{Time: interval, Timeout: timeout}
+	}
+}
+
+// KeepaliveEnforcementPolicy with the min interval of the client pings, and whether the pings without any
+// active stream are permitted. The connection of a client pinging more often is closed, the gRPC default
+// is 5 minutes without any stream, which rejects the clients of the shorter WithKeepaliveParams.
+func KeepaliveEnforcementPolicy(minTime time.Duration, permitWithoutStream bool) ServerOption {
+	return func(s *Server) {
+		s.enforcement = &keepalive.EnforcementPolicy{MinTime: minTime, PermitWithoutStream: permitWithoutStream}
+	}
+}
+
+// ErrorCause with the cause chain of the returned errors in the status details,
+// the causes may contain internal details, so it should only be enabled within a trust boundary.
+func ErrorCause(enable bool) ServerOption {
+	return func(s *Server) {
+		s.errorCause = enable
+	}
+}
+
+// Reflection with whether the server reflection service is registered, it is enabled by default.
+func Reflection(enable bool) ServerOption {
+	return func(s *Server) {
+		s.reflection = enable
+	}
+}
+
+// HealthCheck with whether the grpc_health_v1 health service is registered, it is enabled by default.
+// The server reports NOT_SERVING until it is started, SERVING while it is running, and NOT_SERVING after it is stopped.
+func HealthCheck(enable bool) ServerOption {
+	return func(s *Server) {
+		s.healthCheck = enable
+	}
+}
+
 // Server is a gRPC server wrapper.
 type Server struct {
 	*grpc.Server
@@ -102,31 +175,67 @@ type Server struct {
 	log        *log.Helper
 	middleware []middleware.Middleware
 	ints       []grpc.UnaryServerInterceptor
+	streamInts []grpc.StreamServerInterceptor
 	grpcOpts   []grpc.ServerOption
 	health     *health.Server
 	metadata   *apimd.Server
+	// maxRecvMsgSize and maxSendMsgSize are the message size limits, zero is the gRPC default.
+	maxRecvMsgSize int
+	maxSendMsgSize int
+	methodTimeouts map[string]time.Duration
+	errorCause     bool
+	reflection     bool
+	healthCheck    bool
+	streamObserver func(ctx context.Context, m StreamMessage)
+	// keepalive and enforcement are nil unless they are configured.
+	keepalive   *keepalive.ServerParameters
+	enforcement *keepalive.EnforcementPolicy
 }
 
 // NewServer creates a gRPC server by options.
 func NewServer(opts ...ServerOption) *Server {
 	srv := &Server{
-		network: "tcp",
-		address: ":0",
-		timeout: 1 * time.Second,
-		health:  health.NewServer(),
-		log:     log.NewHelper(log.DefaultLogger),
+		network:     "tcp",
+		address:     ":0",
+		timeout:     1 * time.Second,
+		log:         log.NewHelper(log.DefaultLogger),
+		reflection:  true,
+		healthCheck: true,
 	}
 	for _, o := range opts {
 		o(srv)
 	}
+	if srv.healthCheck {
+		srv.health = health.NewServer()
+		srv.health.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	}
 	var ints = []grpc.UnaryServerInterceptor{
 		srv.unaryServerInterceptor(),
 	}
 	if len(srv.ints) > 0 {
 		ints = append(ints, srv.ints...)
 	}
+	var streamInts = []grpc.StreamServerInterceptor{
+		srv.streamServerInterceptor(),
+	}
+	if len(srv.streamInts) > 0 {
+		streamInts = append(streamInts, srv.streamInts...)
+	}
 	var grpcOpts = []grpc.ServerOption{
 		grpc.ChainUnaryInterceptor(ints...),
+		grpc.ChainStreamInterceptor(streamInts...),
+	}
+	if srv.maxRecvMsgSize > 0 {
+		grpcOpts = append(grpcOpts, grpc.MaxRecvMsgSize(srv.maxRecvMsgSize))
+	}
+	if srv.maxSendMsgSize > 0 {
+		grpcOpts = append(grpcOpts, grpc.MaxSendMsgSize(srv.maxSendMsgSize))
+	}
+	if srv.keepalive != nil {
+		grpcOpts = append(grpcOpts, grpc.KeepaliveParams(*srv.keepalive))
+	}
+	if srv.enforcement != nil {
+		grpcOpts = append(grpcOpts, grpc.KeepaliveEnforcementPolicy(*srv.enforcement))
 	}
 	if srv.tlsConf != nil {
 		grpcOpts = append(grpcOpts, grpc.Creds(credentials.NewTLS(srv.tlsConf)))
@@ -137,12 +246,24 @@ func NewServer(opts ...ServerOption) *Server {
 	srv.Server = grpc.NewServer(grpcOpts...)
 	srv.metadata = apimd.NewServer(srv.Server)
 	// internal register
-	grpc_health_v1.RegisterHealthServer(srv.Server, srv.health)
+	if srv.health != nil {
+		grpc_health_v1.RegisterHealthServer(srv.Server, srv.health)
+	}
 	apimd.RegisterMetadataServer(srv.Server, srv.metadata)
-	reflection.Register(srv.Server)
+	if srv.reflection {
+		reflection.Register(srv.Server)
+	}
 	return srv
 }
 
+// SetServingStatus sets the health status of the service, the empty service is the status of the whole server.
+// The status of all services is reset to SERVING when the server starts, and it is a no-op if the health check is disabled.
+func (s *Server) SetServingStatus(service string, status grpc_health_v1.HealthCheckResponse_ServingStatus) {
+	if s.health != nil {
+		s.health.SetServingStatus(service, status)
+	}
+}
+
 // Endpoint return a real address to registry endpoint.
 // examples:
 //   grpc://127.0.0.1:9000?isSecure=false
@@ -175,18 +296,35 @@ func (s *Server) Start(ctx context.Context) error {
 	}
 	s.ctx = ctx
 	s.log.Infof("[gRPC] server listening on: %s", s.lis.Addr().String())
-	s.health.Resume()
+	if s.health != nil {
+		s.health.Resume()
+	}
 	return s.Serve(s.lis)
 }
 
 // Stop stop the gRPC server.
 func (s *Server) Stop(ctx context.Context) error {
+	if s.health != nil {
+		s.health.Shutdown()
+	}
 	s.GracefulStop()
-	s.health.Shutdown()
 	s.log.Info("[gRPC] server stopping")
 	return nil
 }
 
+// methodTimeout returns the timeout of the full method, exact match first, then the service wildcard.
+func (s *Server) methodTimeout(fullMethod string) time.Duration {
+	if timeout, ok := s.methodTimeouts[fullMethod]; ok {
+		return timeout
+	}
+	if i := strings.LastIndex(fullMethod, "/"); i > 0 {
+		if timeout, ok := s.methodTimeouts[fullMethod[:i+1]+"*"]; ok {
+			return timeout
+		}
+	}
+	return s.timeout
+}
+
 func (s *Server) unaryServerInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 		ctx, cancel := ic.Merge(ctx, s.ctx)
@@ -199,8 +337,8 @@ func (s *Server) unaryServerInterceptor() grpc.UnaryServerInterceptor {
 			reqHeader:   headerCarrier(md),
 			replyHeader: headerCarrier(replyHeader),
 		})
-		if s.timeout > 0 {
-			ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		if timeout := s.methodTimeout(info.FullMethod); timeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, timeout)
 			defer cancel()
 		}
 		h := func(ctx context.Context, req interface{}) (interface{}, error) {
@@ -213,6 +351,9 @@ func (s *Server) unaryServerInterceptor() grpc.UnaryServerInterceptor {
 		if len(replyHeader) > 0 {
 			_ = grpc.SetHeader(ctx, replyHeader)
 		}
+		if err != nil && s.errorCause {
+			err = errors.StatusWithCause(err).Err()
+		}
 		return reply, err
 	}
 }
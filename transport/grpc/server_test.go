@@ -3,9 +3,13 @@ package grpc
 import (
 	"context"
 	"crypto/tls"
+	stderrors "errors"
+	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
 	"net/url"
 	"strings"
 	"testing"
@@ -134,6 +138,65 @@ func TestOptions(t *testing.T) {
 	assert.Equal(t, v, o.grpcOpts)
 }
 
+func TestMaxMsgSize(t *testing.T) {
+	o := &Server{}
+	MaxRecvMsgSize(8 << 20)(o)
+	MaxSendMsgSize(16 << 20)(o)
+	assert.Equal(t, 8<<20, o.maxRecvMsgSize)
+	assert.Equal(t, 16<<20, o.maxSendMsgSize)
+}
+
+func TestKeepalive(t *testing.T) {
+	o := &Server{}
+	KeepaliveParams(time.Minute, 10*time.Second)(o)
+	KeepaliveEnforcementPolicy(30*time.Second, true)(o)
+	assert.Equal(t, &keepalive.ServerParameters{Time: time.Minute, Timeout: 10 * time.Second}, o.keepalive)
+	assert.Equal(t, &keepalive.EnforcementPolicy{MinTime: 30 * time.Second, PermitWithoutStream: true}, o.enforcement)
+}
+
+func TestMethodTimeout(t *testing.T) {
+	o := &Server{timeout: time.Second}
+	MethodTimeout(map[string]time.Duration{
+		"/pkg.Service/*":      5 * time.Second,
+		"/pkg.Service/Report": time.Minute,
+	})(o)
+	assert.Equal(t, time.Minute, o.methodTimeout("/pkg.Service/Report"))
+	assert.Equal(t, 5*time.Second, o.methodTimeout("/pkg.Service/Get"))
+	assert.Equal(t, time.Second, o.methodTimeout("/pkg.Other/Get"))
+}
+
+func TestHealthCheck(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer(Reflection(false))
+	assert.False(t, srv.reflection)
+	_, ok := srv.GetServiceInfo()["grpc.reflection.v1alpha.ServerReflection"]
+	assert.False(t, ok)
+
+	check := func(service string) grpc_health_v1.HealthCheckResponse_ServingStatus {
+		resp, err := srv.health.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: service})
+		assert.NoError(t, err)
+		return resp.Status
+	}
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, check(""))
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, check(""))
+	srv.SetServingStatus("helloworld.Greeter", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, check("helloworld.Greeter"))
+	_ = srv.Stop(ctx)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, check(""))
+
+	srv = NewServer(HealthCheck(false))
+	assert.Nil(t, srv.health)
+	_, ok = srv.GetServiceInfo()["grpc.health.v1.Health"]
+	assert.False(t, ok)
+	srv.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
+}
+
 type testResp struct {
 	Data string
 }
@@ -153,3 +216,21 @@ func TestServer_unaryServerInterceptor(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "hi", rv.(*testResp).Data)
 }
+
+func TestErrorCause(t *testing.T) {
+	u, err := url.Parse("grpc://hello/world")
+	assert.NoError(t, err)
+	srv := &Server{ctx: context.Background(), endpoint: u}
+	ErrorCause(true)(srv)
+	assert.True(t, srv.errorCause)
+
+	cause := stderrors.New("connection refused")
+	_, err = srv.unaryServerInterceptor()(context.TODO(), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, errors.ServiceUnavailable("DB", "database unavailable").WithCause(cause)
+	})
+	err = unaryClientInterceptor(nil, 0)(context.TODO(), "hello", nil, nil, &grpc.ClientConn{}, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return err
+	})
+	assert.Equal(t, "DB", errors.Reason(err))
+	assert.Equal(t, "connection refused", stderrors.Unwrap(err).Error())
+}
@@ -0,0 +1,115 @@
+package grpc
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	ic "github.com/go-kratos/kratos/v2/internal/context"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+
+	"google.golang.org/grpc"
+	grpcmd "google.golang.org/grpc/metadata"
+)
+
+// StreamMessage is a message of a stream, it is observed after it is received or sent.
+type StreamMessage struct {
+	// Operation is the full method of the stream.
+	Operation string
+	// Sent reports whether the message is sent by the server, otherwise it is received.
+	Sent bool
+	// Msg is the message, which is not filled if Err is not nil for a received message.
+	Msg interface{}
+	// Err is the error of receiving or sending the message, io.EOF when the client closes the stream.
+	Err error
+}
+
+// StreamMessageObserver with the observer of every message of the streams, e.g. to log or count the messages.
+func StreamMessageObserver(f func(ctx context.Context, m StreamMessage)) ServerOption {
+	return func(s *Server) {
+		s.streamObserver = f
+	}
+}
+
+// streamServerInterceptor runs the server middleware once per stream, it is invoked with a nil request
+// when the stream opens, and returns when the stream closes, so that the middleware like recovery and tracing
+// cover the whole stream. The stream is served with the context of the middleware.
+func (s *Server) streamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, cancel := ic.Merge(ss.Context(), s.ctx)
+		defer cancel()
+		md, _ := grpcmd.FromIncomingContext(ctx)
+		replyHeader := grpcmd.MD{}
+		ctx = transport.NewServerContext(ctx, &Transport{
+			endpoint:    s.endpoint.String(),
+			operation:   info.FullMethod,
+			reqHeader:   headerCarrier(md),
+			replyHeader: headerCarrier(replyHeader),
+		})
+		ws := &wrappedStream{ServerStream: ss, operation: info.FullMethod, header: replyHeader, observer: s.streamObserver}
+		h := func(ctx context.Context, _ interface{}) (interface{}, error) {
+			ws.ctx = ctx
+			err := handler(srv, ws)
+			ws.flushHeader()
+			return nil, err
+		}
+		if len(s.middleware) > 0 {
+			h = middleware.Chain(s.middleware...)(h)
+		}
+		_, err := h(ctx, nil)
+		if err != nil && s.errorCause {
+			err = errors.StatusWithCause(err).Err()
+		}
+		return err
+	}
+}
+
+// wrappedStream serves a stream with the context of the middleware,
+// the reply header is sent before the first message.
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx       context.Context
+	operation string
+	header    grpcmd.MD
+	once      sync.Once
+	observer  func(ctx context.Context, m StreamMessage)
+}
+
+func (w *wrappedStream) Context() context.Context {
+	return w.ctx
+}
+
+func (w *wrappedStream) flushHeader() {
+	w.once.Do(func() {
+		if len(w.header) > 0 {
+			_ = w.ServerStream.SetHeader(w.header)
+		}
+	})
+}
+
+func (w *wrappedStream) SendHeader(md grpcmd.MD) error {
+	w.flushHeader()
+	return w.ServerStream.SendHeader(md)
+}
+
+func (w *wrappedStream) SendMsg(m interface{}) error {
+	w.flushHeader()
+	err := w.ServerStream.SendMsg(m)
+	if w.observer != nil {
+		w.observer(w.ctx, StreamMessage{Operation: w.operation, Sent: true, Msg: m, Err: err})
+	}
+	return err
+}
+
+func (w *wrappedStream) RecvMsg(m interface{}) error {
+	err := w.ServerStream.RecvMsg(m)
+	if w.observer != nil {
+		msg := m
+		if err != nil {
+			msg = nil
+		}
+		w.observer(w.ctx, StreamMessage{Operation: w.operation, Msg: msg, Err: err})
+	}
+	return err
+}
@@ -0,0 +1,122 @@
+package grpc
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/recovery"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	grpcmd "google.golang.org/grpc/metadata"
+)
+
+type streamKey struct{}
+
+type fakeStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	recv   []string
+	sent   []interface{}
+	header grpcmd.MD
+}
+
+func (s *fakeStream) Context() context.Context { return s.ctx }
+
+func (s *fakeStream) SetHeader(md grpcmd.MD) error {
+	s.header = grpcmd.Join(s.header, md)
+	return nil
+}
+
+func (s *fakeStream) SendHeader(md grpcmd.MD) error {
+	return s.SetHeader(md)
+}
+
+func (s *fakeStream) SendMsg(m interface{}) error {
+	s.sent = append(s.sent, m)
+	return nil
+}
+
+func (s *fakeStream) RecvMsg(m interface{}) error {
+	if len(s.recv) == 0 {
+		return io.EOF
+	}
+	*(m.(*string)) = s.recv[0]
+	s.recv = s.recv[1:]
+	return nil
+}
+
+func newStreamServer(t *testing.T, ms ...middleware.Middleware) *Server {
+	u, err := url.Parse("grpc://hello/world")
+	assert.NoError(t, err)
+	return &Server{ctx: context.Background(), endpoint: u, middleware: ms}
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	var messages []StreamMessage
+	srv := newStreamServer(t, func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				tr.ReplyHeader().Set("x-stream", tr.Operation())
+			}
+			return handler(context.WithValue(ctx, streamKey{}, "value"), req)
+		}
+	})
+	StreamMessageObserver(func(ctx context.Context, m StreamMessage) {
+		assert.Equal(t, "value", ctx.Value(streamKey{}))
+		messages = append(messages, m)
+	})(srv)
+
+	ss := &fakeStream{ctx: context.Background(), recv: []string{"ping"}}
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Echo/Stream"}
+	err := srv.streamServerInterceptor()(nil, ss, info, func(srv interface{}, stream grpc.ServerStream) error {
+		assert.Equal(t, "value", stream.Context().Value(streamKey{}))
+		for {
+			var msg string
+			if err := stream.RecvMsg(&msg); err != nil {
+				if err == io.EOF {
+					return nil
+				}
+				return err
+			}
+			if err := stream.SendMsg(msg + "-pong"); err != nil {
+				return err
+			}
+		}
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/test.Echo/Stream"}, ss.header.Get("x-stream"))
+	assert.Equal(t, []interface{}{"ping-pong"}, ss.sent)
+	if assert.Len(t, messages, 3) {
+		assert.False(t, messages[0].Sent)
+		assert.Equal(t, "ping", *(messages[0].Msg.(*string)))
+		assert.True(t, messages[1].Sent)
+		assert.Equal(t, "ping-pong", messages[1].Msg)
+		assert.Nil(t, messages[2].Msg)
+		assert.Equal(t, io.EOF, messages[2].Err)
+	}
+}
+
+func TestStreamServerInterceptorRecovery(t *testing.T) {
+	srv := newStreamServer(t, recovery.Recovery())
+	ss := &fakeStream{ctx: context.Background()}
+	err := srv.streamServerInterceptor()(nil, ss, &grpc.StreamServerInfo{}, func(srv interface{}, stream grpc.ServerStream) error {
+		panic("stream panic")
+	})
+	assert.Equal(t, "RECOVERY", errors.Reason(err))
+}
+
+func TestStreamInterceptor(t *testing.T) {
+	o := &Server{}
+	v := []grpc.StreamServerInterceptor{
+		func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return handler(srv, ss)
+		},
+	}
+	StreamInterceptor(v...)(o)
+	assert.Equal(t, len(v), len(o.streamInts))
+}
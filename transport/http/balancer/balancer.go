@@ -19,3 +19,42 @@ type Balancer interface {
 	// Update nodes when nodes removed or added
 	Update(nodes []*registry.ServiceInstance)
 }
+
+// Filter reports whether the node can be picked.
+type Filter func(*registry.ServiceInstance) bool
+
+type filterKey struct{}
+
+// NewFilterContext returns a new Context that carries the node filter for a single call,
+// the filter is composed with any filter already carried by ctx.
+func NewFilterContext(ctx context.Context, filter Filter) context.Context {
+	if parent, ok := FromFilterContext(ctx); ok {
+		next := filter
+		filter = func(node *registry.ServiceInstance) bool {
+			return parent(node) && next(node)
+		}
+	}
+	return context.WithValue(ctx, filterKey{}, filter)
+}
+
+// FromFilterContext returns the node filter stored in ctx, if any.
+func FromFilterContext(ctx context.Context) (filter Filter, ok bool) {
+	filter, ok = ctx.Value(filterKey{}).(Filter)
+	return
+}
+
+// FilterNodes returns the nodes accepted by the filter stored in ctx,
+// all nodes are returned if ctx carries no filter.
+func FilterNodes(ctx context.Context, nodes []*registry.ServiceInstance) []*registry.ServiceInstance {
+	filter, ok := FromFilterContext(ctx)
+	if !ok {
+		return nodes
+	}
+	filtered := make([]*registry.ServiceInstance, 0, len(nodes))
+	for _, node := range nodes {
+		if filter(node) {
+			filtered = append(filtered, node)
+		}
+	}
+	return filtered
+}
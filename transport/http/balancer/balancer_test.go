@@ -0,0 +1,31 @@
+package balancer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterContext(t *testing.T) {
+	a := &registry.ServiceInstance{ID: "a", Version: "v1", Metadata: map[string]string{"canary": "true"}}
+	b := &registry.ServiceInstance{ID: "b", Version: "v2", Metadata: map[string]string{"canary": "true"}}
+	c := &registry.ServiceInstance{ID: "c", Version: "v1"}
+	nodes := []*registry.ServiceInstance{a, b, c}
+
+	ctx := context.Background()
+	_, ok := FromFilterContext(ctx)
+	assert.False(t, ok)
+	assert.Equal(t, nodes, FilterNodes(ctx, nodes))
+
+	ctx = NewFilterContext(ctx, func(node *registry.ServiceInstance) bool {
+		return node.Metadata["canary"] == "true"
+	})
+	assert.Equal(t, []*registry.ServiceInstance{a, b}, FilterNodes(ctx, nodes))
+
+	ctx = NewFilterContext(ctx, func(node *registry.ServiceInstance) bool {
+		return node.Version == "v1"
+	})
+	assert.Equal(t, []*registry.ServiceInstance{a}, FilterNodes(ctx, nodes))
+}
@@ -0,0 +1,87 @@
+package balancer
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/transport/circuitbreaker"
+)
+
+var _ Balancer = (*breakerBalancer)(nil)
+
+type breakerBalancer struct {
+	Balancer
+	group *circuitbreaker.Group
+	lock  sync.RWMutex
+	size  int
+}
+
+// WithBreaker returns a Balancer which skips the nodes whose breaker in the group is open,
+// the result of each call is attributed to the breaker of the picked node address.
+// The client errors, e.g. 4xx, don't count as failures.
+func WithBreaker(b Balancer, group *circuitbreaker.Group) Balancer {
+	return &breakerBalancer{Balancer: b, group: group}
+}
+
+func (b *breakerBalancer) Pick(ctx context.Context) (*registry.ServiceInstance, func(context.Context, DoneInfo), error) {
+	b.lock.RLock()
+	size := b.size
+	b.lock.RUnlock()
+
+	var rejected map[*registry.ServiceInstance]struct{}
+	for i := 0; i <= size; i++ {
+		node, done, err := b.Balancer.Pick(ctx)
+		if err != nil {
+			if rejected != nil {
+				return nil, nil, circuitbreaker.ErrNotAllowed
+			}
+			return nil, nil, err
+		}
+		cb := b.group.Get(nodeAddress(node))
+		if cb.Allow() == nil {
+			return node, func(ctx context.Context, di DoneInfo) {
+				if isFailure(di.Err) {
+					cb.MarkFailed()
+				} else {
+					cb.MarkSuccess()
+				}
+				if done != nil {
+					done(ctx, di)
+				}
+			}, nil
+		}
+		if done != nil {
+			done(ctx, DoneInfo{Err: circuitbreaker.ErrNotAllowed})
+		}
+		if rejected == nil {
+			rejected = make(map[*registry.ServiceInstance]struct{})
+			ctx = NewFilterContext(ctx, func(node *registry.ServiceInstance) bool {
+				_, ok := rejected[node]
+				return !ok
+			})
+		}
+		rejected[node] = struct{}{}
+	}
+	return nil, nil, circuitbreaker.ErrNotAllowed
+}
+
+func (b *breakerBalancer) Update(nodes []*registry.ServiceInstance) {
+	b.lock.Lock()
+	b.size = len(nodes)
+	b.lock.Unlock()
+	b.Balancer.Update(nodes)
+}
+
+// isFailure reports whether the call error counts as a node failure.
+func isFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	se := new(errors.Error)
+	if errors.As(err, &se) {
+		return se.Code >= 500
+	}
+	return true
+}
@@ -0,0 +1,41 @@
+package balancer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/transport/circuitbreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithBreaker(t *testing.T) {
+	nodes := []*registry.ServiceInstance{
+		{ID: "1", Endpoints: []string{"http://127.0.0.1:8001"}},
+		{ID: "2", Endpoints: []string{"http://127.0.0.1:8002"}},
+	}
+	g := circuitbreaker.NewGroup(circuitbreaker.WithRequest(1), circuitbreaker.WithRatio(0.5))
+	b := WithBreaker(&firstBalancer{}, g)
+	b.Update(nodes)
+
+	node, done, err := b.Pick(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "1", node.ID)
+	// the client errors don't trip the breaker.
+	done(context.Background(), DoneInfo{Err: kerrors.BadRequest("", "")})
+	node, done, err = b.Pick(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "1", node.ID)
+	done(context.Background(), DoneInfo{Err: errors.New("connection refused")})
+
+	// node 1 is tripped, node 2 keeps serving.
+	node, done, err = b.Pick(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "2", node.ID)
+	done(context.Background(), DoneInfo{Err: kerrors.ServiceUnavailable("", "")})
+
+	_, _, err = b.Pick(context.Background())
+	assert.Equal(t, circuitbreaker.ErrNotAllowed, err)
+}
@@ -0,0 +1,49 @@
+package balancer
+
+import (
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+const defaultCacheSize = 1024
+
+// Cached returns a Filter which caches the results of the underlying filter by node,
+// the underlying filter runs again only for the nodes of a new node list.
+// The results of at most 2*size nodes are kept, so the cache does not grow as nodes change.
+func Cached(filter Filter, size ...int) Filter {
+	c := &filterCache{
+		filter: filter,
+		size:   defaultCacheSize,
+		cur:    make(map[*registry.ServiceInstance]bool),
+	}
+	if len(size) > 0 && size[0] > 0 {
+		c.size = size[0]
+	}
+	return c.match
+}
+
+type filterCache struct {
+	filter Filter
+	size   int
+	lock   sync.Mutex
+	cur    map[*registry.ServiceInstance]bool
+	prev   map[*registry.ServiceInstance]bool
+}
+
+func (c *filterCache) match(node *registry.ServiceInstance) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if ok, found := c.cur[node]; found {
+		return ok
+	}
+	ok, found := c.prev[node]
+	if !found {
+		ok = c.filter(node)
+	}
+	if len(c.cur) >= c.size {
+		c.prev, c.cur = c.cur, make(map[*registry.ServiceInstance]bool, c.size)
+	}
+	c.cur[node] = ok
+	return ok
+}
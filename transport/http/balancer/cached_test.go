@@ -0,0 +1,36 @@
+package balancer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCached(t *testing.T) {
+	calls := 0
+	f := Cached(func(node *registry.ServiceInstance) bool {
+		calls++
+		return node.Version == "v1"
+	}, 2)
+	a := &registry.ServiceInstance{ID: "a", Version: "v1"}
+	b := &registry.ServiceInstance{ID: "b", Version: "v2"}
+	ctx := NewFilterContext(context.Background(), f)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, []*registry.ServiceInstance{a}, FilterNodes(ctx, []*registry.ServiceInstance{a, b}))
+	}
+	assert.Equal(t, 2, calls)
+
+	// a new node list is filtered again.
+	c := &registry.ServiceInstance{ID: "a", Version: "v2"}
+	assert.Equal(t, []*registry.ServiceInstance{a}, FilterNodes(ctx, []*registry.ServiceInstance{a, c}))
+	assert.Equal(t, 3, calls)
+
+	// the evicted nodes are kept at most 2*size.
+	cache := &filterCache{filter: func(*registry.ServiceInstance) bool { return true }, size: 2, cur: map[*registry.ServiceInstance]bool{}}
+	for i := 0; i < 100; i++ {
+		cache.match(&registry.ServiceInstance{})
+	}
+	assert.LessOrEqual(t, len(cache.cur)+len(cache.prev), 4)
+}
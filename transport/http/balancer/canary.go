@@ -0,0 +1,151 @@
+package balancer
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+var _ Balancer = (*canaryBalancer)(nil)
+
+// Canary is the live-updatable percentage of the calls which are sent to the canary nodes,
+// e.g. it is updated by a config observer:
+//
+//	c.Watch("canary.percent", func(_ string, v config.Value) {
+//		if p, err := v.Float(); err == nil {
+//			canary.Set(p)
+//		}
+//	})
+type Canary struct {
+	percent uint64
+}
+
+// NewCanary returns a Canary of the percentage.
+func NewCanary(percent float64) *Canary {
+	c := &Canary{}
+	c.Set(percent)
+	return c
+}
+
+// Set sets the percentage, which is clamped to [0, 100].
+func (c *Canary) Set(percent float64) {
+	percent = math.Max(0, math.Min(100, percent))
+	atomic.StoreUint64(&c.percent, math.Float64bits(percent))
+}
+
+// Percent returns the percentage.
+func (c *Canary) Percent() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&c.percent))
+}
+
+// CanaryOption is canary balancer option.
+type CanaryOption func(*canaryBalancer)
+
+// CanaryMetadata with the metadata key and value of the canary nodes, default is canary=true.
+func CanaryMetadata(key, value string) CanaryOption {
+	return func(b *canaryBalancer) {
+		b.key = key
+		b.value = value
+	}
+}
+
+// CanaryHeader with the request header which pins a call to the canary nodes if it is true, e.g. for the internal testers,
+// default is X-Canary.
+func CanaryHeader(name string) CanaryOption {
+	return func(b *canaryBalancer) {
+		b.header = name
+	}
+}
+
+// CanaryRand with the source of randomness, it is meant for reproducible picks in tests,
+// the default is the math/rand package source.
+func CanaryRand(r *rand.Rand) CanaryOption {
+	return func(b *canaryBalancer) {
+		b.rand = NewRand(r)
+	}
+}
+
+type canaryBalancer struct {
+	Balancer
+	canary *Canary
+	key    string
+	value  string
+	header string
+	rand   *Rand
+
+	lock sync.RWMutex
+	// canaries is the number of the canary nodes.
+	canaries int
+}
+
+// WithCanary returns a Balancer which sends the percentage of the calls to the canary nodes which are selected
+// by the metadata, and the others to the stable nodes. The calls whose request header is true are pinned to
+// the canary nodes. The calls are sent to the other pool if the chosen one has no available node,
+// e.g. all calls are sent to the stable nodes if there is no canary node.
+func WithCanary(b Balancer, canary *Canary, opts ...CanaryOption) Balancer {
+	cb := &canaryBalancer{
+		Balancer: b,
+		canary:   canary,
+		key:      "canary",
+		value:    "true",
+		header:   "X-Canary",
+	}
+	for _, o := range opts {
+		o(cb)
+	}
+	return cb
+}
+
+func (b *canaryBalancer) Pick(ctx context.Context) (*registry.ServiceInstance, func(context.Context, DoneInfo), error) {
+	b.lock.RLock()
+	canaries := b.canaries
+	b.lock.RUnlock()
+	if canaries == 0 {
+		return b.Balancer.Pick(ctx)
+	}
+	canary := b.pinned(ctx) || b.rand.Float64()*100 < b.canary.Percent()
+	node, done, err := b.Balancer.Pick(NewFilterContext(ctx, b.poolFilter(canary)))
+	if err != nil {
+		// the chosen pool has no available node.
+		return b.Balancer.Pick(NewFilterContext(ctx, b.poolFilter(!canary)))
+	}
+	return node, done, nil
+}
+
+// pinned reports whether the request header of the call pins it to the canary nodes.
+func (b *canaryBalancer) pinned(ctx context.Context) bool {
+	if b.header == "" {
+		return false
+	}
+	tr, ok := transport.FromClientContext(ctx)
+	if !ok || tr.RequestHeader() == nil {
+		return false
+	}
+	pinned, _ := strconv.ParseBool(tr.RequestHeader().Get(b.header))
+	return pinned
+}
+
+func (b *canaryBalancer) poolFilter(canary bool) Filter {
+	return func(node *registry.ServiceInstance) bool {
+		return (node.Metadata[b.key] == b.value) == canary
+	}
+}
+
+func (b *canaryBalancer) Update(nodes []*registry.ServiceInstance) {
+	var canaries int
+	for _, node := range nodes {
+		if node.Metadata[b.key] == b.value {
+			canaries++
+		}
+	}
+	b.lock.Lock()
+	b.canaries = canaries
+	b.lock.Unlock()
+	b.Balancer.Update(nodes)
+}
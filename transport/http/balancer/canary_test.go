@@ -0,0 +1,98 @@
+package balancer
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/stretchr/testify/assert"
+)
+
+type canaryTransport struct {
+	header http.Header
+}
+
+func (tr *canaryTransport) Kind() transport.Kind            { return transport.KindHTTP }
+func (tr *canaryTransport) Endpoint() string                { return "discovery:///canary" }
+func (tr *canaryTransport) Operation() string               { return "/canary" }
+func (tr *canaryTransport) RequestHeader() transport.Header { return canaryHeader(tr.header) }
+func (tr *canaryTransport) ReplyHeader() transport.Header   { return canaryHeader(http.Header{}) }
+
+type canaryHeader http.Header
+
+func (h canaryHeader) Get(key string) string { return http.Header(h).Get(key) }
+func (h canaryHeader) Set(key, value string) { http.Header(h).Set(key, value) }
+func (h canaryHeader) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func canaryNodes() []*registry.ServiceInstance {
+	return []*registry.ServiceInstance{
+		{ID: "s1", Endpoints: []string{"http://127.0.0.1:8001"}},
+		{ID: "c1", Endpoints: []string{"http://127.0.0.1:8002"}, Metadata: map[string]string{"canary": "true"}},
+	}
+}
+
+func countCanaries(t *testing.T, b Balancer, ctx context.Context, n int) map[string]int {
+	counts := make(map[string]int)
+	for i := 0; i < n; i++ {
+		node, _, err := b.Pick(ctx)
+		assert.NoError(t, err)
+		counts[node.ID]++
+	}
+	return counts
+}
+
+func TestCanary(t *testing.T) {
+	c := NewCanary(150)
+	assert.Equal(t, float64(100), c.Percent())
+	c.Set(-1)
+	assert.Equal(t, float64(0), c.Percent())
+	c.Set(12.5)
+	assert.Equal(t, 12.5, c.Percent())
+}
+
+func TestWithCanary(t *testing.T) {
+	c := NewCanary(0)
+	b := WithCanary(&firstBalancer{}, c, CanaryRand(rand.New(rand.NewSource(1))))
+	b.Update(canaryNodes())
+	assert.Equal(t, map[string]int{"s1": 100}, countCanaries(t, b, context.Background(), 100))
+
+	c.Set(100)
+	assert.Equal(t, map[string]int{"c1": 100}, countCanaries(t, b, context.Background(), 100))
+
+	c.Set(20)
+	counts := countCanaries(t, b, context.Background(), 1000)
+	assert.InDelta(t, 200, counts["c1"], 50)
+	assert.InDelta(t, 800, counts["s1"], 50)
+
+	// the canary nodes are unavailable.
+	ctx := NewFilterContext(context.Background(), func(node *registry.ServiceInstance) bool {
+		return node.ID != "c1"
+	})
+	c.Set(100)
+	assert.Equal(t, map[string]int{"s1": 10}, countCanaries(t, b, ctx, 10))
+}
+
+func TestWithCanaryPinned(t *testing.T) {
+	b := WithCanary(&firstBalancer{}, NewCanary(0))
+	b.Update(canaryNodes())
+	ctx := transport.NewClientContext(context.Background(), &canaryTransport{header: http.Header{"X-Canary": []string{"true"}}})
+	assert.Equal(t, map[string]int{"c1": 10}, countCanaries(t, b, ctx, 10))
+
+	ctx = transport.NewClientContext(context.Background(), &canaryTransport{header: http.Header{"X-Canary": []string{"false"}}})
+	assert.Equal(t, map[string]int{"s1": 10}, countCanaries(t, b, ctx, 10))
+}
+
+func TestWithCanaryNoCanaries(t *testing.T) {
+	b := WithCanary(&firstBalancer{}, NewCanary(100), CanaryMetadata("track", "canary"))
+	b.Update(canaryNodes())
+	assert.Equal(t, map[string]int{"s1": 10}, countCanaries(t, b, context.Background(), 10))
+}
@@ -0,0 +1,166 @@
+package balancer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+var _ Balancer = (*healthBalancer)(nil)
+
+// errProbing is the done error of a node which is picked while another call probes it.
+var errProbing = errors.New("balancer: node is being probed")
+
+// HealthOption is health balancer option.
+type HealthOption func(*healthBalancer)
+
+// HealthFailures with the consecutive failures which evict a node, default is 5.
+func HealthFailures(n int) HealthOption {
+	return func(b *healthBalancer) {
+		b.failures = n
+	}
+}
+
+// HealthCooldown with how long an evicted node is excluded before it is probed, default is 10s.
+func HealthCooldown(d time.Duration) HealthOption {
+	return func(b *healthBalancer) {
+		b.cooldown = d
+	}
+}
+
+// nodeHealth is the health of a failing node, the healthy nodes have no state.
+type nodeHealth struct {
+	failures int
+	// evicted is until when the node is excluded, zero if it is not evicted.
+	evicted time.Time
+	probing bool
+}
+
+type healthBalancer struct {
+	Balancer
+	failures int
+	cooldown time.Duration
+	now      func() time.Time
+
+	lock  sync.Mutex
+	nodes map[string]*nodeHealth
+	size  int
+}
+
+// WithHealth returns a Balancer which evicts a node after the consecutive failures, the evicted node is
+// excluded from the picks until the cooldown elapses. Then a single call probes the node, it is restored
+// if the probe succeeds, otherwise it is evicted for another cooldown. The client errors, e.g. 4xx,
+// don't count as failures.
+func WithHealth(b Balancer, opts ...HealthOption) Balancer {
+	hb := &healthBalancer{
+		Balancer: b,
+		failures: 5,
+		cooldown: 10 * time.Second,
+		now:      time.Now,
+		nodes:    make(map[string]*nodeHealth),
+	}
+	for _, o := range opts {
+		o(hb)
+	}
+	return hb
+}
+
+func (b *healthBalancer) Pick(ctx context.Context) (*registry.ServiceInstance, func(context.Context, DoneInfo), error) {
+	b.lock.Lock()
+	size := b.size
+	b.lock.Unlock()
+
+	var rejected map[*registry.ServiceInstance]struct{}
+	ctx = NewFilterContext(ctx, func(node *registry.ServiceInstance) bool {
+		if _, ok := rejected[node]; ok {
+			return false
+		}
+		return b.available(nodeAddress(node))
+	})
+	for i := 0; i <= size; i++ {
+		node, done, err := b.Balancer.Pick(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		addr := nodeAddress(node)
+		if b.claim(addr) {
+			return node, func(ctx context.Context, di DoneInfo) {
+				b.mark(addr, isFailure(di.Err))
+				if done != nil {
+					done(ctx, di)
+				}
+			}, nil
+		}
+		// another call claimed the probe of the node since it was filtered.
+		if done != nil {
+			done(ctx, DoneInfo{Err: errProbing})
+		}
+		if rejected == nil {
+			rejected = make(map[*registry.ServiceInstance]struct{})
+		}
+		rejected[node] = struct{}{}
+	}
+	return nil, nil, errProbing
+}
+
+// available reports whether the node is healthy, or it may be probed.
+func (b *healthBalancer) available(addr string) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	h, ok := b.nodes[addr]
+	return !ok || h.evicted.IsZero() || (!h.probing && !b.now().Before(h.evicted))
+}
+
+// claim claims the probe of the picked node if it is evicted, it fails if the node can't be picked.
+func (b *healthBalancer) claim(addr string) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	h, ok := b.nodes[addr]
+	if !ok || h.evicted.IsZero() {
+		return true
+	}
+	if h.probing || b.now().Before(h.evicted) {
+		return false
+	}
+	h.probing = true
+	return true
+}
+
+// mark records the result of a call to the node.
+func (b *healthBalancer) mark(addr string, failed bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if !failed {
+		delete(b.nodes, addr)
+		return
+	}
+	h, ok := b.nodes[addr]
+	if !ok {
+		h = &nodeHealth{}
+		b.nodes[addr] = h
+	}
+	h.failures++
+	if h.probing || (h.evicted.IsZero() && h.failures >= b.failures) {
+		h.probing = false
+		h.evicted = b.now().Add(b.cooldown)
+	}
+}
+
+func (b *healthBalancer) Update(nodes []*registry.ServiceInstance) {
+	b.lock.Lock()
+	addrs := make(map[string]struct{}, len(nodes))
+	for _, node := range nodes {
+		addrs[nodeAddress(node)] = struct{}{}
+	}
+	for addr := range b.nodes {
+		if _, ok := addrs[addr]; !ok {
+			delete(b.nodes, addr)
+		}
+	}
+	b.size = len(nodes)
+	b.lock.Unlock()
+	b.Balancer.Update(nodes)
+}
@@ -0,0 +1,68 @@
+package balancer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithHealth(t *testing.T) {
+	nodes := []*registry.ServiceInstance{
+		{ID: "1", Endpoints: []string{"http://127.0.0.1:8001"}},
+		{ID: "2", Endpoints: []string{"http://127.0.0.1:8002"}},
+	}
+	now := time.Unix(0, 0)
+	b := WithHealth(&firstBalancer{}, HealthFailures(2), HealthCooldown(time.Second)).(*healthBalancer)
+	b.now = func() time.Time { return now }
+	b.Update(nodes)
+	ctx := context.Background()
+
+	pick := func(want string, err error) {
+		t.Helper()
+		node, done, e := b.Pick(ctx)
+		assert.NoError(t, e)
+		assert.Equal(t, want, node.ID)
+		done(ctx, DoneInfo{Err: err})
+	}
+	// the client errors and the non-consecutive failures don't evict the node.
+	pick("1", errors.New("connection refused"))
+	pick("1", kerrors.BadRequest("", ""))
+	pick("1", errors.New("connection refused"))
+	pick("1", kerrors.ServiceUnavailable("", ""))
+
+	// node 1 is evicted until the cooldown elapses.
+	pick("2", nil)
+	now = now.Add(time.Second)
+
+	// a single call probes node 1 and fails, so it is evicted again.
+	probe, done, err := b.Pick(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", probe.ID)
+	pick("2", nil)
+	done(ctx, DoneInfo{Err: errors.New("timeout")})
+	pick("2", nil)
+
+	// the succeeded probe restores node 1.
+	now = now.Add(time.Second)
+	pick("1", nil)
+	pick("1", nil)
+
+	// the nodes are all evicted.
+	for i := 0; i < 2; i++ {
+		pick("1", errors.New("connection refused"))
+	}
+	for i := 0; i < 2; i++ {
+		pick("2", errors.New("connection refused"))
+	}
+	_, _, err = b.Pick(ctx)
+	assert.Error(t, err)
+
+	// the removed nodes lose their health.
+	b.Update(nodes[1:])
+	assert.Len(t, b.nodes, 1)
+}
@@ -0,0 +1,119 @@
+package balancer
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-kratos/kratos/v2/metrics"
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+// Weighter is implemented by the balancers which have a node weight, e.g. wrr.
+type Weighter interface {
+	Weight(node *registry.ServiceInstance) int64
+}
+
+// PickInfo is the info of a pick.
+type PickInfo struct {
+	Node *registry.ServiceInstance
+	// Address is the host of the first node endpoint.
+	Address string
+	// Weight is the current node weight, zero if the balancer is not a Weighter.
+	Weight int64
+	// InFlight is the in-flight request count of the node at pick time.
+	InFlight int64
+}
+
+// PickHook is called on each successful pick.
+type PickHook func(ctx context.Context, info PickInfo)
+
+// MetricsHook returns a PickHook which reports the pick count and the in-flight count,
+// both are labeled by the node address.
+func MetricsHook(picks metrics.Counter, inflight metrics.Observer) PickHook {
+	return func(ctx context.Context, info PickInfo) {
+		if picks != nil {
+			picks.With(info.Address).Inc()
+		}
+		if inflight != nil {
+			inflight.With(info.Address).Observe(float64(info.InFlight))
+		}
+	}
+}
+
+var _ Balancer = (*hookBalancer)(nil)
+
+type hookBalancer struct {
+	Balancer
+	hook     PickHook
+	lock     sync.RWMutex
+	inflight map[*registry.ServiceInstance]*int64
+}
+
+// WithPickHook returns a Balancer which calls the hook on each pick of b,
+// the balancers without hook pay no cost.
+func WithPickHook(b Balancer, hook PickHook) Balancer {
+	return &hookBalancer{
+		Balancer: b,
+		hook:     hook,
+		inflight: make(map[*registry.ServiceInstance]*int64),
+	}
+}
+
+func (b *hookBalancer) Pick(ctx context.Context) (*registry.ServiceInstance, func(context.Context, DoneInfo), error) {
+	node, done, err := b.Balancer.Pick(ctx)
+	if err != nil {
+		return node, done, err
+	}
+	b.lock.RLock()
+	counter, ok := b.inflight[node]
+	b.lock.RUnlock()
+	if !ok {
+		b.lock.Lock()
+		if counter, ok = b.inflight[node]; !ok {
+			counter = new(int64)
+			b.inflight[node] = counter
+		}
+		b.lock.Unlock()
+	}
+	info := PickInfo{
+		Node:     node,
+		Address:  nodeAddress(node),
+		InFlight: atomic.AddInt64(counter, 1) - 1,
+	}
+	if w, ok := b.Balancer.(Weighter); ok {
+		info.Weight = w.Weight(node)
+	}
+	b.hook(ctx, info)
+	return node, func(ctx context.Context, di DoneInfo) {
+		atomic.AddInt64(counter, -1)
+		if done != nil {
+			done(ctx, di)
+		}
+	}, nil
+}
+
+func (b *hookBalancer) Update(nodes []*registry.ServiceInstance) {
+	b.lock.Lock()
+	inflight := make(map[*registry.ServiceInstance]*int64, len(nodes))
+	for _, node := range nodes {
+		if counter, ok := b.inflight[node]; ok {
+			inflight[node] = counter
+		}
+	}
+	b.inflight = inflight
+	b.lock.Unlock()
+	b.Balancer.Update(nodes)
+}
+
+func nodeAddress(node *registry.ServiceInstance) string {
+	if len(node.Endpoints) == 0 {
+		return node.ID
+	}
+	u, err := url.Parse(node.Endpoints[0])
+	if err != nil {
+		return node.Endpoints[0]
+	}
+	return u.Host
+}
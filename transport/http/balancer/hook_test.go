@@ -0,0 +1,57 @@
+package balancer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+type firstBalancer struct {
+	nodes []*registry.ServiceInstance
+}
+
+func (b *firstBalancer) Pick(ctx context.Context) (*registry.ServiceInstance, func(context.Context, DoneInfo), error) {
+	nodes := FilterNodes(ctx, b.nodes)
+	if len(nodes) == 0 {
+		return nil, nil, errors.New("no instances available")
+	}
+	return nodes[0], func(context.Context, DoneInfo) {}, nil
+}
+
+func (b *firstBalancer) Update(nodes []*registry.ServiceInstance) {
+	b.nodes = nodes
+}
+
+func (b *firstBalancer) Weight(node *registry.ServiceInstance) int64 {
+	return 10
+}
+
+func TestWithPickHook(t *testing.T) {
+	var infos []PickInfo
+	b := WithPickHook(&firstBalancer{}, func(ctx context.Context, info PickInfo) {
+		infos = append(infos, info)
+	})
+	_, _, err := b.Pick(context.Background())
+	assert.Error(t, err)
+	assert.Empty(t, infos)
+
+	node := &registry.ServiceInstance{ID: "1", Endpoints: []string{"http://127.0.0.1:8000"}}
+	b.Update([]*registry.ServiceInstance{node})
+	_, done1, err := b.Pick(context.Background())
+	assert.NoError(t, err)
+	_, done2, err := b.Pick(context.Background())
+	assert.NoError(t, err)
+	done1(context.Background(), DoneInfo{})
+	done2(context.Background(), DoneInfo{})
+	_, _, err = b.Pick(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, []PickInfo{
+		{Node: node, Address: "127.0.0.1:8000", Weight: 10, InFlight: 0},
+		{Node: node, Address: "127.0.0.1:8000", Weight: 10, InFlight: 1},
+		{Node: node, Address: "127.0.0.1:8000", Weight: 10, InFlight: 0},
+	}, infos)
+}
@@ -0,0 +1,108 @@
+package leastconn
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/transport/http/balancer"
+)
+
+var _ balancer.Balancer = &Balancer{}
+
+type node struct {
+	ins *registry.ServiceInstance
+	// inflight is shared by the nodes of the instance ID across updates.
+	inflight *int64
+}
+
+// Option is least connections balancer option.
+type Option func(*Balancer)
+
+// WithRand with the source of randomness to break the ties, it is meant for reproducible picks in tests,
+// the math/rand package source is used by default.
+func WithRand(r *rand.Rand) Option {
+	return func(b *Balancer) {
+		b.rand = balancer.NewRand(r)
+	}
+}
+
+// Balancer picks the node with the fewest in-flight requests, ties break randomly.
+type Balancer struct {
+	lock  sync.RWMutex
+	nodes []*node
+	rand  *balancer.Rand
+}
+
+// New creates a least connections balancer.
+func New(opts ...Option) *Balancer {
+	b := &Balancer{}
+	for _, o := range opts {
+		o(b)
+	}
+	return b
+}
+
+// Pick picks the node with the fewest in-flight requests, the request is counted until done is called.
+func (b *Balancer) Pick(ctx context.Context) (*registry.ServiceInstance, func(context.Context, balancer.DoneInfo), error) {
+	filter, hasFilter := balancer.FromFilterContext(ctx)
+
+	b.lock.RLock()
+	nodes := b.nodes
+	b.lock.RUnlock()
+
+	var (
+		picked *node
+		least  int64
+		ties   int
+	)
+	for _, n := range nodes {
+		if hasFilter && !filter(n.ins) {
+			continue
+		}
+		inflight := atomic.LoadInt64(n.inflight)
+		switch {
+		case picked == nil || inflight < least:
+			picked, least, ties = n, inflight, 1
+		case inflight == least:
+			// reservoir sampling among the nodes with the least in-flight requests.
+			ties++
+			if b.rand.Intn(ties) == 0 {
+				picked = n
+			}
+		}
+	}
+	if picked == nil {
+		return nil, nil, fmt.Errorf("no instances available")
+	}
+	atomic.AddInt64(picked.inflight, 1)
+	var once sync.Once
+	return picked.ins, func(context.Context, balancer.DoneInfo) {
+		once.Do(func() {
+			atomic.AddInt64(picked.inflight, -1)
+		})
+	}, nil
+}
+
+// Update updates the nodes and keeps the in-flight count of the remaining nodes by their instance ID,
+// since the registry watchers return the new instances on every change.
+func (b *Balancer) Update(nodes []*registry.ServiceInstance) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	inflight := make(map[string]*int64, len(b.nodes))
+	for _, n := range b.nodes {
+		inflight[n.ins.ID] = n.inflight
+	}
+	updated := make([]*node, 0, len(nodes))
+	for _, ins := range nodes {
+		n := &node{ins: ins, inflight: inflight[ins.ID]}
+		if n.inflight == nil {
+			n.inflight = new(int64)
+		}
+		updated = append(updated, n)
+	}
+	b.nodes = updated
+}
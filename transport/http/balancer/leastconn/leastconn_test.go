@@ -0,0 +1,73 @@
+package leastconn
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/transport/http/balancer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBalancer(t *testing.T) {
+	b := New()
+	_, _, err := b.Pick(context.Background())
+	assert.Error(t, err)
+
+	a := &registry.ServiceInstance{ID: "a"}
+	c := &registry.ServiceInstance{ID: "c"}
+	b.Update([]*registry.ServiceInstance{a, c})
+
+	first, done1, err := b.Pick(context.Background())
+	assert.NoError(t, err)
+	second, done2, err := b.Pick(context.Background())
+	assert.NoError(t, err)
+	assert.NotEqual(t, first, second)
+
+	done1(context.Background(), balancer.DoneInfo{})
+	done1(context.Background(), balancer.DoneInfo{})
+	node, _, err := b.Pick(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, first, node)
+
+	// the in-flight count is kept across updates.
+	b.Update([]*registry.ServiceInstance{a, c})
+	done2(context.Background(), balancer.DoneInfo{})
+	node, _, err = b.Pick(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, second, node)
+}
+
+func TestBalancerUpdateInstances(t *testing.T) {
+	b := New()
+	b.Update([]*registry.ServiceInstance{{ID: "a", Endpoints: []string{"http://127.0.0.1:8000"}}, {ID: "c"}})
+	first, done, err := b.Pick(context.Background())
+	assert.NoError(t, err)
+
+	// the registry watchers return the new instances, the in-flight count is kept by the instance ID.
+	b.Update([]*registry.ServiceInstance{{ID: "a", Endpoints: []string{"http://127.0.0.1:9000"}}, {ID: "c"}})
+	for i := 0; i < 10; i++ {
+		node, done, err := b.Pick(context.Background())
+		assert.NoError(t, err)
+		assert.NotEqual(t, first.ID, node.ID)
+		done(context.Background(), balancer.DoneInfo{})
+	}
+	done(context.Background(), balancer.DoneInfo{})
+	b.Update([]*registry.ServiceInstance{{ID: "a", Endpoints: []string{"http://127.0.0.1:9000"}}})
+	node, _, err := b.Pick(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "http://127.0.0.1:9000", node.Endpoints[0])
+}
+
+func TestBalancerTies(t *testing.T) {
+	b := New()
+	b.Update([]*registry.ServiceInstance{{ID: "a"}, {ID: "b"}, {ID: "c"}})
+	picked := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		node, done, err := b.Pick(context.Background())
+		assert.NoError(t, err)
+		done(context.Background(), balancer.DoneInfo{})
+		picked[node.ID] = true
+	}
+	assert.Len(t, picked, 3)
+}
@@ -0,0 +1,38 @@
+package balancer
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// Rand is the source of randomness of the balancers, the zero value uses the math/rand package source.
+// The injected *rand.Rand is not safe for concurrent use, so it is guarded by a mutex.
+type Rand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+// NewRand creates a Rand from r, e.g. NewRand(rand.New(rand.NewSource(1))) for a reproducible pick sequence in tests.
+func NewRand(r *rand.Rand) *Rand {
+	return &Rand{r: r}
+}
+
+// Intn returns a non-negative pseudo-random number in [0,n).
+func (r *Rand) Intn(n int) int {
+	if r == nil || r.r == nil {
+		return rand.Intn(n)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.r.Intn(n)
+}
+
+// Float64 returns a pseudo-random number in [0.0,1.0).
+func (r *Rand) Float64() float64 {
+	if r == nil || r.r == nil {
+		return rand.Float64()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.r.Float64()
+}
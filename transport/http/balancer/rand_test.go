@@ -0,0 +1,18 @@
+package balancer
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRand(t *testing.T) {
+	var zero *Rand
+	assert.True(t, zero.Intn(3) < 3)
+
+	a, b := NewRand(rand.New(rand.NewSource(1))), NewRand(rand.New(rand.NewSource(1)))
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, a.Intn(100), b.Intn(100))
+	}
+}
@@ -12,13 +12,29 @@ import (
 
 var _ balancer.Balancer = &Balancer{}
 
+// Option is random balancer option.
+type Option func(*Balancer)
+
+// WithRand with the source of randomness, it is meant for reproducible picks in tests,
+// the math/rand package source is used by default.
+func WithRand(r *rand.Rand) Option {
+	return func(b *Balancer) {
+		b.rand = balancer.NewRand(r)
+	}
+}
+
 type Balancer struct {
 	lock  sync.RWMutex
 	nodes []*registry.ServiceInstance
+	rand  *balancer.Rand
 }
 
-func New() *Balancer {
-	return &Balancer{}
+func New(opts ...Option) *Balancer {
+	b := &Balancer{}
+	for _, o := range opts {
+		o(b)
+	}
+	return b
 }
 
 func (b *Balancer) Pick(ctx context.Context) (node *registry.ServiceInstance, done func(context.Context, balancer.DoneInfo), err error) {
@@ -26,13 +42,15 @@ func (b *Balancer) Pick(ctx context.Context) (node *registry.ServiceInstance, do
 	nodes := b.nodes
 	b.lock.RUnlock()
 
+	nodes = balancer.FilterNodes(ctx, nodes)
+
 	if len(nodes) == 0 {
 		return nil, nil, fmt.Errorf("no instances available")
 	}
 	if len(nodes) == 1 {
 		return nodes[0], func(context.Context, balancer.DoneInfo) {}, nil
 	}
-	idx := rand.Intn(len(nodes))
+	idx := b.rand.Intn(len(nodes))
 	return nodes[idx], func(context.Context, balancer.DoneInfo) {}, nil
 }
 
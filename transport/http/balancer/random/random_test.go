@@ -0,0 +1,54 @@
+package random
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/transport/http/balancer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBalancer(t *testing.T) {
+	b := New()
+	_, _, err := b.Pick(context.Background())
+	assert.Error(t, err)
+
+	a := &registry.ServiceInstance{ID: "a"}
+	c := &registry.ServiceInstance{ID: "c", Metadata: map[string]string{"canary": "true"}}
+	b.Update([]*registry.ServiceInstance{a, c})
+	node, done, err := b.Pick(context.Background())
+	assert.NoError(t, err)
+	assert.NotNil(t, done)
+	assert.Contains(t, []*registry.ServiceInstance{a, c}, node)
+
+	ctx := balancer.NewFilterContext(context.Background(), func(node *registry.ServiceInstance) bool {
+		return node.Metadata["canary"] == "true"
+	})
+	for i := 0; i < 10; i++ {
+		node, _, err = b.Pick(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, c, node)
+	}
+
+	ctx = balancer.NewFilterContext(ctx, func(node *registry.ServiceInstance) bool {
+		return false
+	})
+	_, _, err = b.Pick(ctx)
+	assert.Error(t, err)
+}
+
+func TestWithRand(t *testing.T) {
+	nodes := []*registry.ServiceInstance{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	b1, b2 := New(WithRand(rand.New(rand.NewSource(7)))), New(WithRand(rand.New(rand.NewSource(7))))
+	b1.Update(nodes)
+	b2.Update(nodes)
+	for i := 0; i < 10; i++ {
+		n1, _, err := b1.Pick(context.Background())
+		assert.NoError(t, err)
+		n2, _, err := b2.Pick(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, n1, n2)
+	}
+}
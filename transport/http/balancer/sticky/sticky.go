@@ -0,0 +1,112 @@
+package sticky
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/internal/rendezvous"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/http/balancer"
+)
+
+// Name is the name of sticky balancer.
+const Name = "sticky"
+
+var _ balancer.Balancer = &Balancer{}
+
+// KeyFunc extracts the sticky key from the request context.
+type KeyFunc func(ctx context.Context) string
+
+// HeaderKey returns a KeyFunc which extracts the key from the request header.
+func HeaderKey(name string) KeyFunc {
+	return func(ctx context.Context) string {
+		if tr, ok := transport.FromClientContext(ctx); ok {
+			return tr.RequestHeader().Get(name)
+		}
+		return ""
+	}
+}
+
+// Option is sticky balancer option.
+type Option func(*Balancer)
+
+// WithKey with the sticky key extractor.
+func WithKey(key KeyFunc) Option {
+	return func(b *Balancer) {
+		b.key = key
+	}
+}
+
+// WithRand with the source of randomness for the requests without key, it is meant for reproducible picks in tests,
+// the math/rand package source is used by default.
+func WithRand(r *rand.Rand) Option {
+	return func(b *Balancer) {
+		b.rand = balancer.NewRand(r)
+	}
+}
+
+// Balancer picks the same node for the same key by rendezvous hashing,
+// the requests without key are balanced randomly.
+type Balancer struct {
+	lock  sync.RWMutex
+	nodes []*registry.ServiceInstance
+	ids   []string
+	key   KeyFunc
+	rand  *balancer.Rand
+}
+
+// New creates a sticky balancer.
+func New(opts ...Option) *Balancer {
+	b := &Balancer{}
+	for _, o := range opts {
+		o(b)
+	}
+	return b
+}
+
+// Pick picks the node for the key of the request.
+func (b *Balancer) Pick(ctx context.Context) (node *registry.ServiceInstance, done func(context.Context, balancer.DoneInfo), err error) {
+	b.lock.RLock()
+	nodes, ids := b.nodes, b.ids
+	b.lock.RUnlock()
+
+	if _, ok := balancer.FromFilterContext(ctx); ok {
+		nodes = balancer.FilterNodes(ctx, nodes)
+		ids = nodeIDs(nodes)
+	}
+	if len(nodes) == 0 {
+		return nil, nil, fmt.Errorf("no instances available")
+	}
+	var key string
+	if b.key != nil {
+		key = b.key(ctx)
+	}
+	if key == "" {
+		return nodes[b.rand.Intn(len(nodes))], func(context.Context, balancer.DoneInfo) {}, nil
+	}
+	return nodes[rendezvous.Pick(key, ids)], func(context.Context, balancer.DoneInfo) {}, nil
+}
+
+// Update updates the nodes.
+func (b *Balancer) Update(nodes []*registry.ServiceInstance) {
+	ids := nodeIDs(nodes)
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.nodes = nodes
+	b.ids = ids
+}
+
+func nodeIDs(nodes []*registry.ServiceInstance) []string {
+	ids := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		id := node.ID
+		if id == "" && len(node.Endpoints) > 0 {
+			id = node.Endpoints[0]
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
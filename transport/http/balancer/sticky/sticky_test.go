@@ -0,0 +1,38 @@
+package sticky
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+type keyCtx struct{}
+
+func TestBalancer(t *testing.T) {
+	b := New(WithKey(func(ctx context.Context) string {
+		key, _ := ctx.Value(keyCtx{}).(string)
+		return key
+	}))
+	_, _, err := b.Pick(context.Background())
+	assert.Error(t, err)
+
+	var nodes []*registry.ServiceInstance
+	for i := 0; i < 5; i++ {
+		nodes = append(nodes, &registry.ServiceInstance{ID: strconv.Itoa(i)})
+	}
+	b.Update(nodes)
+	ctx := context.WithValue(context.Background(), keyCtx{}, "user-1")
+	first, _, err := b.Pick(ctx)
+	assert.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		node, _, err := b.Pick(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, first, node)
+	}
+	node, _, err := b.Pick(context.Background())
+	assert.NoError(t, err)
+	assert.Contains(t, nodes, node)
+}
@@ -0,0 +1,123 @@
+package wrr
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/transport/http/balancer"
+)
+
+const (
+	// DefaultWeightKey is the instance metadata key of the static node weight,
+	// e.g. weight=200 makes the node receive twice the traffic of the default weight.
+	DefaultWeightKey = "weight"
+	// DefaultWeight is the node weight when the metadata has no valid weight.
+	DefaultWeight = 100
+)
+
+var (
+	_ balancer.Balancer = &Balancer{}
+	_ balancer.Weighter = &Balancer{}
+)
+
+// Option is wrr balancer option.
+type Option func(*Balancer)
+
+// WithWeightKey with the instance metadata key of the node weight.
+func WithWeightKey(key string) Option {
+	return func(b *Balancer) {
+		b.weightKey = key
+	}
+}
+
+type weightedNode struct {
+	node    *registry.ServiceInstance
+	weight  int64
+	current int64
+}
+
+// Balancer is a smooth weighted round-robin balancer which honors the registry instance weights.
+type Balancer struct {
+	lock      sync.Mutex
+	nodes     []*weightedNode
+	weightKey string
+}
+
+// New creates a wrr balancer.
+func New(opts ...Option) *Balancer {
+	b := &Balancer{weightKey: DefaultWeightKey}
+	for _, o := range opts {
+		o(b)
+	}
+	return b
+}
+
+// Pick picks the node with the largest current weight.
+func (b *Balancer) Pick(ctx context.Context) (node *registry.ServiceInstance, done func(context.Context, balancer.DoneInfo), err error) {
+	filter, hasFilter := balancer.FromFilterContext(ctx)
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	var (
+		total    int64
+		selected *weightedNode
+	)
+	for _, n := range b.nodes {
+		if hasFilter && !filter(n.node) {
+			continue
+		}
+		n.current += n.weight
+		total += n.weight
+		if selected == nil || n.current > selected.current {
+			selected = n
+		}
+	}
+	if selected == nil {
+		return nil, nil, fmt.Errorf("no instances available")
+	}
+	selected.current -= total
+	return selected.node, func(context.Context, balancer.DoneInfo) {}, nil
+}
+
+// Update updates the nodes and keeps the current weight of the remaining nodes.
+func (b *Balancer) Update(nodes []*registry.ServiceInstance) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	current := make(map[string]int64, len(b.nodes))
+	for _, n := range b.nodes {
+		current[n.node.ID] = n.current
+	}
+	weighted := make([]*weightedNode, 0, len(nodes))
+	for _, node := range nodes {
+		weighted = append(weighted, &weightedNode{
+			node:    node,
+			weight:  b.weight(node),
+			current: current[node.ID],
+		})
+	}
+	b.nodes = weighted
+}
+
+// Weight returns the static weight of the node, zero if the node is not found.
+func (b *Balancer) Weight(node *registry.ServiceInstance) int64 {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for _, n := range b.nodes {
+		if n.node == node {
+			return n.weight
+		}
+	}
+	return 0
+}
+
+func (b *Balancer) weight(node *registry.ServiceInstance) int64 {
+	if v, ok := node.Metadata[b.weightKey]; ok {
+		if w, err := strconv.ParseInt(v, 10, 64); err == nil && w > 0 {
+			return w
+		}
+	}
+	return DefaultWeight
+}
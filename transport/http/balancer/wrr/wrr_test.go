@@ -0,0 +1,60 @@
+package wrr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/transport/http/balancer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBalancer(t *testing.T) {
+	b := New()
+	_, _, err := b.Pick(context.Background())
+	assert.Error(t, err)
+
+	b.Update([]*registry.ServiceInstance{
+		{ID: "a", Metadata: map[string]string{"weight": "300"}},
+		{ID: "b"},
+		{ID: "c", Metadata: map[string]string{"weight": "bad"}},
+	})
+	counts := map[string]int{}
+	for i := 0; i < 500; i++ {
+		node, done, err := b.Pick(context.Background())
+		assert.NoError(t, err)
+		done(context.Background(), balancer.DoneInfo{})
+		counts[node.ID]++
+	}
+	assert.Equal(t, map[string]int{"a": 300, "b": 100, "c": 100}, counts)
+
+	ctx := balancer.NewFilterContext(context.Background(), func(node *registry.ServiceInstance) bool {
+		return node.ID == "b"
+	})
+	node, _, err := b.Pick(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "b", node.ID)
+}
+
+func TestWithWeightKey(t *testing.T) {
+	b := New(WithWeightKey("lb_weight"))
+	b.Update([]*registry.ServiceInstance{
+		{ID: "a", Metadata: map[string]string{"lb_weight": "2", "weight": "100"}},
+		{ID: "b", Metadata: map[string]string{"lb_weight": "1"}},
+	})
+	counts := map[string]int{}
+	for i := 0; i < 3; i++ {
+		node, _, err := b.Pick(context.Background())
+		assert.NoError(t, err)
+		counts[node.ID]++
+	}
+	assert.Equal(t, map[string]int{"a": 2, "b": 1}, counts)
+}
+
+func TestWeight(t *testing.T) {
+	b := New()
+	node := &registry.ServiceInstance{ID: "a", Metadata: map[string]string{"weight": "300"}}
+	b.Update([]*registry.ServiceInstance{node})
+	assert.Equal(t, int64(300), b.Weight(node))
+	assert.Equal(t, int64(0), b.Weight(&registry.ServiceInstance{ID: "b"}))
+}
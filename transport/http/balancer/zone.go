@@ -0,0 +1,127 @@
+package balancer
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+var _ Balancer = (*zoneBalancer)(nil)
+
+// ZoneOption is zone balancer option.
+type ZoneOption func(*zoneBalancer)
+
+// ZoneKey with the metadata key of the instance zone, default is "zone".
+func ZoneKey(key string) ZoneOption {
+	return func(b *zoneBalancer) {
+		b.key = key
+	}
+}
+
+// ZoneWeight with the share of the picks in [0, 1] which prefer the local zone, default is 1,
+// which sends all calls to the local zone while it has the capacity.
+func ZoneWeight(weight float64) ZoneOption {
+	return func(b *zoneBalancer) {
+		b.weight = weight
+	}
+}
+
+// ZoneSpillover with the ratio in (0, 1] of the available local nodes below which the calls spill over
+// to the other zones, default is 0.5. Below the ratio the local share shrinks in proportion to it,
+// e.g. with the default a zone of 4 nodes which has 1 available node keeps half of the local share.
+func ZoneSpillover(ratio float64) ZoneOption {
+	return func(b *zoneBalancer) {
+		b.spillover = ratio
+	}
+}
+
+// ZoneRand with the source of randomness, it is meant for reproducible picks in tests,
+// the default is the math/rand package source.
+func ZoneRand(r *rand.Rand) ZoneOption {
+	return func(b *zoneBalancer) {
+		b.rand = NewRand(r)
+	}
+}
+
+type zoneBalancer struct {
+	Balancer
+	zone      string
+	key       string
+	weight    float64
+	spillover float64
+	rand      *Rand
+
+	lock  sync.RWMutex
+	local []*registry.ServiceInstance
+	total int
+}
+
+// WithZone returns a Balancer which prefers the nodes of the local zone, whose zone is read from the instance metadata.
+// The available local nodes are the ones accepted by the filter of the call, e.g. the nodes not evicted by WithHealth,
+// so it should be wrapped by WithHealth or WithBreaker. The calls spill over to the other zones when the available
+// local nodes are insufficient or none, and back to the local zone when the other zones have no available node.
+// It is a pass-through if the local zone is empty or all nodes are in the same zone.
+func WithZone(b Balancer, zone string, opts ...ZoneOption) Balancer {
+	zb := &zoneBalancer{
+		Balancer:  b,
+		zone:      zone,
+		key:       "zone",
+		weight:    1,
+		spillover: 0.5,
+	}
+	for _, o := range opts {
+		o(zb)
+	}
+	return zb
+}
+
+func (b *zoneBalancer) Pick(ctx context.Context) (*registry.ServiceInstance, func(context.Context, DoneInfo), error) {
+	b.lock.RLock()
+	local, total := b.local, b.total
+	b.lock.RUnlock()
+	if len(local) == 0 || len(local) == total {
+		return b.Balancer.Pick(ctx)
+	}
+	preferLocal := b.rand.Float64() < b.localShare(ctx, local)
+	node, done, err := b.Balancer.Pick(NewFilterContext(ctx, b.zoneFilter(preferLocal)))
+	if err != nil {
+		// the preferred side has no available node.
+		return b.Balancer.Pick(NewFilterContext(ctx, b.zoneFilter(!preferLocal)))
+	}
+	return node, done, nil
+}
+
+// localShare returns the share of the picks of the local zone, by the ratio of the available local nodes.
+func (b *zoneBalancer) localShare(ctx context.Context, local []*registry.ServiceInstance) float64 {
+	ratio := float64(len(FilterNodes(ctx, local))) / float64(len(local))
+	if b.spillover > 0 && ratio < b.spillover {
+		return b.weight * ratio / b.spillover
+	}
+	if ratio == 0 {
+		return 0
+	}
+	return b.weight
+}
+
+func (b *zoneBalancer) zoneFilter(local bool) Filter {
+	return func(node *registry.ServiceInstance) bool {
+		return (node.Metadata[b.key] == b.zone) == local
+	}
+}
+
+func (b *zoneBalancer) Update(nodes []*registry.ServiceInstance) {
+	var local []*registry.ServiceInstance
+	if b.zone != "" {
+		for _, node := range nodes {
+			if node.Metadata[b.key] == b.zone {
+				local = append(local, node)
+			}
+		}
+	}
+	b.lock.Lock()
+	b.local, b.total = local, len(nodes)
+	b.lock.Unlock()
+	b.Balancer.Update(nodes)
+}
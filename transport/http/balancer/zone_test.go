@@ -0,0 +1,78 @@
+package balancer
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func zoneNodes() []*registry.ServiceInstance {
+	return []*registry.ServiceInstance{
+		{ID: "b1", Endpoints: []string{"http://127.0.0.1:8001"}, Metadata: map[string]string{"zone": "b"}},
+		{ID: "a1", Endpoints: []string{"http://127.0.0.1:8002"}, Metadata: map[string]string{"zone": "a"}},
+		{ID: "a2", Endpoints: []string{"http://127.0.0.1:8003"}, Metadata: map[string]string{"zone": "a"}},
+	}
+}
+
+func countZones(t *testing.T, b Balancer, ctx context.Context, n int) map[string]int {
+	counts := make(map[string]int)
+	for i := 0; i < n; i++ {
+		node, _, err := b.Pick(ctx)
+		assert.NoError(t, err)
+		counts[node.Metadata["zone"]]++
+	}
+	return counts
+}
+
+func TestWithZone(t *testing.T) {
+	b := WithZone(&firstBalancer{}, "a", ZoneRand(rand.New(rand.NewSource(1))))
+	b.Update(zoneNodes())
+	assert.Equal(t, map[string]int{"a": 100}, countZones(t, b, context.Background(), 100))
+
+	// all local nodes are unavailable.
+	ctx := NewFilterContext(context.Background(), func(node *registry.ServiceInstance) bool {
+		return node.Metadata["zone"] != "a"
+	})
+	assert.Equal(t, map[string]int{"b": 100}, countZones(t, b, ctx, 100))
+
+	// the other zones have no available node.
+	ctx = NewFilterContext(context.Background(), func(node *registry.ServiceInstance) bool {
+		return node.Metadata["zone"] == "a"
+	})
+	b = WithZone(&firstBalancer{}, "a", ZoneWeight(0))
+	b.Update(zoneNodes())
+	assert.Equal(t, map[string]int{"a": 10}, countZones(t, b, ctx, 10))
+}
+
+func TestWithZoneSpillover(t *testing.T) {
+	b := WithZone(&firstBalancer{}, "a", ZoneSpillover(1), ZoneRand(rand.New(rand.NewSource(1))))
+	b.Update(zoneNodes())
+	// a half of the local nodes are available, which halves the local share.
+	ctx := NewFilterContext(context.Background(), func(node *registry.ServiceInstance) bool {
+		return node.ID != "a1"
+	})
+	counts := countZones(t, b, ctx, 1000)
+	assert.InDelta(t, 500, counts["a"], 100)
+	assert.InDelta(t, 500, counts["b"], 100)
+}
+
+func TestWithZoneWeight(t *testing.T) {
+	b := WithZone(&firstBalancer{}, "a", ZoneWeight(0.8), ZoneRand(rand.New(rand.NewSource(1))))
+	b.Update(zoneNodes())
+	counts := countZones(t, b, context.Background(), 1000)
+	assert.InDelta(t, 800, counts["a"], 100)
+	assert.InDelta(t, 200, counts["b"], 100)
+}
+
+func TestWithZonePassThrough(t *testing.T) {
+	for _, zone := range []string{"", "c"} {
+		b := WithZone(&firstBalancer{}, zone)
+		b.Update(zoneNodes())
+		node, _, err := b.Pick(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "b1", node.ID)
+	}
+}
@@ -12,9 +12,10 @@ import (
 
 	"github.com/go-kratos/kratos/v2/encoding"
 	"github.com/go-kratos/kratos/v2/errors"
-	"github.com/go-kratos/kratos/v2/internal/endpoint"
 	"github.com/go-kratos/kratos/v2/internal/host"
 	"github.com/go-kratos/kratos/v2/internal/httputil"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/metrics"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/registry"
 	"github.com/go-kratos/kratos/v2/transport"
@@ -31,14 +32,19 @@ type EncodeRequestFunc func(ctx context.Context, contentType string, in interfac
 // DecodeResponseFunc is response decode func.
 type DecodeResponseFunc func(ctx context.Context, res *http.Response, out interface{}) error
 
+// RequestInterceptor inspects or modifies an outbound request just before its round-trip, e.g. to sign it.
+type RequestInterceptor func(*http.Request) error
+
 // ClientOption is HTTP client option.
 type ClientOption func(*clientOptions)
 
 // Client is an HTTP transport client.
 type clientOptions struct {
-	ctx          context.Context
-	tlsConf      *tls.Config
-	timeout      time.Duration
+	ctx     context.Context
+	tlsConf *tls.Config
+	timeout time.Duration
+	// timeoutSet reports whether the timeout is set by WithTimeout, the Do calls are only bounded then.
+	timeoutSet   bool
 	endpoint     string
 	userAgent    string
 	encoder      EncodeRequestFunc
@@ -49,6 +55,34 @@ type clientOptions struct {
 	discovery    registry.Discovery
 	middleware   []middleware.Middleware
 	block        bool
+	backoff      BackoffFunc
+	keepOnEmpty  bool
+	dedup        bool
+	observer     ObserverFunc
+	dnsInterval  time.Duration
+	closeTimeout time.Duration
+	subsetSize   int
+	// subsetClientID is the hash key of the subset, default is the hostname.
+	subsetClientID string
+	filter         NodeFilter
+	schemes        []string
+	blockTimeout   time.Duration
+	drainKey       string
+	drainParse     func(string) bool
+	logger         log.Logger
+	// counter: http_client_resolver_events_total{endpoint, event}
+	resolverMetrics metrics.Counter
+	retry           *RetryConfig
+	hedgeDelay      time.Duration
+	hedgeExtra      int
+	// counter: http_client_hedge_wins_total{endpoint, winner}
+	hedgeMetrics metrics.Counter
+	// the connection pool settings of the transport, zero keeps the setting of the transport.
+	maxIdleConns        int
+	maxIdleConnsPerHost int
+	maxConnsPerHost     int
+	idleConnTimeout     time.Duration
+	interceptors        []RequestInterceptor
 }
 
 // WithTransport with client transport.
@@ -58,10 +92,14 @@ func WithTransport(trans http.RoundTripper) ClientOption {
 	}
 }
 
-// WithTimeout with client request timeout.
+// WithTimeout with client request timeout, which bounds the whole call from the moment it begins,
+// including the node selection, the retries and the round-trips. The deadline of the call context
+// applies if it is earlier. The Do calls are only bounded by the timeout if it is set by this option,
+// the timeout then also bounds the reading of the response body.
 func WithTimeout(d time.Duration) ClientOption {
 	return func(o *clientOptions) {
 		o.timeout = d
+		o.timeoutSet = true
 	}
 }
 
@@ -72,6 +110,15 @@ func WithUserAgent(ua string) ClientOption {
 	}
 }
 
+// WithRequestInterceptor with the interceptors of the outbound requests, which are invoked in order for every
+// round-trip, including the retries and the hedged requests, after the node selection and the URL rewriting.
+// The round-trip is aborted if an interceptor returns an error.
+func WithRequestInterceptor(in ...RequestInterceptor) ClientOption {
+	return func(o *clientOptions) {
+		o.interceptors = append(o.interceptors, in...)
+	}
+}
+
 // WithMiddleware with client middleware.
 func WithMiddleware(m ...middleware.Middleware) ClientOption {
 	return func(o *clientOptions) {
@@ -79,7 +126,8 @@ func WithMiddleware(m ...middleware.Middleware) ClientOption {
 	}
 }
 
-// WithEndpoint with client addr.
+// WithEndpoint with client addr, e.g. "127.0.0.1:8000", "discovery:///demo", "dns:///demo.svc:8000",
+// or "unix:///var/run/app.sock" to dial a Unix domain socket, whose TLS config should set the ServerName.
 func WithEndpoint(endpoint string) ClientOption {
 	return func(o *clientOptions) {
 		o.endpoint = endpoint
@@ -130,6 +178,169 @@ func WithBlock() ClientOption {
 	}
 }
 
+// WithResolverBackoff with resolver watch retry backoff.
+func WithResolverBackoff(backoff BackoffFunc) ClientOption {
+	return func(o *clientOptions) {
+		o.backoff = backoff
+	}
+}
+
+// WithResolverKeepOnEmpty with resolver keep last nodes when discovery returns zero endpoint,
+// set false to clear nodes on empty push.
+func WithResolverKeepOnEmpty(keep bool) ClientOption {
+	return func(o *clientOptions) {
+		o.keepOnEmpty = keep
+	}
+}
+
+// WithResolverDedup with resolver skips the identical watch pushes,
+// set false to update the balancer on every push.
+func WithResolverDedup(dedup bool) ClientOption {
+	return func(o *clientOptions) {
+		o.dedup = dedup
+	}
+}
+
+// WithResolverObserver with resolver nodes change observer.
+// The observer is called synchronously in the watch loop,
+// long-running observers should spawn their own goroutine.
+func WithResolverObserver(observer ObserverFunc) ClientOption {
+	return func(o *clientOptions) {
+		o.observer = observer
+	}
+}
+
+// WithResolverFilter with resolver instance filter,
+// the rejected instances never reach the balancer.
+func WithResolverFilter(filter NodeFilter) ClientOption {
+	return func(o *clientOptions) {
+		o.filter = filter
+	}
+}
+
+// WithResolverDraining with the metadata key which marks an instance as draining and the parser of its value,
+// default is the "draining" key parsed by strconv.ParseBool. A draining instance is removed from the balancer,
+// so the new requests are not routed to it while the in-flight ones finish, and it is fully dropped once the
+// registry stops reporting it. The zero endpoint policy applies if all the instances are draining.
+// An empty key disables the draining, and a nil parse keeps the default parser.
+func WithResolverDraining(key string, parse func(value string) bool) ClientOption {
+	return func(o *clientOptions) {
+		o.drainKey = key
+		if parse != nil {
+			o.drainParse = parse
+		}
+	}
+}
+
+// WithResolverSchemes with prioritized endpoint schemes, e.g. "https", "http",
+// the first scheme present in the instance endpoints is used to dial the node.
+func WithResolverSchemes(schemes ...string) ClientOption {
+	return func(o *clientOptions) {
+		o.schemes = schemes
+	}
+}
+
+// WithResolverCloseTimeout with the max duration Close waits for the resolver watch goroutines to exit.
+func WithResolverCloseTimeout(d time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.closeTimeout = d
+	}
+}
+
+// WithSubset with the max number of resolved nodes surfaced to the balancer,
+// each client picks a stable subset of the nodes based on its hostname.
+func WithSubset(size int) ClientOption {
+	return func(o *clientOptions) {
+		o.subsetSize = size
+	}
+}
+
+// WithDNSRefreshInterval with the re-resolution interval of dns target.
+func WithDNSRefreshInterval(d time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.dnsInterval = d
+	}
+}
+
+// WithResolverBlockTimeout with the timeout of the initial resolution in block mode,
+// which is independent of the deadline of the client context.
+func WithResolverBlockTimeout(d time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.blockTimeout = d
+	}
+}
+
+// WithResolverMetrics with resolver events counter,
+// the labels are the target endpoint and one of update, empty, parse_error and watch_error.
+func WithResolverMetrics(c metrics.Counter) ClientOption {
+	return func(o *clientOptions) {
+		o.resolverMetrics = c
+	}
+}
+
+// WithLogger with client logger.
+func WithLogger(logger log.Logger) ClientOption {
+	return func(o *clientOptions) {
+		o.logger = logger
+	}
+}
+
+// WithRetry with client retry config, only GET, HEAD, PUT and DELETE requests
+// are retried unless other methods are allowed by the config.
+// The balancer picks a node on every attempt.
+func WithRetry(c RetryConfig) ClientOption {
+	return func(o *clientOptions) {
+		o.retry = &c
+	}
+}
+
+// WithHedging with hedged requests of the idempotent methods, an extra request is sent
+// to a different node after every delay until maxExtra extra requests are in flight,
+// the first succeeded response wins and the other requests are canceled.
+func WithHedging(delay time.Duration, maxExtra int) ClientOption {
+	return func(o *clientOptions) {
+		o.hedgeDelay = delay
+		o.hedgeExtra = maxExtra
+	}
+}
+
+// WithHedgingMetrics with the counter of the hedged requests,
+// the labels are the target endpoint and the winner, which is either primary or hedge.
+func WithHedgingMetrics(c metrics.Counter) ClientOption {
+	return func(o *clientOptions) {
+		o.hedgeMetrics = c
+	}
+}
+
+// WithMaxIdleConns with the max idle connections across all the hosts of the transport.
+func WithMaxIdleConns(n int) ClientOption {
+	return func(o *clientOptions) {
+		o.maxIdleConns = n
+	}
+}
+
+// WithMaxIdleConnsPerHost with the max idle connections per host of the transport,
+// the http.DefaultTransport keeps 2, which causes the connection churn under high concurrency.
+func WithMaxIdleConnsPerHost(n int) ClientOption {
+	return func(o *clientOptions) {
+		o.maxIdleConnsPerHost = n
+	}
+}
+
+// WithMaxConnsPerHost with the max connections per host of the transport, including the active ones.
+func WithMaxConnsPerHost(n int) ClientOption {
+	return func(o *clientOptions) {
+		o.maxConnsPerHost = n
+	}
+}
+
+// WithIdleConnTimeout with how long an idle connection of the transport is kept.
+func WithIdleConnTimeout(d time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.idleConnTimeout = d
+	}
+}
+
 // WithTLSConfig with tls config.
 func WithTLSConfig(c *tls.Config) ClientOption {
 	return func(o *clientOptions) {
@@ -149,32 +360,45 @@ type Client struct {
 // NewClient returns an HTTP client.
 func NewClient(ctx context.Context, opts ...ClientOption) (*Client, error) {
 	options := clientOptions{
-		ctx:          ctx,
-		timeout:      2000 * time.Millisecond,
-		encoder:      DefaultRequestEncoder,
-		decoder:      DefaultResponseDecoder,
-		errorDecoder: DefaultErrorDecoder,
-		transport:    http.DefaultTransport,
-		balancer:     random.New(),
+		ctx:            ctx,
+		timeout:        2000 * time.Millisecond,
+		encoder:        DefaultRequestEncoder,
+		decoder:        DefaultResponseDecoder,
+		errorDecoder:   DefaultErrorDecoder,
+		transport:      http.DefaultTransport,
+		balancer:       random.New(),
+		backoff:        defaultBackoff,
+		keepOnEmpty:    true,
+		dedup:          true,
+		drainKey:       defaultDrainKey,
+		drainParse:     parseDraining,
+		dnsInterval:    defaultDNSRefreshInterval,
+		closeTimeout:   5 * time.Second,
+		subsetClientID: defaultSubsetClientID(),
 	}
 	for _, o := range opts {
 		o(&options)
 	}
-	if options.tlsConf != nil {
-		if tr, ok := options.transport.(*http.Transport); ok {
-			tr.TLSClientConfig = options.tlsConf
-		}
-	}
+	options.transport = options.buildTransport()
 	insecure := options.tlsConf == nil
 	target, err := parseTarget(options.endpoint, insecure)
 	if err != nil {
 		return nil, err
 	}
 	var r *resolver
-	if options.discovery != nil {
+	if target.Scheme == "unix" {
+		if options.transport, err = unixTransport(options.transport, target.Endpoint); err != nil {
+			return nil, err
+		}
+		target = unixTarget(target, insecure)
+	} else if target.Scheme == "dns" {
+		if r, err = newDNSResolver(ctx, target, insecure, &options); err != nil {
+			return nil, fmt.Errorf("[http client] new dns resolver failed!endpoint: %v err: %w", options.endpoint, err)
+		}
+	} else if options.discovery != nil {
 		if target.Scheme == "discovery" {
-			if r, err = newResolver(ctx, options.discovery, target, options.balancer, options.block, insecure); err != nil {
-				return nil, fmt.Errorf("[http client] new resolver failed!err: %v", options.endpoint)
+			if r, err = newResolver(ctx, target, insecure, &options); err != nil {
+				return nil, fmt.Errorf("[http client] new resolver failed!endpoint: %v err: %w", options.endpoint, err)
 			}
 		} else if _, _, err := host.ExtractHostPort(options.endpoint); err != nil {
 			return nil, fmt.Errorf("[http client] invalid endpoint format: %v", options.endpoint)
@@ -186,18 +410,56 @@ func NewClient(ctx context.Context, opts ...ClientOption) (*Client, error) {
 		insecure: insecure,
 		r:        r,
 		cc: &http.Client{
-			Timeout:   options.timeout,
 			Transport: options.transport,
 		},
 	}, nil
 }
 
+// buildTransport applies the TLS config and the connection pool settings to the transport, which is either
+// the *http.Transport of WithTransport or a clone of the http.DefaultTransport, so the http.DefaultTransport is never changed.
+func (o *clientOptions) buildTransport() http.RoundTripper {
+	if o.tlsConf == nil && o.maxIdleConns == 0 && o.maxIdleConnsPerHost == 0 && o.maxConnsPerHost == 0 && o.idleConnTimeout == 0 {
+		return o.transport
+	}
+	tr, ok := o.transport.(*http.Transport)
+	if !ok {
+		return o.transport
+	}
+	if tr == http.DefaultTransport {
+		tr = tr.Clone()
+	}
+	if o.tlsConf != nil {
+		tr.TLSClientConfig = o.tlsConf
+	}
+	if o.maxIdleConns > 0 {
+		tr.MaxIdleConns = o.maxIdleConns
+	}
+	if o.maxIdleConnsPerHost > 0 {
+		tr.MaxIdleConnsPerHost = o.maxIdleConnsPerHost
+	}
+	if o.maxConnsPerHost > 0 {
+		tr.MaxConnsPerHost = o.maxConnsPerHost
+	}
+	if o.idleConnTimeout > 0 {
+		tr.IdleConnTimeout = o.idleConnTimeout
+	}
+	return tr
+}
+
+// NewFilterContext returns a new Context that carries the node filter,
+// the balancer only picks the nodes accepted by the filter for calls with the context.
+func NewFilterContext(ctx context.Context, filter NodeFilter) context.Context {
+	return balancer.NewFilterContext(ctx, balancer.Filter(filter))
+}
+
 // Invoke makes an rpc call procedure for remote service.
 func (client *Client) Invoke(ctx context.Context, method, path string, args interface{}, reply interface{}, opts ...CallOption) error {
 	var (
 		contentType string
 		body        io.Reader
 	)
+	ctx, cancel := client.withTimeout(ctx)
+	defer cancel()
 	c := defaultCallInfo(path)
 	for _, o := range opts {
 		if err := o.before(&c); err != nil {
@@ -234,7 +496,7 @@ func (client *Client) Invoke(ctx context.Context, method, path string, args inte
 }
 
 func (client *Client) invoke(ctx context.Context, req *http.Request, args interface{}, reply interface{}, c callInfo, opts ...CallOption) error {
-	h := func(ctx context.Context, in interface{}) (interface{}, error) {
+	attempt := func(ctx context.Context, req *http.Request, picked func(*registry.ServiceInstance)) (*http.Response, error) {
 		var done func(context.Context, balancer.DoneInfo)
 		if client.r != nil {
 			var (
@@ -242,35 +504,75 @@ func (client *Client) invoke(ctx context.Context, req *http.Request, args interf
 				node *registry.ServiceInstance
 			)
 			if node, done, err = client.opts.balancer.Pick(ctx); err != nil {
+				if ctx.Err() == context.DeadlineExceeded {
+					return nil, deadlineError(phasePick)
+				}
 				return nil, errors.ServiceUnavailable("NODE_NOT_FOUND", err.Error())
 			}
-			endpoint, err := endpoint.ParseEndpoint(node.Endpoints, "http", !client.insecure)
+			if picked != nil {
+				picked(node)
+			}
+			ept, err := client.r.endpoint(node)
 			if err != nil {
 				return nil, errors.ServiceUnavailable("NODE_NOT_FOUND", err.Error())
 			}
-			if client.insecure {
-				req.URL.Scheme = "http"
-			} else {
-				req.URL.Scheme = "https"
-			}
-			req.URL.Host = endpoint
-			req.Host = endpoint
+			req.URL.Scheme = ept.scheme
+			req.URL.Host = ept.host
+			req.Host = ept.host
+		}
+		if timeout, ok := client.timeoutBudget(ctx); ok {
+			req.Header.Set(TimeoutHeader, encodeTimeout(timeout))
+		}
+		res, err := client.do(ctx, req.WithContext(ctx), c)
+		if err != nil && ctx.Err() == context.DeadlineExceeded {
+			err = deadlineError(phaseRoundTrip)
 		}
-		res, err := client.do(ctx, req, c)
 		if done != nil {
 			done(ctx, balancer.DoneInfo{Err: err})
 		}
+		return res, err
+	}
+	send := func(ctx context.Context, req *http.Request) (res *http.Response, err error) {
+		if client.opts.hedgeDelay > 0 && client.opts.hedgeExtra > 0 && idempotent(req.Method) {
+			res, err = client.hedge(ctx, req, attempt)
+		} else {
+			res, err = attempt(ctx, req, nil)
+		}
+		// the hedged attempts run concurrently, so the call options only see the response of the winner.
 		if res != nil {
 			cs := csAttempt{res: res}
 			for _, o := range opts {
 				o.after(&c, &cs)
 			}
 		}
+		return res, err
+	}
+	h := func(ctx context.Context, in interface{}) (interface{}, error) {
+		res, err := send(ctx, req)
+		if rc, ok := retryConfig(ctx, client.opts.retry); ok && rc.allowMethod(req.Method) {
+			for i := 1; i < rc.Attempts && err != nil && rc.retryable(ctx, err); i++ {
+				if req.GetBody == nil && req.Body != nil {
+					break
+				}
+				if !rc.wait(ctx, i) {
+					break
+				}
+				if req.GetBody != nil {
+					if req.Body, err = req.GetBody(); err != nil {
+						return nil, err
+					}
+				}
+				res, err = send(ctx, req)
+			}
+		}
 		if err != nil {
 			return nil, err
 		}
 		defer res.Body.Close()
 		if err := client.opts.decoder(ctx, res, reply); err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				return nil, deadlineError(phaseDecode)
+			}
 			return nil, err
 		}
 		return reply, nil
@@ -282,8 +584,27 @@ func (client *Client) invoke(ctx context.Context, req *http.Request, args interf
 	return err
 }
 
+// withTimeout returns the context bounded by the client timeout, the earlier deadline of ctx is kept.
+func (client *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if client.opts.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, client.opts.timeout)
+}
+
+// timeoutBudget returns the remaining timeout of the call, which is propagated to the server.
+func (client *Client) timeoutBudget(ctx context.Context) (time.Duration, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(deadline), true
+}
+
 // Do send an HTTP request and decodes the body of response into target.
 // returns an error (of type *Error) if the response status code is not 2xx.
+// The call is bounded by the context of the request, and by the timeout only if it is set by WithTimeout,
+// since the body of the response is read by the caller, e.g. as a stream.
 func (client *Client) Do(req *http.Request, opts ...CallOption) (*http.Response, error) {
 	c := defaultCallInfo(req.URL.Path)
 	for _, o := range opts {
@@ -291,10 +612,28 @@ func (client *Client) Do(req *http.Request, opts ...CallOption) (*http.Response,
 			return nil, err
 		}
 	}
-	return client.do(req.Context(), req, c)
+	if !client.opts.timeoutSet {
+		return client.do(req.Context(), req, c)
+	}
+	ctx, cancel := client.withTimeout(req.Context())
+	res, err := client.do(ctx, req.WithContext(ctx), c)
+	if err != nil {
+		cancel()
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, deadlineError(phaseRoundTrip)
+		}
+		return nil, err
+	}
+	res.Body = &cancelBody{ReadCloser: res.Body, cancel: cancel}
+	return res, nil
 }
 
 func (client *Client) do(ctx context.Context, req *http.Request, c callInfo) (*http.Response, error) {
+	for _, in := range client.opts.interceptors {
+		if err := in(req); err != nil {
+			return nil, err
+		}
+	}
 	resp, err := client.cc.Do(req)
 	if err != nil {
 		return nil, err
@@ -305,6 +644,15 @@ func (client *Client) do(ctx context.Context, req *http.Request, c callInfo) (*h
 	return resp, nil
 }
 
+// ResolvedNodes returns a copy of the nodes currently resolved by discovery,
+// it returns nil if the client does not use discovery.
+func (client *Client) ResolvedNodes() []*registry.ServiceInstance {
+	if client.r != nil {
+		return client.r.Nodes()
+	}
+	return nil
+}
+
 // Close tears down the Transport and all underlying connections.
 func (client *Client) Close() error {
 	if client.r != nil {
@@ -313,6 +661,14 @@ func (client *Client) Close() error {
 	return nil
 }
 
+// CloseContext is like Close but bounds the wait of the resolver by the context.
+func (client *Client) CloseContext(ctx context.Context) error {
+	if client.r != nil {
+		return client.r.CloseContext(ctx)
+	}
+	return nil
+}
+
 // DefaultRequestEncoder is an HTTP request encoder.
 func DefaultRequestEncoder(ctx context.Context, contentType string, in interface{}) ([]byte, error) {
 	name := httputil.ContentSubtype(contentType)
@@ -5,14 +5,18 @@ import (
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	stderrors "errors"
 	"io/ioutil"
 	nethttp "net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/transport/http/balancer"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -46,6 +50,79 @@ func TestWithBlock(t *testing.T) {
 	assert.True(t, co.block)
 }
 
+func TestWithResolverBackoff(t *testing.T) {
+	o := &clientOptions{}
+	WithResolverBackoff(func(attempt int) time.Duration { return time.Duration(attempt) * time.Second })(o)
+	assert.Equal(t, 2*time.Second, o.backoff(2))
+}
+
+func TestWithResolverKeepOnEmpty(t *testing.T) {
+	o := &clientOptions{keepOnEmpty: true}
+	WithResolverKeepOnEmpty(false)(o)
+	assert.False(t, o.keepOnEmpty)
+}
+
+func TestWithResolverDedup(t *testing.T) {
+	o := &clientOptions{dedup: true}
+	WithResolverDedup(false)(o)
+	assert.False(t, o.dedup)
+}
+
+func TestWithResolverObserver(t *testing.T) {
+	o := &clientOptions{}
+	WithResolverObserver(func(old, new []*registry.ServiceInstance) {})(o)
+	assert.NotNil(t, o.observer)
+}
+
+func TestWithResolverCloseTimeout(t *testing.T) {
+	o := &clientOptions{}
+	WithResolverCloseTimeout(time.Second)(o)
+	assert.Equal(t, time.Second, o.closeTimeout)
+}
+
+func TestWithSubset(t *testing.T) {
+	o := &clientOptions{}
+	WithSubset(10)(o)
+	assert.Equal(t, 10, o.subsetSize)
+}
+
+func TestWithDNSRefreshInterval(t *testing.T) {
+	o := &clientOptions{}
+	WithDNSRefreshInterval(time.Minute)(o)
+	assert.Equal(t, time.Minute, o.dnsInterval)
+}
+
+func TestWithResolverFilter(t *testing.T) {
+	o := &clientOptions{}
+	WithResolverFilter(func(*registry.ServiceInstance) bool { return true })(o)
+	assert.NotNil(t, o.filter)
+}
+
+func TestWithResolverSchemes(t *testing.T) {
+	o := &clientOptions{}
+	WithResolverSchemes("https", "http")(o)
+	assert.Equal(t, []string{"https", "http"}, o.schemes)
+}
+
+func TestWithResolverBlockTimeout(t *testing.T) {
+	o := &clientOptions{}
+	WithResolverBlockTimeout(time.Second)(o)
+	assert.Equal(t, time.Second, o.blockTimeout)
+}
+
+func TestWithResolverMetrics(t *testing.T) {
+	o := &clientOptions{}
+	c := &mockCounter{}
+	WithResolverMetrics(c)(o)
+	assert.Equal(t, c, o.resolverMetrics)
+}
+
+func TestWithLogger(t *testing.T) {
+	o := &clientOptions{}
+	WithLogger(log.DefaultLogger)(o)
+	assert.Equal(t, log.DefaultLogger, o.logger)
+}
+
 func TestWithBalancer(t *testing.T) {
 
 }
@@ -58,6 +135,34 @@ func TestWithTLSConfig(t *testing.T) {
 	assert.Same(t, ov, co.tlsConf)
 }
 
+func TestWithConnPool(t *testing.T) {
+	client, err := NewClient(context.Background(),
+		WithMaxIdleConns(200),
+		WithMaxIdleConnsPerHost(100),
+		WithMaxConnsPerHost(300),
+		WithIdleConnTimeout(time.Minute),
+	)
+	assert.NoError(t, err)
+	tr, ok := client.cc.Transport.(*nethttp.Transport)
+	assert.True(t, ok)
+	assert.NotSame(t, nethttp.DefaultTransport, tr)
+	assert.Equal(t, 200, tr.MaxIdleConns)
+	assert.Equal(t, 100, tr.MaxIdleConnsPerHost)
+	assert.Equal(t, 300, tr.MaxConnsPerHost)
+	assert.Equal(t, time.Minute, tr.IdleConnTimeout)
+	assert.Equal(t, 0, nethttp.DefaultTransport.(*nethttp.Transport).MaxIdleConnsPerHost)
+
+	custom := &nethttp.Transport{}
+	client, err = NewClient(context.Background(), WithTransport(custom), WithMaxIdleConnsPerHost(10))
+	assert.NoError(t, err)
+	assert.Same(t, custom, client.cc.Transport)
+	assert.Equal(t, 10, custom.MaxIdleConnsPerHost)
+
+	client, err = NewClient(context.Background())
+	assert.NoError(t, err)
+	assert.Same(t, nethttp.DefaultTransport, client.cc.Transport)
+}
+
 func TestWithUserAgent(t *testing.T) {
 	ov := "kratos"
 	o := WithUserAgent(ov)
@@ -75,6 +180,38 @@ func TestWithMiddleware(t *testing.T) {
 	assert.Equal(t, v, o.middleware)
 }
 
+func TestWithRequestInterceptor(t *testing.T) {
+	ts := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"signature":"` + r.Header.Get("X-Signature") + `"}`))
+	}))
+	defer ts.Close()
+	var calls []string
+	sign := func(req *nethttp.Request) error {
+		calls = append(calls, "sign")
+		req.Header.Set("X-Signature", req.Method+" "+req.URL.Path)
+		return nil
+	}
+	record := func(req *nethttp.Request) error {
+		calls = append(calls, "record:"+req.Header.Get("X-Signature"))
+		return nil
+	}
+	client, err := NewClient(context.Background(), WithEndpoint(ts.URL), WithRequestInterceptor(sign), WithRequestInterceptor(record))
+	assert.NoError(t, err)
+	reply := map[string]string{}
+	assert.NoError(t, client.Invoke(context.Background(), nethttp.MethodGet, "/hello", nil, &reply))
+	assert.Equal(t, "GET /hello", reply["signature"])
+	assert.Equal(t, []string{"sign", "record:GET /hello"}, calls)
+
+	errSign := stderrors.New("no credentials")
+	client, err = NewClient(context.Background(), WithEndpoint(ts.URL), WithRequestInterceptor(func(*nethttp.Request) error {
+		return errSign
+	}))
+	assert.NoError(t, err)
+	err = client.Invoke(context.Background(), nethttp.MethodGet, "/hello", nil, &reply)
+	assert.True(t, stderrors.Is(err, errSign))
+}
+
 func TestWithEndpoint(t *testing.T) {
 	ov := "some-endpoint"
 	o := WithEndpoint(ov)
@@ -125,6 +262,12 @@ func TestWithDiscovery(t *testing.T) {
 	assert.Equal(t, co.discovery, ov)
 }
 
+func TestNewFilterContext(t *testing.T) {
+	ctx := NewFilterContext(context.Background(), func(*registry.ServiceInstance) bool { return false })
+	nodes := balancer.FilterNodes(ctx, []*registry.ServiceInstance{{ID: "1"}})
+	assert.Empty(t, nodes)
+}
+
 func TestDefaultRequestEncoder(t *testing.T) {
 	req1 := &nethttp.Request{
 		Header: make(nethttp.Header),
@@ -3,6 +3,9 @@ package http
 import (
 	"io/ioutil"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/go-kratos/kratos/v2/encoding"
 	"github.com/go-kratos/kratos/v2/errors"
@@ -29,6 +32,9 @@ func DefaultRequestDecoder(r *http.Request, v interface{}) error {
 	}
 	data, err := ioutil.ReadAll(r.Body)
 	if err != nil {
+		if se := new(errors.Error); errors.As(err, &se) {
+			return se
+		}
 		return errors.BadRequest("CODEC", err.Error())
 	}
 	if err = codec.Unmarshal(data, v); err != nil {
@@ -66,6 +72,46 @@ func DefaultErrorEncoder(w http.ResponseWriter, r *http.Request, err error) {
 	_, _ = w.Write(body)
 }
 
+// LocalizedErrorEncoder returns the error encoder which localizes the error messages by the catalog,
+// the locale of the request context takes precedence over the Accept-Language header.
+func LocalizedErrorEncoder(c errors.Catalog) EncodeErrorFunc {
+	return func(w http.ResponseWriter, r *http.Request, err error) {
+		var locales []string
+		if locale, ok := errors.LocaleFromContext(r.Context()); ok {
+			locales = append(locales, locale)
+		}
+		locales = append(locales, acceptLanguages(r.Header.Get("Accept-Language"))...)
+		DefaultErrorEncoder(w, r, errors.Localize(errors.FromError(err), c, locales...))
+	}
+}
+
+// acceptLanguages returns the languages of the Accept-Language header in the order of the quality.
+func acceptLanguages(header string) []string {
+	type language struct {
+		tag string
+		q   float64
+	}
+	var langs []language
+	for _, part := range strings.Split(header, ",") {
+		tag, q := strings.TrimSpace(part), 1.0
+		if i := strings.IndexByte(tag, ';'); i >= 0 {
+			if v, err := strconv.ParseFloat(strings.TrimPrefix(strings.TrimSpace(tag[i+1:]), "q="), 64); err == nil {
+				q = v
+			}
+			tag = strings.TrimSpace(tag[:i])
+		}
+		if tag != "" && tag != "*" && q > 0 {
+			langs = append(langs, language{tag: tag, q: q})
+		}
+	}
+	sort.SliceStable(langs, func(i, j int) bool { return langs[i].q > langs[j].q })
+	tags := make([]string, 0, len(langs))
+	for _, l := range langs {
+		tags = append(tags, l.tag)
+	}
+	return tags
+}
+
 // CodecForRequest get encoding.Codec via http.Request
 func CodecForRequest(r *http.Request, name string) (encoding.Codec, bool) {
 	for _, accept := range r.Header[name] {
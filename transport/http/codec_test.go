@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"io/ioutil"
 	nethttp "net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/go-kratos/kratos/v2/errors"
@@ -101,3 +102,32 @@ func TestCodecForRequest(t *testing.T) {
 	assert.False(t, ok)
 	assert.Equal(t, "json", c.Name())
 }
+
+func TestLocalizedErrorEncoder(t *testing.T) {
+	enc := LocalizedErrorEncoder(errors.MapCatalog{
+		"en": {"USER_NOT_FOUND": "user {id} not found"},
+		"zh": {"USER_NOT_FOUND": "用户 {id} 不存在"},
+	})
+	err := errors.NotFound("USER_NOT_FOUND", "raw").WithMetadata(map[string]string{"id": "1"})
+
+	req := httptest.NewRequest(nethttp.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "fr;q=0.9, en;q=0.5, zh-CN")
+	w := httptest.NewRecorder()
+	enc(w, req, err)
+	assert.Equal(t, nethttp.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), `"message":"用户 1 不存在"`)
+
+	// the locale of the context takes precedence.
+	w = httptest.NewRecorder()
+	enc(w, req.WithContext(errors.NewLocaleContext(req.Context(), "en")), err)
+	assert.Contains(t, w.Body.String(), `"message":"user 1 not found"`)
+
+	w = httptest.NewRecorder()
+	enc(w, httptest.NewRequest(nethttp.MethodGet, "/", nil), err)
+	assert.Contains(t, w.Body.String(), `"message":"raw"`)
+}
+
+func TestAcceptLanguages(t *testing.T) {
+	assert.Equal(t, []string{"zh-CN", "fr", "en"}, acceptLanguages("fr;q=0.9, en;q=0.5, zh-CN, *;q=0.1, de;q=0"))
+	assert.Empty(t, acceptLanguages(""))
+}
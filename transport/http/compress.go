@@ -0,0 +1,261 @@
+package http
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// compressedTypes are the prefixes of the content types which are already compressed.
+var compressedTypes = []string{
+	"image/", "video/", "audio/", "font/woff",
+	"application/zip", "application/gzip", "application/x-gzip", "application/x-bzip2",
+	"application/x-xz", "application/x-7z-compressed", "application/x-rar-compressed", "application/zstd",
+}
+
+// Compression with the response compression by the Accept-Encoding of the request, either gzip or deflate.
+// The responses smaller than minSize bytes are not compressed, and level is a compress/flate level.
+// The streaming responses are compressed once they are flushed, and the upgraded connections are not compressed.
+func Compression(minSize, level int) ServerOption {
+	return func(o *Server) {
+		o.compressor = newCompressor(minSize, level)
+	}
+}
+
+type compressor struct {
+	minSize int
+	level   int
+	gzip    sync.Pool
+	flate   sync.Pool
+}
+
+func newCompressor(minSize, level int) *compressor {
+	if level < flate.HuffmanOnly || level > flate.BestCompression {
+		level = flate.DefaultCompression
+	}
+	c := &compressor{minSize: minSize, level: level}
+	c.gzip.New = func() interface{} {
+		w, _ := gzip.NewWriterLevel(nil, c.level)
+		return w
+	}
+	c.flate.New = func() interface{} {
+		w, _ := flate.NewWriter(nil, c.level)
+		return w
+	}
+	return c
+}
+
+func (c *compressor) filter(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if upgrading(req) {
+			// the upgraded connections are hijacked from the writer.
+			next.ServeHTTP(w, req)
+			return
+		}
+		cw := &compressWriter{
+			ResponseWriter: w,
+			c:              c,
+			encoding:       acceptEncoding(req.Header.Get("Accept-Encoding")),
+			head:           req.Method == http.MethodHead,
+		}
+		defer cw.close()
+		next.ServeHTTP(cw, req)
+	})
+}
+
+// compressWriter buffers the response until it is decided whether to compress,
+// which is once minSize bytes are written, the response is flushed, or the handler returns.
+type compressWriter struct {
+	http.ResponseWriter
+	c        *compressor
+	encoding string
+	head     bool
+	status   int
+	buf      []byte
+	decided  bool
+	// cw is nil unless the response is compressed.
+	cw compressResetWriter
+}
+
+type compressResetWriter interface {
+	io.WriteCloser
+	Flush() error
+	Reset(io.Writer)
+}
+
+func (w *compressWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if w.decided {
+		if w.cw != nil {
+			return w.cw.Write(p)
+		}
+		return w.ResponseWriter.Write(p)
+	}
+	w.buf = append(w.buf, p...)
+	if len(w.buf) >= w.c.minSize {
+		w.decide(true)
+		if err := w.writeBuffer(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush compresses the response regardless of its size, since the streaming responses are unbounded.
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide(true)
+		if err := w.writeBuffer(); err != nil {
+			return
+		}
+	}
+	if w.cw != nil {
+		if err := w.cw.Flush(); err != nil {
+			return
+		}
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *compressWriter) close() {
+	if !w.decided {
+		w.decide(len(w.buf) >= w.c.minSize)
+		_ = w.writeBuffer()
+	}
+	if w.cw != nil {
+		_ = w.cw.Close()
+		w.cw.Reset(nil)
+		if w.encoding == "gzip" {
+			w.c.gzip.Put(w.cw)
+		} else {
+			w.c.flate.Put(w.cw)
+		}
+		w.cw = nil
+	}
+}
+
+// decide writes the header, the response is compressed if compress is true and the client accepts it.
+func (w *compressWriter) decide(compress bool) {
+	w.decided = true
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	h := w.Header()
+	if h.Get("Content-Type") == "" && len(w.buf) > 0 {
+		// the content type is sniffed here, which the server can't do from the compressed body.
+		h.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+	if compressible(h, w.status) {
+		if !hasToken(h.Values("Vary"), "Accept-Encoding") {
+			h.Add("Vary", "Accept-Encoding")
+		}
+		if compress && w.encoding != "" && !w.head {
+			h.Set("Content-Encoding", w.encoding)
+			h.Del("Content-Length")
+			if w.encoding == "gzip" {
+				w.cw = w.c.gzip.Get().(*gzip.Writer)
+			} else {
+				w.cw = w.c.flate.Get().(*flate.Writer)
+			}
+			w.cw.Reset(w.ResponseWriter)
+		}
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+}
+
+func (w *compressWriter) writeBuffer() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	buf := w.buf
+	w.buf = nil
+	var err error
+	if w.cw != nil {
+		_, err = w.cw.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// compressible reports whether the response may be compressed.
+func compressible(h http.Header, status int) bool {
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		return false
+	}
+	if h.Get("Content-Encoding") != "" {
+		return false
+	}
+	contentType := strings.ToLower(h.Get("Content-Type"))
+	if strings.HasPrefix(contentType, "image/svg") {
+		return true
+	}
+	for _, prefix := range compressedTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// acceptEncoding returns the preferred encoding of the Accept-Encoding header, gzip is preferred over deflate
+// for the same quality, and it is empty if neither is accepted.
+func acceptEncoding(header string) string {
+	var (
+		encoding  string
+		best      float64
+		qualities = make(map[string]float64)
+	)
+	for _, part := range strings.Split(header, ",") {
+		name, q := strings.TrimSpace(part), 1.0
+		if i := strings.IndexByte(name, ';'); i >= 0 {
+			params := strings.TrimSpace(name[i+1:])
+			name = strings.TrimSpace(name[:i])
+			if strings.HasPrefix(params, "q=") {
+				if v, err := strconv.ParseFloat(params[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		qualities[strings.ToLower(name)] = q
+	}
+	for _, name := range []string{"gzip", "deflate"} {
+		q, ok := qualities[name]
+		if !ok {
+			if q, ok = qualities["*"]; !ok {
+				continue
+			}
+		}
+		if q > best {
+			encoding, best = name, q
+		}
+	}
+	return encoding
+}
+
+// upgrading reports whether the request upgrades the connection, e.g. to a WebSocket.
+func upgrading(req *http.Request) bool {
+	return hasToken(req.Header.Values("Connection"), "upgrade")
+}
+
+func hasToken(values []string, token string) bool {
+	for _, v := range values {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
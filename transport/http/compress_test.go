@@ -0,0 +1,137 @@
+package http
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcceptEncoding(t *testing.T) {
+	tests := map[string]string{
+		"":                             "",
+		"gzip":                         "gzip",
+		"deflate, gzip":                "gzip",
+		"gzip;q=0.5, deflate":          "deflate",
+		"gzip;q=0, deflate;q=0":        "",
+		"*":                            "gzip",
+		"br, *;q=0.1, gzip;q=0":        "deflate",
+		"identity":                     "",
+		" GZIP ; q=0.8, deflate;q=0.9": "deflate",
+	}
+	for header, want := range tests {
+		assert.Equal(t, want, acceptEncoding(header), header)
+	}
+}
+
+func serveCompressed(h http.HandlerFunc, minSize int, acceptEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+	w := httptest.NewRecorder()
+	newCompressor(minSize, flate.BestSpeed).filter(h).ServeHTTP(w, req)
+	return w
+}
+
+func TestCompression(t *testing.T) {
+	body := strings.Repeat(`{"hello":"kratos"}`, 100)
+	h := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Length", "1800")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(body[:10]))
+		_, _ = w.Write([]byte(body[10:]))
+	}
+
+	w := serveCompressed(h, 1024, "gzip, deflate")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+	assert.Empty(t, w.Header().Get("Content-Length"))
+	gr, err := gzip.NewReader(w.Body)
+	assert.NoError(t, err)
+	data, err := ioutil.ReadAll(gr)
+	assert.NoError(t, err)
+	assert.Equal(t, body, string(data))
+
+	w = serveCompressed(h, 1024, "deflate")
+	assert.Equal(t, "deflate", w.Header().Get("Content-Encoding"))
+	data, err = ioutil.ReadAll(flate.NewReader(w.Body))
+	assert.NoError(t, err)
+	assert.Equal(t, body, string(data))
+
+	// too small to compress, or not accepted.
+	for _, w := range []*httptest.ResponseRecorder{serveCompressed(h, 4096, "gzip"), serveCompressed(h, 10, "")} {
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+		assert.Equal(t, body, w.Body.String())
+	}
+}
+
+func TestCompressionSkipped(t *testing.T) {
+	body := strings.Repeat("x", 100)
+	for _, header := range []http.Header{
+		{"Content-Type": {"image/png"}},
+		{"Content-Type": {"application/json"}, "Content-Encoding": {"br"}},
+	} {
+		header := header
+		w := serveCompressed(func(w http.ResponseWriter, r *http.Request) {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			_, _ = w.Write([]byte(body))
+		}, 10, "gzip")
+		assert.Equal(t, header.Get("Content-Encoding"), w.Header().Get("Content-Encoding"))
+		assert.Empty(t, w.Header().Get("Vary"))
+		assert.Equal(t, body, w.Body.String())
+	}
+
+	w := serveCompressed(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}, 0, "gzip")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+}
+
+func TestCompressionFlush(t *testing.T) {
+	w := serveCompressed(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: 1\n\n"))
+		w.(http.Flusher).Flush()
+		// the flushed chunk is decodable before the stream ends.
+		gr, err := gzip.NewReader(strings.NewReader(w.(*compressWriter).ResponseWriter.(*httptest.ResponseRecorder).Body.String()))
+		assert.NoError(t, err)
+		chunk := make([]byte, 9)
+		_, err = gr.Read(chunk)
+		assert.NoError(t, err)
+		assert.Equal(t, "data: 1\n\n", string(chunk))
+		_, _ = w.Write([]byte("data: 2\n\n"))
+	}, 1024, "gzip")
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.True(t, w.Flushed)
+	gr, err := gzip.NewReader(w.Body)
+	assert.NoError(t, err)
+	data, err := ioutil.ReadAll(gr)
+	assert.NoError(t, err)
+	assert.Equal(t, "data: 1\n\ndata: 2\n\n", string(data))
+}
+
+func TestServerCompression(t *testing.T) {
+	srv := NewServer(Compression(16, gzip.DefaultCompression))
+	_, err := srv.Endpoint()
+	assert.NoError(t, err)
+	defer srv.lis.Close()
+	srv.HandleFunc("/index", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("kratos", 10)))
+	})
+	req := httptest.NewRequest(http.MethodGet, "/index", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, req)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+}
@@ -41,20 +41,34 @@ type Context interface {
 }
 
 type responseWriter struct {
-	code int
-	w    http.ResponseWriter
+	code        int
+	wroteHeader bool
+	w           http.ResponseWriter
 }
 
 func (w *responseWriter) rest(res http.ResponseWriter) {
 	w.w = res
 	w.code = http.StatusOK
+	w.wroteHeader = false
 }
 func (w *responseWriter) Header() http.Header        { return w.w.Header() }
 func (w *responseWriter) WriteHeader(statusCode int) { w.code = statusCode }
 func (w *responseWriter) Write(data []byte) (int, error) {
-	w.w.WriteHeader(w.code)
+	w.writeHeader()
 	return w.w.Write(data)
 }
+func (w *responseWriter) Flush() {
+	w.writeHeader()
+	if f, ok := w.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+func (w *responseWriter) writeHeader() {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.w.WriteHeader(w.code)
+	}
+}
 
 type wrapper struct {
 	router *Router
@@ -87,7 +101,11 @@ func (c *wrapper) Query() url.Values {
 func (c *wrapper) Request() *http.Request        { return c.req }
 func (c *wrapper) Response() http.ResponseWriter { return c.res }
 func (c *wrapper) Middleware(h middleware.Handler) middleware.Handler {
-	return middleware.Chain(c.router.srv.ms...)(h)
+	ms := c.router.srv.ms
+	if route := routeMiddleware(c.req.Context()); len(route) > 0 {
+		ms = append(ms[:len(ms):len(ms)], route...)
+	}
+	return middleware.Chain(ms...)(h)
 }
 func (c *wrapper) Bind(v interface{}) error      { return c.router.srv.dec(c.req, v) }
 func (c *wrapper) BindVars(v interface{}) error  { return binding.BindQuery(c.Vars(), v) }
@@ -97,11 +115,23 @@ func (c *wrapper) Returns(v interface{}, err error) error {
 	if err != nil {
 		return err
 	}
-	return c.router.srv.enc(&c.w, c.req, v)
+	return c.encode(v)
 }
 
 func (c *wrapper) Result(code int, v interface{}) error {
 	c.w.WriteHeader(code)
+	return c.encode(v)
+}
+
+func (c *wrapper) encode(v interface{}) error {
+	if c.router.srv.streamEnc != nil && isStream(v) {
+		return c.router.srv.streamEnc(&c.w, c.req, v)
+	}
+	if env := c.router.srv.envelope; env != nil {
+		if _, ok := v.(error); !ok {
+			v = env(v)
+		}
+	}
 	return c.router.srv.enc(&c.w, c.req, v)
 }
 
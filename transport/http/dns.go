@@ -0,0 +1,115 @@
+package http
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/internal/endpoint"
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+const defaultDNSRefreshInterval = 30 * time.Second
+
+var _ registry.Discovery = (*dnsDiscovery)(nil)
+
+// dnsDiscovery is a discovery which re-queries the A/AAAA records of a host on an interval.
+type dnsDiscovery struct {
+	interval time.Duration
+	insecure bool
+	lookup   func(ctx context.Context, host string) ([]string, error)
+}
+
+func newDNSDiscovery(interval time.Duration, insecure bool) *dnsDiscovery {
+	if interval <= 0 {
+		interval = defaultDNSRefreshInterval
+	}
+	return &dnsDiscovery{
+		interval: interval,
+		insecure: insecure,
+		lookup:   net.DefaultResolver.LookupHost,
+	}
+}
+
+// GetService resolves the service name which is formatted as host:port.
+func (d *dnsDiscovery) GetService(ctx context.Context, serviceName string) ([]*registry.ServiceInstance, error) {
+	host, port, err := net.SplitHostPort(serviceName)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := d.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	ins := make([]*registry.ServiceInstance, 0, len(addrs))
+	for _, addr := range addrs {
+		hostport := net.JoinHostPort(addr, port)
+		ins = append(ins, &registry.ServiceInstance{
+			ID:        hostport,
+			Name:      host,
+			Endpoints: []string{endpoint.NewEndpoint("http", hostport, !d.insecure).String()},
+		})
+	}
+	return ins, nil
+}
+
+// Watch creates a watcher which resolves the service name on every refresh interval.
+func (d *dnsDiscovery) Watch(ctx context.Context, serviceName string) (registry.Watcher, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &dnsWatcher{
+		d:           d,
+		ctx:         ctx,
+		cancel:      cancel,
+		serviceName: serviceName,
+		now:         true,
+	}, nil
+}
+
+type dnsWatcher struct {
+	d           *dnsDiscovery
+	ctx         context.Context
+	cancel      context.CancelFunc
+	serviceName string
+	now         bool
+}
+
+func (w *dnsWatcher) Next() ([]*registry.ServiceInstance, error) {
+	if !w.now {
+		select {
+		case <-w.ctx.Done():
+			return nil, w.ctx.Err()
+		case <-time.After(w.d.interval):
+		}
+	}
+	ins, err := w.d.GetService(w.ctx, w.serviceName)
+	// retry immediately after the resolver backoff if the lookup failed.
+	w.now = err != nil
+	return ins, err
+}
+
+func (w *dnsWatcher) Stop() error {
+	w.cancel()
+	return nil
+}
+
+// newDNSResolver creates a resolver for dns target, the target falls back to
+// a single static node if the host resolves to exactly one address.
+func newDNSResolver(ctx context.Context, target *Target, insecure bool, opts *clientOptions) (*resolver, error) {
+	d := newDNSDiscovery(opts.dnsInterval, insecure)
+	ins, err := d.GetService(ctx, target.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if len(ins) == 1 {
+		if insecure {
+			target.Scheme = "http"
+		} else {
+			target.Scheme = "https"
+		}
+		target.Authority = target.Endpoint
+		return nil, nil
+	}
+	o := *opts
+	o.discovery = d
+	return newResolver(ctx, target, insecure, &o)
+}
@@ -0,0 +1,41 @@
+package http
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDNSDiscovery(t *testing.T) {
+	d := newDNSDiscovery(time.Millisecond, true)
+	d.lookup = func(ctx context.Context, host string) ([]string, error) {
+		return []string{"10.0.0.1", "10.0.0.2"}, nil
+	}
+	w, err := d.Watch(context.Background(), "demo.svc:8000")
+	assert.NoError(t, err)
+	ins, err := w.Next()
+	assert.NoError(t, err)
+	assert.Len(t, ins, 2)
+	assert.Equal(t, "10.0.0.1:8000", ins[0].ID)
+	assert.Equal(t, []string{"http://10.0.0.1:8000"}, ins[0].Endpoints)
+
+	ins, err = w.Next()
+	assert.NoError(t, err)
+	assert.Len(t, ins, 2)
+
+	assert.NoError(t, w.Stop())
+	_, err = w.Next()
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestDNSResolverStatic(t *testing.T) {
+	target, err := parseTarget("dns:///127.0.0.1:8000", true)
+	assert.NoError(t, err)
+	r, err := newDNSResolver(context.Background(), target, true, &clientOptions{})
+	assert.NoError(t, err)
+	assert.Nil(t, r)
+	assert.Equal(t, "http", target.Scheme)
+	assert.Equal(t, "127.0.0.1:8000", target.Authority)
+}
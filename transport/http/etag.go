@@ -0,0 +1,116 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// ETag returns a filter which handles the conditional GET and HEAD requests of the routes it filters,
+// e.g. r.GET("/users/{id}", h, ETag()). The 200 responses are buffered and sent with a weak ETag of the body
+// hash unless the handler set one, and a 304 without the body if it matches the If-None-Match of the request.
+// The other responses, the flushed streaming responses and the upgraded connections are sent as is.
+func ETag() FilterFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if (req.Method != http.MethodGet && req.Method != http.MethodHead) || upgrading(req) {
+				next.ServeHTTP(w, req)
+				return
+			}
+			ew := &etagWriter{ResponseWriter: w, match: req.Header.Get("If-None-Match")}
+			next.ServeHTTP(ew, req)
+			ew.close()
+		})
+	}
+}
+
+// etagWriter buffers the response until the handler returns, or it is flushed.
+type etagWriter struct {
+	http.ResponseWriter
+	match  string
+	status int
+	buf    []byte
+	// bypass is true once the response is sent as is.
+	bypass bool
+}
+
+func (w *etagWriter) WriteHeader(status int) {
+	if w.status != 0 {
+		return
+	}
+	w.status = status
+	if status != http.StatusOK {
+		w.bypass = true
+		w.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (w *etagWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.bypass {
+		return w.ResponseWriter.Write(p)
+	}
+	w.buf = append(w.buf, p...)
+	return len(p), nil
+}
+
+// Flush sends the buffered response without the ETag, since the streaming responses are unbounded.
+func (w *etagWriter) Flush() {
+	if !w.bypass {
+		w.bypass = true
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		w.ResponseWriter.WriteHeader(w.status)
+		if len(w.buf) > 0 {
+			if _, err := w.ResponseWriter.Write(w.buf); err != nil {
+				return
+			}
+			w.buf = nil
+		}
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *etagWriter) close() {
+	if w.bypass {
+		return
+	}
+	h := w.Header()
+	etag := h.Get("ETag")
+	if etag == "" {
+		sum := sha256.Sum256(w.buf)
+		etag = `W/"` + hex.EncodeToString(sum[:16]) + `"`
+		h.Set("ETag", etag)
+	}
+	if w.match != "" && etagMatch(w.match, etag) {
+		h.Del("Content-Type")
+		h.Del("Content-Length")
+		w.ResponseWriter.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	if len(w.buf) > 0 {
+		_, _ = w.ResponseWriter.Write(w.buf)
+	}
+}
+
+// etagMatch reports whether the If-None-Match header matches the etag by the weak comparison.
+func etagMatch(header, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || strings.TrimPrefix(v, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
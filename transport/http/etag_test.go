@@ -0,0 +1,97 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEtagMatch(t *testing.T) {
+	tests := map[string]bool{
+		`"a"`:            true,
+		`W/"a"`:          true,
+		`"b", W/"a"`:     true,
+		`*`:              true,
+		`"b"`:            false,
+		`"a-gzip", "b" `: false,
+	}
+	for header, want := range tests {
+		assert.Equal(t, want, etagMatch(header, `W/"a"`), header)
+	}
+}
+
+func TestETag(t *testing.T) {
+	srv := NewServer()
+	_, err := srv.Endpoint()
+	assert.NoError(t, err)
+	defer srv.lis.Close()
+	r := srv.Route("/")
+	r.GET("/users/{id}", func(ctx Context) error {
+		return ctx.Result(200, map[string]string{"id": ctx.Vars().Get("id")})
+	}, ETag())
+	r.GET("/missing", func(ctx Context) error {
+		return errors.NotFound("USER_NOT_FOUND", "user not found")
+	}, ETag())
+	r.GET("/fixed", func(ctx Context) error {
+		ctx.Response().Header().Set("ETag", `"v1"`)
+		return ctx.Result(200, map[string]string{"id": "1"})
+	}, ETag())
+	r.POST("/users/{id}", func(ctx Context) error {
+		return ctx.Result(200, map[string]string{"id": ctx.Vars().Get("id")})
+	}, ETag())
+
+	serve := func(method, path, match string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if match != "" {
+			req.Header.Set("If-None-Match", match)
+		}
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve(http.MethodGet, "/users/1", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"id":"1"}`, w.Body.String())
+	etag := w.Header().Get("ETag")
+	assert.Regexp(t, `^W/"[0-9a-f]{32}"$`, etag)
+
+	w = serve(http.MethodGet, "/users/1", etag)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Equal(t, etag, w.Header().Get("ETag"))
+	assert.Empty(t, w.Header().Get("Content-Type"))
+
+	w = serve(http.MethodGet, "/users/2", etag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+
+	w = serve(http.MethodGet, "/missing", "*")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Empty(t, w.Header().Get("ETag"))
+
+	w = serve(http.MethodGet, "/fixed", `"v1"`)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, `"v1"`, w.Header().Get("ETag"))
+
+	w = serve(http.MethodPost, "/users/1", "*")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("ETag"))
+}
+
+func TestETagFlush(t *testing.T) {
+	h := ETag()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("data: 1\n\n"))
+		w.(http.Flusher).Flush()
+		_, _ = w.Write([]byte("data: 2\n\n"))
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, w.Flushed)
+	assert.Equal(t, "data: 1\n\ndata: 2\n\n", w.Body.String())
+	assert.Empty(t, w.Header().Get("ETag"))
+}
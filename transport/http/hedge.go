@@ -0,0 +1,141 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/transport/http/balancer"
+)
+
+type attemptFunc func(ctx context.Context, req *http.Request, picked func(*registry.ServiceInstance)) (*http.Response, error)
+
+type hedgeResult struct {
+	res   *http.Response
+	err   error
+	index int
+}
+
+// cancelBody cancels the context of the response once the body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// hedge sends the request and the hedged requests to distinct nodes,
+// it returns the first succeeded response or the last error if all of them failed.
+func (client *Client) hedge(ctx context.Context, req *http.Request, attempt attemptFunc) (*http.Response, error) {
+	var (
+		lock   sync.Mutex
+		picked = make(map[string]struct{})
+	)
+	ctx = balancer.NewFilterContext(ctx, func(node *registry.ServiceInstance) bool {
+		lock.Lock()
+		defer lock.Unlock()
+		_, ok := picked[node.ID]
+		return !ok
+	})
+	onPick := func(node *registry.ServiceInstance) {
+		lock.Lock()
+		picked[node.ID] = struct{}{}
+		lock.Unlock()
+	}
+
+	total := client.opts.hedgeExtra + 1
+	results := make(chan hedgeResult, total)
+	cancels := make([]context.CancelFunc, 0, total)
+	launch := func() error {
+		r := req.Clone(ctx)
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return err
+			}
+			r.Body = body
+		}
+		actx, cancel := context.WithCancel(ctx)
+		index := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			res, err := attempt(actx, r, onPick)
+			results <- hedgeResult{res: res, err: err, index: index}
+		}()
+		return nil
+	}
+	if err := launch(); err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(client.opts.hedgeDelay)
+	defer timer.Stop()
+	var (
+		pending = 1
+		lastErr error
+	)
+	for {
+		select {
+		case <-timer.C:
+			if len(cancels) < total {
+				if err := launch(); err != nil {
+					lastErr = err
+				} else {
+					pending++
+				}
+				timer.Reset(client.opts.hedgeDelay)
+			}
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				client.hedgeWon(r, cancels, results, pending)
+				return r.res, nil
+			}
+			cancels[r.index]()
+			lastErr = r.err
+			// an attempt failed, hedge to another node right away.
+			if len(cancels) < total && ctx.Err() == nil {
+				if err := launch(); err == nil {
+					pending++
+				}
+			}
+			if pending == 0 {
+				return nil, lastErr
+			}
+		}
+	}
+}
+
+// hedgeWon cancels the losers and closes their late responses,
+// the winner is canceled once its body is closed.
+func (client *Client) hedgeWon(r hedgeResult, cancels []context.CancelFunc, results <-chan hedgeResult, pending int) {
+	for i, cancel := range cancels {
+		if i != r.index {
+			cancel()
+		}
+	}
+	r.res.Body = &cancelBody{ReadCloser: r.res.Body, cancel: cancels[r.index]}
+	if pending > 0 {
+		go func() {
+			for ; pending > 0; pending-- {
+				if late := <-results; late.res != nil {
+					late.res.Body.Close()
+				}
+			}
+		}()
+	}
+	if client.opts.hedgeMetrics != nil && len(cancels) > 1 {
+		winner := "primary"
+		if r.index > 0 {
+			winner = "hedge"
+		}
+		client.opts.hedgeMetrics.With(client.opts.endpoint, winner).Inc()
+	}
+}
@@ -0,0 +1,96 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/transport/http/balancer"
+	"github.com/stretchr/testify/assert"
+)
+
+// orderedBalancer picks the first node accepted by the filter.
+type orderedBalancer struct {
+	lock  sync.Mutex
+	nodes []*registry.ServiceInstance
+}
+
+func (b *orderedBalancer) Pick(ctx context.Context) (*registry.ServiceInstance, func(context.Context, balancer.DoneInfo), error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	nodes := balancer.FilterNodes(ctx, b.nodes)
+	if len(nodes) == 0 {
+		return nil, nil, context.Canceled
+	}
+	return nodes[0], func(context.Context, balancer.DoneInfo) {}, nil
+}
+
+func (b *orderedBalancer) Update(nodes []*registry.ServiceInstance) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.nodes = nodes
+}
+
+func TestWithHedging(t *testing.T) {
+	o := &clientOptions{}
+	WithHedging(time.Millisecond, 2)(o)
+	c := &mockCounter{}
+	WithHedgingMetrics(c)(o)
+	assert.Equal(t, time.Millisecond, o.hedgeDelay)
+	assert.Equal(t, 2, o.hedgeExtra)
+	assert.Equal(t, c, o.hedgeMetrics)
+}
+
+func TestClientHedging(t *testing.T) {
+	slowCanceled := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			close(slowCanceled)
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer slow.Close()
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Node", "fast")
+		_, _ = w.Write([]byte(`{"a":"fast"}`))
+	}))
+	defer fast.Close()
+
+	w := newMockWatcher()
+	w.push(
+		&registry.ServiceInstance{ID: "slow", Endpoints: []string{slow.URL}},
+		&registry.ServiceInstance{ID: "fast", Endpoints: []string{fast.URL}},
+	)
+	c := &mockCounter{counts: make(map[string]int)}
+	client, err := NewClient(context.Background(),
+		WithEndpoint("discovery:///demo"),
+		WithDiscovery(&mockWatchDiscovery{w: w}),
+		WithBalancer(&orderedBalancer{}),
+		WithBlock(),
+		WithHedging(20*time.Millisecond, 1),
+		WithHedgingMetrics(c),
+	)
+	assert.NoError(t, err)
+	defer client.Close()
+
+	reply := make(map[string]string)
+	var header http.Header
+	start := time.Now()
+	assert.NoError(t, client.Invoke(context.Background(), http.MethodGet, "/", nil, &reply, Header(&header)))
+	assert.Equal(t, "fast", reply["a"])
+	assert.Equal(t, "fast", header.Get("X-Node"))
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+
+	select {
+	case <-slowCanceled:
+	case <-time.After(time.Second):
+		t.Fatal("the losing request was not canceled")
+	}
+	assert.Equal(t, 1, c.counts["discovery:///demo,hedge"])
+}
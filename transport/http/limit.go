@@ -0,0 +1,53 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// PayloadTooLargeReason is the error reason when the request body exceeds the max body size.
+const PayloadTooLargeReason = "PAYLOAD_TOO_LARGE"
+
+func errPayloadTooLarge(limit, size int64) error {
+	return errors.New(http.StatusRequestEntityTooLarge, PayloadTooLargeReason, "request body too large").WithMetadata(map[string]string{
+		"limit": strconv.FormatInt(limit, 10),
+		"size":  strconv.FormatInt(size, 10),
+	})
+}
+
+// maxBodyReader limits the request body, it returns a PAYLOAD_TOO_LARGE error
+// once more than limit bytes are read.
+type maxBodyReader struct {
+	rc    io.ReadCloser
+	limit int64
+	n     int64
+}
+
+func newMaxBodyReader(w http.ResponseWriter, rc io.ReadCloser, limit int64) *maxBodyReader {
+	// read one more byte to detect the body exceeding the limit.
+	return &maxBodyReader{rc: http.MaxBytesReader(w, rc, limit+1), limit: limit}
+}
+
+func (r *maxBodyReader) Read(p []byte) (int, error) {
+	n, err := r.rc.Read(p)
+	r.n += int64(n)
+	if r.n > r.limit {
+		return n, errPayloadTooLarge(r.limit, r.n)
+	}
+	return n, err
+}
+
+func (r *maxBodyReader) Close() error {
+	return r.rc.Close()
+}
+
+// maxBodySize returns the max body size of the operation, zero is unlimited.
+func (s *Server) maxBodySize(operation string) int64 {
+	if size, ok := s.maxBodySizes[operation]; ok {
+		return size
+	}
+	return s.maxBody
+}
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxBodySize(t *testing.T) {
+	o := &Server{}
+	MaxBodySize(10)(o)
+	OperationMaxBodySize(map[string]int64{"/upload": 100})(o)
+	assert.Equal(t, int64(10), o.maxBodySize("/index"))
+	assert.Equal(t, int64(100), o.maxBodySize("/upload"))
+}
+
+func TestServerMaxBodySize(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer(MaxBodySize(16), OperationMaxBodySize(map[string]int64{"/upload": 1024}))
+	handler := func(ctx Context) error {
+		var in map[string]string
+		if err := ctx.Bind(&in); err != nil {
+			return err
+		}
+		return ctx.Result(200, in)
+	}
+	r := srv.Route("/")
+	r.POST("/index", handler)
+	r.POST("/upload", handler)
+	e, err := srv.Endpoint()
+	assert.NoError(t, err)
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	defer srv.Stop(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	large := `{"data":"` + strings.Repeat("a", 32) + `"}`
+	tests := []struct {
+		path string
+		body io.Reader
+		code int
+		size string
+	}{
+		{"/index", strings.NewReader(`{"a":"b"}`), 200, ""},
+		{"/index", strings.NewReader(large), 413, "43"},
+		// the unknown content length is limited while decoding.
+		{"/index", io.MultiReader(strings.NewReader(large)), 413, "17"},
+		{"/upload", bytes.NewBufferString(large), 200, ""},
+	}
+	for _, test := range tests {
+		req, err := http.NewRequest("POST", fmt.Sprintf("http://%s%s", e.Host, test.path), test.body)
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		assert.Equal(t, test.code, resp.StatusCode)
+		if test.code != 200 {
+			se := DefaultErrorDecoder(ctx, resp)
+			assert.Equal(t, PayloadTooLargeReason, errors.Reason(se))
+			assert.Equal(t, "16", errors.FromError(se).Metadata["limit"])
+			assert.Equal(t, test.size, errors.FromError(se).Metadata["size"])
+		}
+		resp.Body.Close()
+	}
+}
@@ -0,0 +1,39 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// unmatched returns the handler which renders the error of an unmatched request by the ErrorEncoder, the default error
+// is rendered if h returns nil, and the path of the request is added to the metadata of the error.
+func (s *Server) unmatched(h, def func(req *http.Request) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var err error
+		if h != nil {
+			err = h(req)
+		}
+		if err == nil {
+			err = def(req)
+		}
+		se := errors.FromError(err)
+		if _, ok := se.Metadata["path"]; !ok {
+			md := make(map[string]string, len(se.Metadata)+1)
+			for k, v := range se.Metadata {
+				md[k] = v
+			}
+			md["path"] = req.URL.Path
+			se = se.WithMetadata(md)
+		}
+		s.ene(w, req, se)
+	})
+}
+
+func defaultNotFound(req *http.Request) error {
+	return errors.NotFound("NOT_FOUND", "the requested path is not found")
+}
+
+func defaultMethodNotAllowed(req *http.Request) error {
+	return errors.New(http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "the requested method is not allowed")
+}
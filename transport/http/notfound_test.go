@@ -0,0 +1,65 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func newUnmatchedServer(t *testing.T, opts ...ServerOption) *Server {
+	srv := NewServer(opts...)
+	_, err := srv.Endpoint()
+	assert.NoError(t, err)
+	srv.Route("/").GET("/users/{id}", func(ctx Context) error {
+		return ctx.Result(200, nil)
+	})
+	return srv
+}
+
+func serveUnmatched(t *testing.T, srv *Server, method, path string) (int, *errors.Error) {
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	se := new(errors.Error)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), se))
+	return w.Code, se
+}
+
+func TestUnmatchedDefault(t *testing.T) {
+	srv := newUnmatchedServer(t)
+	defer srv.lis.Close()
+	code, se := serveUnmatched(t, srv, http.MethodGet, "/missing")
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.Equal(t, "NOT_FOUND", se.Reason)
+	assert.Equal(t, map[string]string{"path": "/missing"}, se.Metadata)
+
+	code, se = serveUnmatched(t, srv, http.MethodPost, "/users/1")
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+	assert.Equal(t, "METHOD_NOT_ALLOWED", se.Reason)
+	assert.Equal(t, map[string]string{"path": "/users/1"}, se.Metadata)
+}
+
+func TestUnmatchedHandler(t *testing.T) {
+	srv := newUnmatchedServer(t,
+		NotFoundHandler(func(req *http.Request) error {
+			return errors.NotFound("ROUTE_NOT_FOUND", "no route").WithMetadata(map[string]string{"method": req.Method})
+		}),
+		MethodNotAllowedHandler(func(req *http.Request) error {
+			return nil
+		}),
+	)
+	defer srv.lis.Close()
+	code, se := serveUnmatched(t, srv, http.MethodGet, "/missing")
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.Equal(t, "ROUTE_NOT_FOUND", se.Reason)
+	assert.Equal(t, map[string]string{"method": "GET", "path": "/missing"}, se.Metadata)
+
+	// the default error is rendered if the handler returns nil.
+	code, se = serveUnmatched(t, srv, http.MethodDelete, "/users/1")
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+	assert.Equal(t, "METHOD_NOT_ALLOWED", se.Reason)
+}
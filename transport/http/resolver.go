@@ -2,17 +2,68 @@ package http
 
 import (
 	"context"
-	"errors"
+	"fmt"
 	"net/url"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/internal/endpoint"
 	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/metrics"
 	"github.com/go-kratos/kratos/v2/registry"
 )
 
+// ResolveTimeoutReason is the error reason when the block mode resolver
+// does not find any node before the context is done.
+const ResolveTimeoutReason = "RESOLVE_TIMEOUT"
+
+// resolver metrics events.
+const (
+	resolverEventUpdate     = "update"
+	resolverEventEmpty      = "empty"
+	resolverEventParseError = "parse_error"
+	resolverEventWatchError = "watch_error"
+)
+
+// BackoffFunc returns the delay before the next watch retry,
+// attempt is the number of consecutive failures starting from 1.
+type BackoffFunc func(attempt int) time.Duration
+
+// ObserverFunc is called with the previous and the current nodes
+// whenever the resolver writes a new node list.
+type ObserverFunc func(old, new []*registry.ServiceInstance)
+
+// NodeFilter reports whether the instance should be surfaced to the balancer.
+type NodeFilter func(*registry.ServiceInstance) bool
+
+// resolveError is a kratos error which keeps the context error as its cause.
+type resolveError struct {
+	err   *errors.Error
+	cause error
+}
+
+func (e *resolveError) Error() string { return e.err.Error() }
+
+func (e *resolveError) Unwrap() error { return e.cause }
+
+func (e *resolveError) Is(target error) bool { return e.err.Is(target) }
+
+func (e *resolveError) As(target interface{}) bool {
+	if se, ok := target.(**errors.Error); ok {
+		*se = e.err
+		return true
+	}
+	return false
+}
+
+func defaultBackoff(attempt int) time.Duration {
+	return time.Second
+}
+
 // Updater is resolver nodes updater
 type Updater interface {
 	Update(nodes []*registry.ServiceInstance)
@@ -41,9 +92,28 @@ func parseTarget(endpoint string, insecure bool) (*Target, error) {
 	if len(u.Path) > 1 {
 		target.Endpoint = u.Path[1:]
 	}
+	// dns://host:port and dns:///host:port are both accepted.
+	if target.Scheme == "dns" && target.Endpoint == "" {
+		target.Endpoint = target.Authority
+	}
+	// unix:///var/run/app.sock is the absolute socket path, and unix://app.sock a relative one.
+	if target.Scheme == "unix" {
+		target.Endpoint = u.Path
+		if target.Endpoint == "" {
+			target.Endpoint = target.Authority
+		}
+	}
 	return target, nil
 }
 
+const defaultDrainKey = "draining"
+
+// parseDraining reports whether the metadata value marks the instance as draining.
+func parseDraining(value string) bool {
+	draining, _ := strconv.ParseBool(value)
+	return draining
+}
+
 type resolver struct {
 	lock    sync.RWMutex
 	nodes   []*registry.ServiceInstance
@@ -53,30 +123,168 @@ type resolver struct {
 	watcher registry.Watcher
 	logger  *log.Helper
 
-	insecure bool
+	insecure    bool
+	keepOnEmpty bool
+	dedup       bool
+	observer    ObserverFunc
+	filter      NodeFilter
+	// drainKey is the metadata key of the draining instances, empty if the draining is disabled.
+	drainKey   string
+	drainParse func(string) bool
+	// schemes is the prioritized list of endpoint schemes.
+	schemes   []string
+	endpoints map[*registry.ServiceInstance]nodeEndpoint
+	// subsetSize is the max number of nodes surfaced to the balancer.
+	subsetSize int
+	clientID   string
+	// counter: http_client_resolver_events_total{endpoint, event}
+	metrics metrics.Counter
+
+	ctx          context.Context
+	cancel       context.CancelFunc
+	wg           sync.WaitGroup
+	closeTimeout time.Duration
+}
+
+type nodeEndpoint struct {
+	scheme string
+	host   string
+}
+
+// parseEndpoint returns the first endpoint which matches the schemes in order,
+// https matches both https:// and http://?isSecure=true endpoints.
+func parseEndpoint(endpoints []string, schemes []string) (nodeEndpoint, error) {
+	for _, scheme := range schemes {
+		var (
+			host string
+			err  error
+		)
+		switch scheme {
+		case "https":
+			if host, err = endpoint.ParseEndpoint(endpoints, "https", false); err == nil && host == "" {
+				host, err = endpoint.ParseEndpoint(endpoints, "http", true)
+			}
+		default:
+			host, err = endpoint.ParseEndpoint(endpoints, scheme, false)
+		}
+		if err != nil {
+			return nodeEndpoint{}, err
+		}
+		if host != "" {
+			return nodeEndpoint{scheme: scheme, host: host}, nil
+		}
+	}
+	return nodeEndpoint{}, nil
+}
+
+// sameNodes reports whether the two node sets are identical by ID, endpoints and metadata,
+// regardless of the order.
+func sameNodes(a, b []*registry.ServiceInstance) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	keys := make(map[string]int, len(a))
+	for _, in := range a {
+		keys[nodeKey(in)]++
+	}
+	for _, in := range b {
+		k := nodeKey(in)
+		if keys[k] == 0 {
+			return false
+		}
+		keys[k]--
+	}
+	return true
+}
+
+func nodeKey(in *registry.ServiceInstance) string {
+	endpoints := append([]string(nil), in.Endpoints...)
+	sort.Strings(endpoints)
+	md := make([]string, 0, len(in.Metadata))
+	for k, v := range in.Metadata {
+		md = append(md, k+"="+v)
+	}
+	sort.Strings(md)
+	return in.ID + "\x00" + strings.Join(endpoints, ",") + "\x00" + strings.Join(md, ",")
+}
+
+func defaultSchemes(insecure bool) []string {
+	if insecure {
+		return []string{"http"}
+	}
+	return []string{"https"}
 }
 
-func newResolver(ctx context.Context, discovery registry.Discovery, target *Target, updater Updater, block, insecure bool) (*resolver, error) {
-	watcher, err := discovery.Watch(ctx, target.Endpoint)
+func newResolver(ctx context.Context, target *Target, insecure bool, opts *clientOptions) (*resolver, error) {
+	backoff := opts.backoff
+	if backoff == nil {
+		backoff = defaultBackoff
+	}
+	logger := opts.logger
+	if logger == nil {
+		logger = log.DefaultLogger
+	}
+	watcher, err := opts.discovery.Watch(ctx, target.Endpoint)
 	if err != nil {
 		return nil, err
 	}
 	r := &resolver{
-		target:   target,
-		watcher:  watcher,
-		logger:   log.NewHelper(log.DefaultLogger),
-		updater:  updater,
-		insecure: insecure,
+		target:       target,
+		watcher:      watcher,
+		logger:       log.NewHelper(logger),
+		updater:      opts.balancer,
+		insecure:     insecure,
+		keepOnEmpty:  opts.keepOnEmpty,
+		dedup:        opts.dedup,
+		observer:     opts.observer,
+		filter:       opts.filter,
+		drainKey:     opts.drainKey,
+		drainParse:   opts.drainParse,
+		schemes:      opts.schemes,
+		metrics:      opts.resolverMetrics,
+		closeTimeout: opts.closeTimeout,
+		subsetSize:   opts.subsetSize,
+		clientID:     opts.subsetClientID,
 	}
-	if block {
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+	if opts.block {
+		blockCtx := ctx
+		if opts.blockTimeout > 0 {
+			var cancel context.CancelFunc
+			blockCtx, cancel = context.WithTimeout(ctx, opts.blockTimeout)
+			defer cancel()
+		}
+		// stop releases the watch context and the watcher of the failed block.
+		stop := func() {
+			r.cancel()
+			if err := watcher.Stop(); err != nil {
+				r.logger.Errorf("failed to http client watch stop: %v", target)
+			}
+		}
 		done := make(chan error, 1)
+		r.wg.Add(1)
 		go func() {
+			defer r.wg.Done()
+			attempt := 0
 			for {
 				services, err := watcher.Next()
 				if err != nil {
-					done <- err
-					return
+					if errors.Is(err, context.Canceled) {
+						done <- err
+						return
+					}
+					attempt++
+					r.inc(resolverEventWatchError)
+					r.logger.Errorf("http client watch service %v got unexpected error:=%v", target, err)
+					select {
+					case <-time.After(backoff(attempt)):
+						continue
+					case <-blockCtx.Done():
+						done <- blockCtx.Err()
+						return
+					}
 				}
+				attempt = 0
 				r.update(services)
 				if len(r.nodes) > 0 {
 					done <- nil
@@ -87,32 +295,39 @@ func newResolver(ctx context.Context, discovery registry.Discovery, target *Targ
 		select {
 		case err := <-done:
 			if err != nil {
-				err := watcher.Stop()
-				if err != nil {
-					r.logger.Errorf("failed to http client watch stop: %v", target)
+				stop()
+				if blockCtx.Err() != nil {
+					return nil, r.timeoutError(ctx, blockCtx)
 				}
 				return nil, err
 			}
-		case <-ctx.Done():
+		case <-blockCtx.Done():
 			r.logger.Errorf("http client watch service %v reaching context deadline!", target)
-			err := watcher.Stop()
-			if err != nil {
-				r.logger.Errorf("failed to http client watch stop: %v", target)
-			}
-			return nil, ctx.Err()
+			stop()
+			return nil, r.timeoutError(ctx, blockCtx)
 		}
 	}
+	r.wg.Add(1)
 	go func() {
+		defer r.wg.Done()
+		attempt := 0
 		for {
 			services, err := watcher.Next()
 			if err != nil {
-				if errors.Is(err, context.Canceled) {
+				if errors.Is(err, context.Canceled) || r.ctx.Err() != nil {
 					return
 				}
+				attempt++
+				r.inc(resolverEventWatchError)
 				r.logger.Errorf("http client watch service %v got unexpected error:=%v", target, err)
-				time.Sleep(time.Second)
-				continue
+				select {
+				case <-time.After(backoff(attempt)):
+					continue
+				case <-r.ctx.Done():
+					return
+				}
 			}
+			attempt = 0
 			r.update(services)
 		}
 	}()
@@ -121,27 +336,146 @@ func newResolver(ctx context.Context, discovery registry.Discovery, target *Targ
 
 func (r *resolver) update(services []*registry.ServiceInstance) {
 	var nodes []*registry.ServiceInstance
+	schemes := r.schemes
+	if len(schemes) == 0 {
+		schemes = defaultSchemes(r.insecure)
+	}
+	endpoints := make(map[*registry.ServiceInstance]nodeEndpoint, len(services))
 	for _, in := range services {
-		ept, err := endpoint.ParseEndpoint(in.Endpoints, "http", !r.insecure)
+		if r.filter != nil && !r.filter(in) {
+			continue
+		}
+		ept, err := parseEndpoint(in.Endpoints, schemes)
 		if err != nil {
+			r.inc(resolverEventParseError)
 			r.logger.Errorf("Failed to parse (%v) discovery endpoint: %v error %v", r.target, in.Endpoints, err)
 			continue
 		}
-		if ept == "" {
+		if ept.host == "" {
+			continue
+		}
+		endpoints[in] = ept
+		// the endpoint of a draining instance is kept for the in-flight requests, but no new one is routed to it.
+		if r.draining(in) {
 			continue
 		}
 		nodes = append(nodes, in)
 	}
-	if len(nodes) != 0 {
-		r.updater.Update(nodes)
-		r.lock.Lock()
-		r.nodes = nodes
-		r.lock.Unlock()
-	} else {
-		r.logger.Warnf("[http resovler]Zero endpoint found,refused to write,ser: %s ins: %v", r.target.Endpoint, nodes)
+	nodes = subset(r.clientID, nodes, r.subsetSize)
+	if len(nodes) == 0 {
+		r.inc(resolverEventEmpty)
+		if r.keepOnEmpty {
+			r.logger.Warnf("[http resovler]Zero endpoint found,refused to write,ser: %s ins: %v", r.target.Endpoint, services)
+			return
+		}
+		r.logger.Infof("[http resovler]Zero endpoint found,clear nodes,ser: %s ins: %v", r.target.Endpoint, services)
+	}
+	if r.dedup {
+		r.lock.RLock()
+		same := sameNodes(r.nodes, nodes)
+		r.lock.RUnlock()
+		if same {
+			return
+		}
+	}
+	r.inc(resolverEventUpdate)
+	r.lock.Lock()
+	old := r.nodes
+	r.nodes = nodes
+	r.endpoints = endpoints
+	r.lock.Unlock()
+	r.updater.Update(nodes)
+	if r.observer != nil {
+		r.observer(old, nodes)
 	}
 }
 
+// draining reports whether the instance is draining.
+func (r *resolver) draining(in *registry.ServiceInstance) bool {
+	if r.drainKey == "" || r.drainParse == nil {
+		return false
+	}
+	value, ok := in.Metadata[r.drainKey]
+	return ok && r.drainParse(value)
+}
+
+// timeoutError returns a resolve timeout error carrying the target and the nodes seen,
+// the "tripped" metadata reports whether the parent context or the block timeout is done.
+func (r *resolver) timeoutError(parent, blockCtx context.Context) error {
+	r.lock.RLock()
+	nodes := len(r.nodes)
+	r.lock.RUnlock()
+	cause, tripped := blockCtx.Err(), "block_timeout"
+	if parent.Err() != nil {
+		cause, tripped = parent.Err(), "context"
+	}
+	return &resolveError{
+		err: errors.ServiceUnavailable(ResolveTimeoutReason, cause.Error()).WithMetadata(map[string]string{
+			"endpoint": r.target.Endpoint,
+			"scheme":   r.target.Scheme,
+			"nodes":    strconv.Itoa(nodes),
+			"tripped":  tripped,
+		}),
+		cause: cause,
+	}
+}
+
+func (r *resolver) inc(event string) {
+	if r.metrics != nil {
+		r.metrics.With(r.target.Endpoint, event).Inc()
+	}
+}
+
+// Nodes returns a copy of the current nodes.
+func (r *resolver) Nodes() []*registry.ServiceInstance {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	nodes := make([]*registry.ServiceInstance, len(r.nodes))
+	copy(nodes, r.nodes)
+	return nodes
+}
+
+// endpoint returns the scheme and host chosen for the node.
+func (r *resolver) endpoint(node *registry.ServiceInstance) (nodeEndpoint, error) {
+	r.lock.RLock()
+	ept, ok := r.endpoints[node]
+	r.lock.RUnlock()
+	if ok {
+		return ept, nil
+	}
+	schemes := r.schemes
+	if len(schemes) == 0 {
+		schemes = defaultSchemes(r.insecure)
+	}
+	return parseEndpoint(node.Endpoints, schemes)
+}
+
+// Close stops the watcher and waits up to the close timeout for the watch goroutines to exit,
+// a non-positive close timeout waits until they exit.
 func (r *resolver) Close() error {
-	return r.watcher.Stop()
+	if r.closeTimeout <= 0 {
+		return r.CloseContext(context.Background())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), r.closeTimeout)
+	defer cancel()
+	return r.CloseContext(ctx)
+}
+
+// CloseContext stops the watcher and waits until the watch goroutines exit or the context is done.
+func (r *resolver) CloseContext(ctx context.Context) error {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	err := r.watcher.Stop()
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("[http resolver] watch service %v not exited: %w", r.target, ctx.Err())
+	}
 }
@@ -1,11 +1,139 @@
 package http
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/metrics"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/transport/http/balancer"
 	"github.com/stretchr/testify/assert"
 )
 
+type watchEvent struct {
+	ins []*registry.ServiceInstance
+	err error
+}
+
+type mockWatcher struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	ch     chan watchEvent
+}
+
+func newMockWatcher() *mockWatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &mockWatcher{
+		ctx:    ctx,
+		cancel: cancel,
+		ch:     make(chan watchEvent, 16),
+	}
+}
+
+func (w *mockWatcher) push(ins ...*registry.ServiceInstance) {
+	w.ch <- watchEvent{ins: ins}
+}
+
+func (w *mockWatcher) fail(err error) {
+	w.ch <- watchEvent{err: err}
+}
+
+func (w *mockWatcher) Next() ([]*registry.ServiceInstance, error) {
+	select {
+	case <-w.ctx.Done():
+		return nil, w.ctx.Err()
+	case e := <-w.ch:
+		return e.ins, e.err
+	}
+}
+
+func (w *mockWatcher) Stop() error {
+	w.cancel()
+	return nil
+}
+
+// stuckWatcher ignores Stop and blocks in Next until released.
+type stuckWatcher struct {
+	release chan struct{}
+}
+
+func (w *stuckWatcher) Next() ([]*registry.ServiceInstance, error) {
+	<-w.release
+	return nil, context.Canceled
+}
+
+func (w *stuckWatcher) Stop() error {
+	return nil
+}
+
+type mockWatchDiscovery struct {
+	w registry.Watcher
+}
+
+func (d *mockWatchDiscovery) GetService(ctx context.Context, serviceName string) ([]*registry.ServiceInstance, error) {
+	return nil, nil
+}
+
+func (d *mockWatchDiscovery) Watch(ctx context.Context, serviceName string) (registry.Watcher, error) {
+	return d.w, nil
+}
+
+type mockCounter struct {
+	lock   sync.Mutex
+	lvs    []string
+	counts map[string]int
+}
+
+func (c *mockCounter) With(lvs ...string) metrics.Counter {
+	return &mockCounter{lvs: lvs, counts: c.counts}
+}
+
+func (c *mockCounter) Inc() {
+	c.Add(1)
+}
+
+func (c *mockCounter) Add(delta float64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.counts[strings.Join(c.lvs, ",")] += int(delta)
+}
+
+type mockBalancer struct {
+	lock    sync.Mutex
+	nodes   []*registry.ServiceInstance
+	updates int
+}
+
+func (b *mockBalancer) Pick(ctx context.Context) (*registry.ServiceInstance, func(context.Context, balancer.DoneInfo), error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if len(b.nodes) == 0 {
+		return nil, nil, errors.New("no instances available")
+	}
+	return b.nodes[0], func(context.Context, balancer.DoneInfo) {}, nil
+}
+
+func (b *mockBalancer) Update(nodes []*registry.ServiceInstance) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.nodes = nodes
+	b.updates++
+}
+
+func (b *mockBalancer) snapshot() ([]*registry.ServiceInstance, int) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.nodes, b.updates
+}
+
 func TestParseTarget(t *testing.T) {
 	target, err := parseTarget("localhost:8000", true)
 	assert.Nil(t, err)
@@ -26,4 +154,367 @@ func TestParseTarget(t *testing.T) {
 	target, err = parseTarget("127.0.0.1:8000", false)
 	assert.Nil(t, err)
 	assert.Equal(t, &Target{Scheme: "https", Authority: "127.0.0.1:8000"}, target)
+
+	target, err = parseTarget("dns://demo.svc:8000", true)
+	assert.Nil(t, err)
+	assert.Equal(t, &Target{Scheme: "dns", Authority: "demo.svc:8000", Endpoint: "demo.svc:8000"}, target)
+
+	target, err = parseTarget("dns:///demo.svc:8000", true)
+	assert.Nil(t, err)
+	assert.Equal(t, &Target{Scheme: "dns", Authority: "", Endpoint: "demo.svc:8000"}, target)
+
+	target, err = parseTarget("unix:///var/run/app.sock", true)
+	assert.Nil(t, err)
+	assert.Equal(t, &Target{Scheme: "unix", Authority: "", Endpoint: "/var/run/app.sock"}, target)
+
+	target, err = parseTarget("unix://app.sock", true)
+	assert.Nil(t, err)
+	assert.Equal(t, &Target{Scheme: "unix", Authority: "app.sock", Endpoint: "app.sock"}, target)
+}
+
+func TestResolverBackoff(t *testing.T) {
+	w := newMockWatcher()
+	var (
+		lock     sync.Mutex
+		attempts []int
+	)
+	backoff := func(attempt int) time.Duration {
+		lock.Lock()
+		attempts = append(attempts, attempt)
+		lock.Unlock()
+		return time.Millisecond
+	}
+	w.fail(errors.New("flap"))
+	w.fail(errors.New("flap"))
+	w.push(&registry.ServiceInstance{ID: "1", Endpoints: []string{"http://127.0.0.1:8000"}})
+
+	target := &Target{Scheme: "discovery", Endpoint: "demo"}
+	opts := &clientOptions{
+		discovery: &mockWatchDiscovery{w: w},
+		balancer:  &mockBalancer{},
+		block:     true,
+		backoff:   backoff,
+	}
+	r, err := newResolver(context.Background(), target, true, opts)
+	assert.NoError(t, err)
+	defer r.Close()
+
+	w.fail(errors.New("flap"))
+	time.Sleep(50 * time.Millisecond)
+	lock.Lock()
+	defer lock.Unlock()
+	assert.Equal(t, []int{1, 2, 1}, attempts)
+}
+
+func TestResolverKeepOnEmpty(t *testing.T) {
+	ins := &registry.ServiceInstance{ID: "1", Endpoints: []string{"http://127.0.0.1:8000"}}
+	target := &Target{Scheme: "discovery", Endpoint: "demo"}
+
+	b := &mockBalancer{}
+	r := &resolver{target: target, updater: b, logger: log.NewHelper(log.DefaultLogger), insecure: true, keepOnEmpty: true}
+	r.update([]*registry.ServiceInstance{ins})
+	r.update(nil)
+	nodes, updates := b.snapshot()
+	assert.Equal(t, []*registry.ServiceInstance{ins}, nodes)
+	assert.Equal(t, 1, updates)
+
+	b = &mockBalancer{}
+	r = &resolver{target: target, updater: b, logger: log.NewHelper(log.DefaultLogger), insecure: true, keepOnEmpty: false}
+	r.update([]*registry.ServiceInstance{ins})
+	r.update(nil)
+	nodes, updates = b.snapshot()
+	assert.Empty(t, nodes)
+	assert.Empty(t, r.nodes)
+	assert.Equal(t, 2, updates)
+}
+
+func TestResolverObserver(t *testing.T) {
+	ins1 := &registry.ServiceInstance{ID: "1", Endpoints: []string{"http://127.0.0.1:8000"}}
+	ins2 := &registry.ServiceInstance{ID: "2", Endpoints: []string{"http://127.0.0.1:8001"}}
+	var olds, news [][]*registry.ServiceInstance
+	r := &resolver{
+		target:      &Target{Scheme: "discovery", Endpoint: "demo"},
+		updater:     &mockBalancer{},
+		logger:      log.NewHelper(log.DefaultLogger),
+		insecure:    true,
+		keepOnEmpty: true,
+		observer: func(old, new []*registry.ServiceInstance) {
+			olds = append(olds, old)
+			news = append(news, new)
+		},
+	}
+	r.update([]*registry.ServiceInstance{ins1})
+	r.update([]*registry.ServiceInstance{ins1, ins2})
+	r.update(nil)
+	assert.Equal(t, [][]*registry.ServiceInstance{nil, {ins1}}, olds)
+	assert.Equal(t, [][]*registry.ServiceInstance{{ins1}, {ins1, ins2}}, news)
+}
+
+func TestResolverFilter(t *testing.T) {
+	canary := &registry.ServiceInstance{ID: "1", Endpoints: []string{"http://127.0.0.1:8000"}, Metadata: map[string]string{"canary": "true"}}
+	stable := &registry.ServiceInstance{ID: "2", Endpoints: []string{"http://127.0.0.1:8001"}}
+	b := &mockBalancer{}
+	r := &resolver{
+		target:      &Target{Scheme: "discovery", Endpoint: "demo"},
+		updater:     b,
+		logger:      log.NewHelper(log.DefaultLogger),
+		insecure:    true,
+		keepOnEmpty: true,
+		filter: func(in *registry.ServiceInstance) bool {
+			return in.Metadata["canary"] == "true"
+		},
+	}
+	r.update([]*registry.ServiceInstance{canary, stable})
+	nodes, _ := b.snapshot()
+	assert.Equal(t, []*registry.ServiceInstance{canary}, nodes)
+
+	r.update([]*registry.ServiceInstance{stable})
+	nodes, updates := b.snapshot()
+	assert.Equal(t, []*registry.ServiceInstance{canary}, nodes)
+	assert.Equal(t, 1, updates)
+}
+
+func TestResolverDraining(t *testing.T) {
+	a := &registry.ServiceInstance{ID: "1", Endpoints: []string{"http://127.0.0.1:8000"}}
+	b := &registry.ServiceInstance{ID: "2", Endpoints: []string{"http://127.0.0.1:8001"}}
+	drainingA := &registry.ServiceInstance{ID: "1", Endpoints: a.Endpoints, Metadata: map[string]string{"draining": "true"}}
+	mb := &mockBalancer{}
+	r := &resolver{
+		target:      &Target{Scheme: "discovery", Endpoint: "demo"},
+		updater:     mb,
+		logger:      log.NewHelper(log.DefaultLogger),
+		insecure:    true,
+		keepOnEmpty: true,
+		dedup:       true,
+		drainKey:    defaultDrainKey,
+		drainParse:  parseDraining,
+	}
+	r.update([]*registry.ServiceInstance{a, b})
+	nodes, _ := mb.snapshot()
+	assert.Equal(t, []*registry.ServiceInstance{a, b}, nodes)
+
+	// the draining instance is removed from the balancer, but its endpoint stays resolvable.
+	r.update([]*registry.ServiceInstance{drainingA, b})
+	nodes, _ = mb.snapshot()
+	assert.Equal(t, []*registry.ServiceInstance{b}, nodes)
+	ept, err := r.endpoint(drainingA)
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:8000", ept.host)
+
+	// the zero endpoint policy applies if all the instances are draining.
+	r.update([]*registry.ServiceInstance{drainingA})
+	nodes, _ = mb.snapshot()
+	assert.Equal(t, []*registry.ServiceInstance{b}, nodes)
+
+	o := &clientOptions{drainKey: defaultDrainKey, drainParse: parseDraining}
+	WithResolverDraining("state", func(v string) bool { return v == "drain" })(o)
+	assert.Equal(t, "state", o.drainKey)
+	r.drainKey, r.drainParse = o.drainKey, o.drainParse
+	assert.False(t, r.draining(drainingA))
+	assert.True(t, r.draining(&registry.ServiceInstance{Metadata: map[string]string{"state": "drain"}}))
+
+	WithResolverDraining("", nil)(o)
+	r.drainKey, r.drainParse = o.drainKey, o.drainParse
+	assert.False(t, r.draining(&registry.ServiceInstance{Metadata: map[string]string{"": "true"}}))
+}
+
+func TestParseEndpoint(t *testing.T) {
+	ept, err := parseEndpoint([]string{"http://127.0.0.1:8000", "https://127.0.0.1:8443"}, []string{"https", "http"})
+	assert.NoError(t, err)
+	assert.Equal(t, nodeEndpoint{scheme: "https", host: "127.0.0.1:8443"}, ept)
+
+	ept, err = parseEndpoint([]string{"http://127.0.0.1:8443?isSecure=true"}, []string{"https", "http"})
+	assert.NoError(t, err)
+	assert.Equal(t, nodeEndpoint{scheme: "https", host: "127.0.0.1:8443"}, ept)
+
+	ept, err = parseEndpoint([]string{"http://127.0.0.1:8000"}, []string{"https", "http"})
+	assert.NoError(t, err)
+	assert.Equal(t, nodeEndpoint{scheme: "http", host: "127.0.0.1:8000"}, ept)
+
+	ept, err = parseEndpoint([]string{"grpc://127.0.0.1:9000"}, []string{"https", "http"})
+	assert.NoError(t, err)
+	assert.Equal(t, nodeEndpoint{}, ept)
+}
+
+func TestResolverSchemes(t *testing.T) {
+	secure := &registry.ServiceInstance{ID: "1", Endpoints: []string{"https://127.0.0.1:8443"}}
+	plain := &registry.ServiceInstance{ID: "2", Endpoints: []string{"http://127.0.0.1:8000"}}
+	b := &mockBalancer{}
+	r := &resolver{
+		target:      &Target{Scheme: "discovery", Endpoint: "demo"},
+		updater:     b,
+		logger:      log.NewHelper(log.DefaultLogger),
+		keepOnEmpty: true,
+		schemes:     []string{"https", "http"},
+	}
+	r.update([]*registry.ServiceInstance{secure, plain})
+	nodes, _ := b.snapshot()
+	assert.Len(t, nodes, 2)
+
+	ept, err := r.endpoint(secure)
+	assert.NoError(t, err)
+	assert.Equal(t, nodeEndpoint{scheme: "https", host: "127.0.0.1:8443"}, ept)
+	ept, err = r.endpoint(plain)
+	assert.NoError(t, err)
+	assert.Equal(t, nodeEndpoint{scheme: "http", host: "127.0.0.1:8000"}, ept)
+}
+
+func TestResolverBlockTimeout(t *testing.T) {
+	w := newMockWatcher()
+	opts := &clientOptions{
+		discovery: &mockWatchDiscovery{w: w},
+		balancer:  &mockBalancer{},
+		block:     true,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := newResolver(ctx, &Target{Scheme: "discovery", Endpoint: "demo"}, true, opts)
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, ResolveTimeoutReason, kerrors.Reason(err))
+	assert.Equal(t, map[string]string{"endpoint": "demo", "scheme": "discovery", "nodes": "0", "tripped": "context"}, kerrors.FromError(err).Metadata)
+
+	opts.discovery = &mockWatchDiscovery{w: newMockWatcher()}
+	opts.blockTimeout = 10 * time.Millisecond
+	_, err = newResolver(context.Background(), &Target{Scheme: "discovery", Endpoint: "demo"}, true, opts)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, "block_timeout", kerrors.FromError(err).Metadata["tripped"])
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	opts.discovery = &mockWatchDiscovery{w: newMockWatcher()}
+	opts.blockTimeout = time.Second
+	_, err = newResolver(ctx, &Target{Scheme: "discovery", Endpoint: "demo"}, true, opts)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, "context", kerrors.FromError(err).Metadata["tripped"])
+}
+
+func TestResolverBlockFailed(t *testing.T) {
+	before := runtime.NumGoroutine()
+	w := newMockWatcher()
+	w.fail(errors.New("unavailable"))
+	opts := &clientOptions{
+		discovery:    &mockWatchDiscovery{w: w},
+		balancer:     &mockBalancer{},
+		block:        true,
+		blockTimeout: 20 * time.Millisecond,
+	}
+	_, err := newResolver(context.Background(), &Target{Scheme: "discovery", Endpoint: "demo"}, true, opts)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	// the watcher of the failed block is stopped, and the watch goroutine exits.
+	assert.Error(t, w.ctx.Err())
+	assert.Eventually(t, func() bool { return runtime.NumGoroutine() <= before }, time.Second, 10*time.Millisecond)
+}
+
+func TestResolverLogger(t *testing.T) {
+	w := newMockWatcher()
+	buf := new(bytes.Buffer)
+	opts := &clientOptions{
+		discovery: &mockWatchDiscovery{w: w},
+		balancer:  &mockBalancer{},
+		logger:    log.With(log.NewStdLogger(buf), "service", "demo"),
+	}
+	r, err := newResolver(context.Background(), &Target{Scheme: "discovery", Endpoint: "demo"}, true, opts)
+	assert.NoError(t, err)
+	defer r.Close()
+	r.update(nil)
+	assert.Contains(t, buf.String(), "service=demo")
+	assert.Contains(t, buf.String(), "Zero endpoint found")
+}
+
+func TestResolverMetrics(t *testing.T) {
+	c := &mockCounter{counts: map[string]int{}}
+	r := &resolver{
+		target:      &Target{Scheme: "discovery", Endpoint: "demo"},
+		updater:     &mockBalancer{},
+		logger:      log.NewHelper(log.DefaultLogger),
+		insecure:    true,
+		keepOnEmpty: true,
+		metrics:     c,
+	}
+	r.update([]*registry.ServiceInstance{{ID: "1", Endpoints: []string{"http://127.0.0.1:8000"}}})
+	r.update([]*registry.ServiceInstance{{ID: "2", Endpoints: []string{"%zz"}}})
+	assert.Equal(t, map[string]int{"demo,update": 1, "demo,parse_error": 1, "demo,empty": 1}, c.counts)
+
+	r.metrics = nil
+	r.update(nil)
+}
+
+func TestResolverDedup(t *testing.T) {
+	ins1 := &registry.ServiceInstance{ID: "1", Endpoints: []string{"http://127.0.0.1:8000"}, Metadata: map[string]string{"zone": "a"}}
+	ins2 := &registry.ServiceInstance{ID: "2", Endpoints: []string{"http://127.0.0.1:8001"}}
+	b := &mockBalancer{}
+	r := &resolver{
+		target:      &Target{Scheme: "discovery", Endpoint: "demo"},
+		updater:     b,
+		logger:      log.NewHelper(log.DefaultLogger),
+		insecure:    true,
+		keepOnEmpty: true,
+		dedup:       true,
+	}
+	r.update([]*registry.ServiceInstance{ins1, ins2})
+	r.update([]*registry.ServiceInstance{
+		{ID: "2", Endpoints: []string{"http://127.0.0.1:8001"}},
+		{ID: "1", Endpoints: []string{"http://127.0.0.1:8000"}, Metadata: map[string]string{"zone": "a"}},
+	})
+	nodes, updates := b.snapshot()
+	assert.Equal(t, 1, updates)
+	assert.Equal(t, []*registry.ServiceInstance{ins1, ins2}, nodes)
+
+	r.update([]*registry.ServiceInstance{
+		{ID: "1", Endpoints: []string{"http://127.0.0.1:8000"}, Metadata: map[string]string{"zone": "b"}},
+		ins2,
+	})
+	_, updates = b.snapshot()
+	assert.Equal(t, 2, updates)
+
+	r.dedup = false
+	r.update([]*registry.ServiceInstance{ins1, ins2})
+	r.update([]*registry.ServiceInstance{ins1, ins2})
+	_, updates = b.snapshot()
+	assert.Equal(t, 4, updates)
+}
+
+func TestResolverClose(t *testing.T) {
+	opts := &clientOptions{
+		discovery:    &mockWatchDiscovery{w: newMockWatcher()},
+		balancer:     &mockBalancer{},
+		closeTimeout: time.Second,
+	}
+	r, err := newResolver(context.Background(), &Target{Scheme: "discovery", Endpoint: "demo"}, true, opts)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Close())
+
+	w := &stuckWatcher{release: make(chan struct{})}
+	opts.discovery = &mockWatchDiscovery{w: w}
+	r, err = newResolver(context.Background(), &Target{Scheme: "discovery", Endpoint: "demo"}, true, opts)
+	assert.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = r.CloseContext(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	close(w.release)
+	assert.NoError(t, r.CloseContext(context.Background()))
+}
+
+func TestResolverNodes(t *testing.T) {
+	ins := &registry.ServiceInstance{ID: "1", Endpoints: []string{"http://127.0.0.1:8000"}}
+	r := &resolver{
+		target:      &Target{Scheme: "discovery", Endpoint: "demo"},
+		updater:     &mockBalancer{},
+		logger:      log.NewHelper(log.DefaultLogger),
+		insecure:    true,
+		keepOnEmpty: true,
+	}
+	assert.Empty(t, r.Nodes())
+	r.update([]*registry.ServiceInstance{ins})
+	nodes := r.Nodes()
+	assert.Equal(t, []*registry.ServiceInstance{ins}, nodes)
+	nodes[0] = nil
+	assert.Equal(t, []*registry.ServiceInstance{ins}, r.Nodes())
+
+	c := &Client{r: r}
+	assert.Equal(t, []*registry.ServiceInstance{ins}, c.ResolvedNodes())
+	assert.Nil(t, (&Client{}).ResolvedNodes())
 }
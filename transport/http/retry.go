@@ -0,0 +1,111 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// RetryConfig is the retry config of the client requests.
+type RetryConfig struct {
+	// Attempts is the max number of attempts including the first one.
+	Attempts int
+	// StatusCodes is the retryable status codes, default is 502, 503 and 504.
+	StatusCodes []int
+	// Backoff returns the delay before the retry attempt, default is no delay.
+	Backoff BackoffFunc
+	// Methods is the retryable methods besides GET, HEAD, PUT and DELETE, e.g. POST.
+	Methods []string
+}
+
+var defaultRetryStatusCodes = []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+type retryKey struct{}
+
+// NewRetryContext returns a new Context that carries the retry config,
+// which overrides the client retry config for calls with the context.
+func NewRetryContext(ctx context.Context, c RetryConfig) context.Context {
+	return context.WithValue(ctx, retryKey{}, c)
+}
+
+// FromRetryContext returns the retry config in ctx if it exists.
+func FromRetryContext(ctx context.Context) (c RetryConfig, ok bool) {
+	c, ok = ctx.Value(retryKey{}).(RetryConfig)
+	return
+}
+
+func retryConfig(ctx context.Context, def *RetryConfig) (RetryConfig, bool) {
+	if c, ok := FromRetryContext(ctx); ok {
+		return c, true
+	}
+	if def != nil {
+		return *def, true
+	}
+	return RetryConfig{}, false
+}
+
+// idempotent reports whether the request method is idempotent.
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// allowMethod reports whether the request method can be retried.
+func (c RetryConfig) allowMethod(method string) bool {
+	if idempotent(method) {
+		return true
+	}
+	for _, m := range c.Methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// retryable reports whether the failed attempt can be retried,
+// the connection errors and the retryable status codes are retried.
+func (c RetryConfig) retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	se := new(errors.Error)
+	if !errors.As(err, &se) {
+		return true
+	}
+	codes := c.StatusCodes
+	if len(codes) == 0 {
+		codes = defaultRetryStatusCodes
+	}
+	for _, code := range codes {
+		if int(se.Code) == code {
+			return true
+		}
+	}
+	return false
+}
+
+// wait waits for the backoff of the attempt, it returns false
+// if the backoff exceeds the deadline of the request.
+func (c RetryConfig) wait(ctx context.Context, attempt int) bool {
+	if c.Backoff == nil {
+		return true
+	}
+	delay := c.Backoff(attempt)
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= delay {
+		return false
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRetryServer(fails int32, code int) (*httptest.Server, *int32) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= fails {
+			w.WriteHeader(code)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"a":"b"}`))
+	}))
+	return srv, &calls
+}
+
+func TestWithRetry(t *testing.T) {
+	o := &clientOptions{}
+	WithRetry(RetryConfig{Attempts: 3})(o)
+	assert.Equal(t, 3, o.retry.Attempts)
+}
+
+func TestRetryConfigContext(t *testing.T) {
+	_, ok := retryConfig(context.Background(), nil)
+	assert.False(t, ok)
+	ctx := NewRetryContext(context.Background(), RetryConfig{Attempts: 2})
+	c, ok := retryConfig(ctx, &RetryConfig{Attempts: 5})
+	assert.True(t, ok)
+	assert.Equal(t, 2, c.Attempts)
+}
+
+func TestRetryConfigRetryable(t *testing.T) {
+	c := RetryConfig{}
+	ctx := context.Background()
+	assert.True(t, c.retryable(ctx, errors.ServiceUnavailable("", "")))
+	assert.False(t, c.retryable(ctx, errors.InternalServer("", "")))
+	assert.True(t, c.retryable(ctx, context.DeadlineExceeded))
+	c.StatusCodes = []int{http.StatusInternalServerError}
+	assert.True(t, c.retryable(ctx, errors.InternalServer("", "")))
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.False(t, c.retryable(canceled, context.Canceled))
+}
+
+func TestClientRetry(t *testing.T) {
+	srv, calls := newRetryServer(2, http.StatusServiceUnavailable)
+	defer srv.Close()
+	client, err := NewClient(context.Background(),
+		WithEndpoint(strings.TrimPrefix(srv.URL, "http://")),
+		WithRetry(RetryConfig{Attempts: 3}),
+	)
+	assert.NoError(t, err)
+
+	reply := make(map[string]string)
+	assert.NoError(t, client.Invoke(context.Background(), http.MethodGet, "/", nil, &reply))
+	assert.Equal(t, "b", reply["a"])
+	assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+
+	// non-idempotent methods are not retried by default.
+	atomic.StoreInt32(calls, 0)
+	err = client.Invoke(context.Background(), http.MethodPost, "/", map[string]string{}, &reply)
+	assert.Equal(t, http.StatusServiceUnavailable, int(errors.FromError(err).Code))
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+
+	// the context config overrides the client config.
+	atomic.StoreInt32(calls, 0)
+	ctx := NewRetryContext(context.Background(), RetryConfig{Attempts: 3, Methods: []string{http.MethodPost}})
+	assert.NoError(t, client.Invoke(ctx, http.MethodPost, "/", map[string]string{}, &reply))
+	assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+}
+
+func TestClientRetryDeadline(t *testing.T) {
+	srv, calls := newRetryServer(10, http.StatusBadGateway)
+	defer srv.Close()
+	client, err := NewClient(context.Background(),
+		WithEndpoint(strings.TrimPrefix(srv.URL, "http://")),
+		WithRetry(RetryConfig{Attempts: 10, Backoff: func(int) time.Duration { return 100 * time.Millisecond }}),
+	)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 250*time.Millisecond)
+	defer cancel()
+	err = client.Invoke(ctx, http.MethodGet, "/", nil, nil)
+	assert.Equal(t, http.StatusBadGateway, int(errors.FromError(err).Code))
+	assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+}
@@ -1,9 +1,12 @@
 package http
 
 import (
+	"context"
 	"net/http"
 	"path"
 	"sync"
+
+	"github.com/go-kratos/kratos/v2/middleware"
 )
 
 // HandlerFunc defines a function to serve HTTP requests.
@@ -29,6 +32,27 @@ func newRouter(prefix string, srv *Server, filters ...FilterFunc) *Router {
 	return r
 }
 
+type routeMiddlewareKey struct{}
+
+// RouteMiddleware returns a filter which attaches the service middleware to the routes it filters,
+// e.g. r.GET("/admin", h, RouteMiddleware(auth)). The server middleware is the outermost, then the
+// middleware of the router groups, and the middleware of the route is the innermost.
+func RouteMiddleware(m ...middleware.Middleware) FilterFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			ms := routeMiddleware(req.Context())
+			ms = append(ms[:len(ms):len(ms)], m...)
+			next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), routeMiddlewareKey{}, ms)))
+		})
+	}
+}
+
+// routeMiddleware returns the route middleware attached to the request context.
+func routeMiddleware(ctx context.Context) []middleware.Middleware {
+	ms, _ := ctx.Value(routeMiddlewareKey{}).([]middleware.Middleware)
+	return ms
+}
+
 // Group returns a new router group.
 func (r *Router) Group(prefix string, filters ...FilterFunc) *Router {
 	var newFilters []FilterFunc
@@ -7,11 +7,13 @@ import (
 	"github.com/stretchr/testify/assert"
 	"log"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/go-kratos/kratos/v2/internal/host"
+	"github.com/go-kratos/kratos/v2/middleware"
 )
 
 type User struct {
@@ -181,3 +183,40 @@ func TestHandle(t *testing.T) {
 	}
 	r.GET("/get", h)
 }
+
+func TestRouteMiddleware(t *testing.T) {
+	var calls []string
+	mw := func(name string) middleware.Middleware {
+		return func(handler middleware.Handler) middleware.Handler {
+			return func(ctx context.Context, req interface{}) (interface{}, error) {
+				calls = append(calls, name)
+				return handler(ctx, req)
+			}
+		}
+	}
+	srv := NewServer(Middleware(mw("global")))
+	_, err := srv.Endpoint()
+	assert.NoError(t, err)
+	defer srv.lis.Close()
+	h := func(ctx Context) error {
+		_, err := ctx.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			calls = append(calls, "handler")
+			return nil, nil
+		})(ctx, nil)
+		return err
+	}
+	r := srv.Route("/", RouteMiddleware(mw("group")))
+	r.GET("/admin", h, RouteMiddleware(mw("route1"), mw("route2")))
+	r.GET("/index", h)
+	srv.Route("/").GET("/plain", h)
+
+	for path, want := range map[string][]string{
+		"/admin": {"global", "group", "route1", "route2", "handler"},
+		"/index": {"global", "group", "handler"},
+		"/plain": {"global", "handler"},
+	} {
+		calls = nil
+		srv.Handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, want, calls, path)
+	}
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"crypto/tls"
 	"errors"
+	"fmt"
 	"github.com/go-kratos/kratos/v2/internal/endpoint"
 	"net"
 	"net/http"
 	"net/url"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-kratos/kratos/v2/internal/host"
@@ -17,6 +19,8 @@ import (
 	"github.com/go-kratos/kratos/v2/transport"
 
 	"github.com/gorilla/mux"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 var _ transport.Server = (*Server)(nil)
@@ -81,6 +85,22 @@ func ResponseEncoder(en EncodeResponseFunc) ServerOption {
 	}
 }
 
+// StreamResponseEncoder with the response encoder of the streaming replies, e.g. DefaultStreamResponseEncoder,
+// it is used for the io.Reader and channel replies, other replies use the ResponseEncoder.
+func StreamResponseEncoder(en EncodeResponseFunc) ServerOption {
+	return func(o *Server) {
+		o.streamEnc = en
+	}
+}
+
+// ResponseEnvelope with the envelope which wraps the replies before they are encoded by the ResponseEncoder,
+// the errors are encoded by the ErrorEncoder and never wrapped.
+func ResponseEnvelope(env func(data interface{}) interface{}) ServerOption {
+	return func(o *Server) {
+		o.envelope = env
+	}
+}
+
 // ErrorEncoder with error encoder.
 func ErrorEncoder(en EncodeErrorFunc) ServerOption {
 	return func(o *Server) {
@@ -102,6 +122,108 @@ func TLSConfig(c *tls.Config) ServerOption {
 	}
 }
 
+// H2C with HTTP/2 cleartext, the server accepts HTTP/2 prior knowledge
+// and HTTP/1.1 connections on the same listener without TLS.
+func H2C(enable bool) ServerOption {
+	return func(o *Server) {
+		o.h2c = enable
+	}
+}
+
+// ShutdownTimeout with the max duration Stop waits for in-flight requests to drain,
+// the remaining requests are abandoned and their connections are closed.
+func ShutdownTimeout(timeout time.Duration) ServerOption {
+	return func(o *Server) {
+		o.shutdownTimeout = timeout
+	}
+}
+
+// MaxBodySize with the max request body size in bytes, the request exceeding it
+// fails with a PAYLOAD_TOO_LARGE error before the body is decoded.
+func MaxBodySize(size int64) ServerOption {
+	return func(o *Server) {
+		o.maxBody = size
+	}
+}
+
+// OperationMaxBodySize with the max request body sizes keyed by operation, e.g. "/v1/upload/{id}",
+// which override the MaxBodySize for the matching routes, zero is unlimited.
+func OperationMaxBodySize(sizes map[string]int64) ServerOption {
+	return func(o *Server) {
+		o.maxBodySizes = sizes
+	}
+}
+
+// ReadHeaderTimeout with the max duration for reading the request headers, it mitigates the slowloris attacks,
+// e.g. 5 seconds. Zero falls back to the ReadTimeout, and there is no limit if both are zero, which is the default.
+func ReadHeaderTimeout(timeout time.Duration) ServerOption {
+	return func(o *Server) {
+		o.readHeaderTimeout = timeout
+	}
+}
+
+// ReadTimeout with the max duration for reading the entire request including the body,
+// zero is no limit, which is the default. Prefer the ReadHeaderTimeout for the large uploads.
+func ReadTimeout(timeout time.Duration) ServerOption {
+	return func(o *Server) {
+		o.readTimeout = timeout
+	}
+}
+
+// WriteTimeout with the max duration from the end of reading the request headers to the end of writing the response,
+// zero is no limit, which is the default. Unlike the Timeout which cancels the context of the handler,
+// it closes the connection, so it should be longer than the Timeout, and zero for the streaming replies and WebSockets.
+func WriteTimeout(timeout time.Duration) ServerOption {
+	return func(o *Server) {
+		o.writeTimeout = timeout
+	}
+}
+
+// IdleTimeout with the max duration to wait for the next request on a keep-alive connection, e.g. 120 seconds.
+// Zero falls back to the ReadTimeout, and there is no limit if both are zero, which is the default.
+func IdleTimeout(timeout time.Duration) ServerOption {
+	return func(o *Server) {
+		o.idleTimeout = timeout
+	}
+}
+
+// MaxHeaderBytes with the max size in bytes of the request line and headers, e.g. 64 KiB,
+// the request exceeding it fails with 431 Request Header Fields Too Large. Zero is 1 MiB, which is the default.
+func MaxHeaderBytes(size int) ServerOption {
+	return func(o *Server) {
+		o.maxHeaderBytes = size
+	}
+}
+
+// NotFoundHandler with the handler of the requests which match no route, the returned error is
+// rendered by the ErrorEncoder, default is a 404 NOT_FOUND error.
+func NotFoundHandler(h func(req *http.Request) error) ServerOption {
+	return func(o *Server) {
+		o.notFound = h
+	}
+}
+
+// MethodNotAllowedHandler with the handler of the requests which match a route by the path but not by the method,
+// the returned error is rendered by the ErrorEncoder, default is a 405 METHOD_NOT_ALLOWED error.
+func MethodNotAllowedHandler(h func(req *http.Request) error) ServerOption {
+	return func(o *Server) {
+		o.methodNotAllowed = h
+	}
+}
+
+// AbandonedError is returned by Stop when in-flight requests are abandoned.
+type AbandonedError struct {
+	// Abandoned is the number of the in-flight requests when the server is closed.
+	Abandoned int64
+	Err       error
+}
+
+func (e *AbandonedError) Error() string {
+	return fmt.Sprintf("http server abandoned %d in-flight requests: %v", e.Abandoned, e.Err)
+}
+
+func (e *AbandonedError) Unwrap() error { return e.Err }
+
 // Server is an HTTP server wrapper.
 type Server struct {
 	*http.Server
@@ -118,8 +240,28 @@ type Server struct {
 	dec      DecodeRequestFunc
 	enc      EncodeResponseFunc
 	ene      EncodeErrorFunc
-	router   *mux.Router
-	log      *log.Helper
+	// streamEnc is nil unless the streaming replies are enabled.
+	streamEnc EncodeResponseFunc
+	envelope  func(data interface{}) interface{}
+	router    *mux.Router
+	log       *log.Helper
+	h2c       bool
+	// inflight is the number of the requests being served.
+	inflight        int64
+	shutdownTimeout time.Duration
+	maxBody         int64
+	maxBodySizes    map[string]int64
+	// the timeouts and the max header bytes of the http.Server, zero is the default of net/http.
+	readHeaderTimeout time.Duration
+	readTimeout       time.Duration
+	writeTimeout      time.Duration
+	idleTimeout       time.Duration
+	maxHeaderBytes    int
+	// compressor is nil unless the responses are compressed.
+	compressor *compressor
+	// notFound and methodNotAllowed return the errors of the unmatched requests.
+	notFound         func(req *http.Request) error
+	methodNotAllowed func(req *http.Request) error
 }
 
 // NewServer creates an HTTP server by options.
@@ -132,15 +274,33 @@ func NewServer(opts ...ServerOption) *Server {
 		enc:     DefaultResponseEncoder,
 		ene:     DefaultErrorEncoder,
 		log:     log.NewHelper(log.DefaultLogger),
+
+		notFound:         defaultNotFound,
+		methodNotAllowed: defaultMethodNotAllowed,
 	}
 	for _, o := range opts {
 		o(srv)
 	}
+	handler := FilterChain(srv.filters...)(srv)
+	if srv.compressor != nil {
+		handler = srv.compressor.filter(handler)
+	}
+	handler = srv.track(handler)
+	if srv.h2c && srv.tlsConf == nil {
+		handler = h2c.NewHandler(handler, &http2.Server{})
+	}
 	srv.Server = &http.Server{
-		Handler:   FilterChain(srv.filters...)(srv),
-		TLSConfig: srv.tlsConf,
+		Handler:           handler,
+		TLSConfig:         srv.tlsConf,
+		ReadHeaderTimeout: srv.readHeaderTimeout,
+		ReadTimeout:       srv.readTimeout,
+		WriteTimeout:      srv.writeTimeout,
+		IdleTimeout:       srv.idleTimeout,
+		MaxHeaderBytes:    srv.maxHeaderBytes,
 	}
 	srv.router = mux.NewRouter()
+	srv.router.NotFoundHandler = srv.unmatched(srv.notFound, defaultNotFound)
+	srv.router.MethodNotAllowedHandler = srv.unmatched(srv.methodNotAllowed, defaultMethodNotAllowed)
 	srv.router.Use(srv.filter())
 	return srv
 }
@@ -170,20 +330,42 @@ func (s *Server) ServeHTTP(res http.ResponseWriter, req *http.Request) {
 	s.router.ServeHTTP(res, req)
 }
 
+// track counts the in-flight requests.
+func (s *Server) track(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt64(&s.inflight, 1)
+		defer atomic.AddInt64(&s.inflight, -1)
+		next.ServeHTTP(w, req)
+	})
+}
+
 func (s *Server) filter() mux.MiddlewareFunc {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 			ctx, cancel := context.WithCancel(req.Context())
 			defer cancel()
+			ctx = context.WithValue(ctx, requestContextKey{}, req.Context())
 			if s.timeout > 0 {
 				ctx, cancel = context.WithTimeout(ctx, s.timeout)
 				defer cancel()
 			}
+			// the timeout budget of the caller, which is capped by the server timeout.
+			if timeout, ok := requestTimeout(req); ok {
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
 			pathTemplate := req.URL.Path
 			if route := mux.CurrentRoute(req); route != nil {
 				// /path/123 -> /path/{id}
 				pathTemplate, _ = route.GetPathTemplate()
 			}
+			if limit := s.maxBodySize(pathTemplate); limit > 0 && req.Body != nil {
+				if req.ContentLength > limit {
+					s.ene(w, req, errPayloadTooLarge(limit, req.ContentLength))
+					return
+				}
+				req.Body = newMaxBodyReader(w, req.Body, limit)
+			}
 			tr := &Transport{
 				endpoint:     s.endpoint.String(),
 				operation:    pathTemplate,
@@ -248,8 +430,25 @@ func (s *Server) Start(ctx context.Context) error {
 	return nil
 }
 
-// Stop stop the HTTP server.
+// Stop stop the HTTP server, it waits for in-flight requests until the context is done
+// or the shutdown timeout elapses, and returns an AbandonedError if any request is abandoned.
 func (s *Server) Stop(ctx context.Context) error {
 	s.log.Info("[HTTP] server stopping")
-	return s.Shutdown(ctx)
+	if s.shutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.shutdownTimeout)
+		defer cancel()
+	}
+	err := s.Shutdown(ctx)
+	if err == nil {
+		return nil
+	}
+	abandoned := atomic.LoadInt64(&s.inflight)
+	if cerr := s.Close(); cerr != nil {
+		s.log.Errorf("[HTTP] server close error: %v", cerr)
+	}
+	if abandoned > 0 {
+		return &AbandonedError{Abandoned: abandoned, Err: err}
+	}
+	return err
 }
@@ -8,7 +8,9 @@ import (
 	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
@@ -16,6 +18,7 @@ import (
 
 	"github.com/go-kratos/kratos/v2/internal/host"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/http2"
 )
 
 type testKey struct{}
@@ -215,6 +218,42 @@ func TestResponseEncoder(t *testing.T) {
 	assert.NotNil(t, o.enc)
 }
 
+func TestStreamResponseEncoder(t *testing.T) {
+	o := &Server{}
+	StreamResponseEncoder(DefaultStreamResponseEncoder)(o)
+	assert.NotNil(t, o.streamEnc)
+}
+
+func TestResponseEnvelope(t *testing.T) {
+	o := &Server{}
+	ResponseEnvelope(func(data interface{}) interface{} { return data })(o)
+	assert.NotNil(t, o.envelope)
+}
+
+func TestServerResponseEnvelope(t *testing.T) {
+	srv := NewServer(ResponseEnvelope(func(data interface{}) interface{} {
+		return map[string]interface{}{"code": 0, "data": data}
+	}))
+	if _, err := srv.Endpoint(); err != nil {
+		t.Fatal(err)
+	}
+	srv.Route("/").GET("/data", func(ctx Context) error {
+		return ctx.Result(http.StatusOK, map[string]string{"a": "b"})
+	})
+	srv.Route("/").GET("/error", func(ctx Context) error {
+		return errors.BadRequest("BAD", "bad request")
+	})
+
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/data", nil))
+	assert.Equal(t, "{\"code\":0,\"data\":{\"a\":\"b\"}}", w.Body.String())
+
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/error", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.NotContains(t, w.Body.String(), "\"data\"")
+}
+
 func TestErrorEncoder(t *testing.T) {
 	o := &Server{}
 	v := func(http.ResponseWriter, *http.Request, error) {}
@@ -222,9 +261,117 @@ func TestErrorEncoder(t *testing.T) {
 	assert.NotNil(t, o.ene)
 }
 
+func TestH2C(t *testing.T) {
+	o := &Server{}
+	H2C(true)(o)
+	assert.True(t, o.h2c)
+}
+
+func TestServerH2C(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer(H2C(true))
+	srv.HandleFunc("/index", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.Proto)
+	})
+	e, err := srv.Endpoint()
+	assert.NoError(t, err)
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	defer srv.Stop(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	h2 := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, cfg *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+	}}
+	for client, proto := range map[*http.Client]string{h2: "HTTP/2.0", http.DefaultClient: "HTTP/1.1"} {
+		resp, err := client.Get(fmt.Sprintf("http://%s/index", e.Host))
+		assert.NoError(t, err)
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.NoError(t, err)
+		assert.Equal(t, proto, string(body))
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	o := &Server{}
+	ShutdownTimeout(time.Second)(o)
+	assert.Equal(t, time.Second, o.shutdownTimeout)
+}
+
+func TestServerStopAbandoned(t *testing.T) {
+	ctx := context.Background()
+	release := make(chan struct{})
+	srv := NewServer(ShutdownTimeout(50*time.Millisecond), Timeout(0))
+	srv.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	})
+	e, err := srv.Endpoint()
+	assert.NoError(t, err)
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(100 * time.Millisecond)
+	go http.Get(fmt.Sprintf("http://%s/slow", e.Host))
+	time.Sleep(100 * time.Millisecond)
+
+	err = srv.Stop(ctx)
+	close(release)
+	var ae *AbandonedError
+	assert.True(t, errors.As(err, &ae))
+	assert.Equal(t, int64(1), ae.Abandoned)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
 func TestTLSConfig(t *testing.T) {
 	o := &Server{}
 	v := &tls.Config{}
 	TLSConfig(v)(o)
 	assert.Equal(t, v, o.tlsConf)
 }
+
+func TestServerLimits(t *testing.T) {
+	srv := NewServer(
+		ReadHeaderTimeout(time.Second),
+		ReadTimeout(2*time.Second),
+		WriteTimeout(3*time.Second),
+		IdleTimeout(4*time.Second),
+		MaxHeaderBytes(1024),
+	)
+	assert.Equal(t, time.Second, srv.Server.ReadHeaderTimeout)
+	assert.Equal(t, 2*time.Second, srv.Server.ReadTimeout)
+	assert.Equal(t, 3*time.Second, srv.Server.WriteTimeout)
+	assert.Equal(t, 4*time.Second, srv.Server.IdleTimeout)
+	assert.Equal(t, 1024, srv.Server.MaxHeaderBytes)
+}
+
+func TestServerMaxHeaderBytes(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer(MaxHeaderBytes(1024))
+	srv.HandleFunc("/index", func(w http.ResponseWriter, r *http.Request) {})
+	e, err := srv.Endpoint()
+	assert.NoError(t, err)
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+	defer srv.Stop(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s/index", e.Host), nil)
+	assert.NoError(t, err)
+	req.Header.Set("X-Large", strings.Repeat("a", 8<<10))
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusRequestHeaderFieldsTooLarge, res.StatusCode)
+}
@@ -0,0 +1,95 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+)
+
+const streamBufferSize = 32 * 1024
+
+// isStream reports whether the reply should be streamed.
+func isStream(v interface{}) bool {
+	if _, ok := v.(io.Reader); ok {
+		return true
+	}
+	if v == nil {
+		return false
+	}
+	t := reflect.TypeOf(v)
+	return t.Kind() == reflect.Chan && t.ChanDir()&reflect.RecvDir != 0
+}
+
+// DefaultStreamResponseEncoder streams the io.Reader or the channel reply to the HTTP response,
+// every chunk or channel element is flushed. The elements of a channel are encoded as
+// server-sent events if the request accepts text/event-stream, otherwise as NDJSON.
+// The streaming stops when the reader or the channel is drained or the client disconnects.
+func DefaultStreamResponseEncoder(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	flusher, _ := w.(http.Flusher)
+	flush := func() {
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	if rd, ok := v.(io.Reader); ok {
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", "application/octet-stream")
+		}
+		buf := make([]byte, streamBufferSize)
+		for {
+			if err := r.Context().Err(); err != nil {
+				return err
+			}
+			n, err := rd.Read(buf)
+			if n > 0 {
+				if _, werr := w.Write(buf[:n]); werr != nil {
+					return werr
+				}
+				flush()
+			}
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	sse := strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+	if sse {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+	codec := encoding.GetCodec("json")
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(v)},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(r.Context().Done())},
+	}
+	for {
+		chosen, elem, ok := reflect.Select(cases)
+		if chosen == 1 {
+			return r.Context().Err()
+		}
+		if !ok {
+			return nil
+		}
+		data, err := codec.Marshal(elem.Interface())
+		if err != nil {
+			return err
+		}
+		if sse {
+			data = append(append([]byte("data: "), data...), '\n', '\n')
+		} else {
+			data = append(data, '\n')
+		}
+		if _, err = w.Write(data); err != nil {
+			return err
+		}
+		flush()
+	}
+}
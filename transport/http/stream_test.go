@@ -0,0 +1,87 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsStream(t *testing.T) {
+	assert.True(t, isStream(strings.NewReader("a")))
+	assert.True(t, isStream(make(chan int)))
+	assert.True(t, isStream(make(<-chan int)))
+	assert.False(t, isStream(make(chan<- int)))
+	assert.False(t, isStream(nil))
+	assert.False(t, isStream(&struct{}{}))
+}
+
+func TestDefaultStreamResponseEncoderReader(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	err := DefaultStreamResponseEncoder(w, r, strings.NewReader("hello"))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", w.Body.String())
+	assert.Equal(t, "application/octet-stream", w.Header().Get("Content-Type"))
+	assert.True(t, w.Flushed)
+}
+
+func TestDefaultStreamResponseEncoderChannel(t *testing.T) {
+	ch := make(chan map[string]int, 2)
+	ch <- map[string]int{"a": 1}
+	ch <- map[string]int{"a": 2}
+	close(ch)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	assert.NoError(t, DefaultStreamResponseEncoder(w, r, ch))
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	assert.Equal(t, "{\"a\":1}\n{\"a\":2}\n", w.Body.String())
+
+	ch = make(chan map[string]int, 1)
+	ch <- map[string]int{"a": 1}
+	close(ch)
+	w = httptest.NewRecorder()
+	r.Header.Set("Accept", "text/event-stream")
+	assert.NoError(t, DefaultStreamResponseEncoder(w, r, ch))
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Equal(t, "data: {\"a\":1}\n\n", w.Body.String())
+}
+
+func TestDefaultStreamResponseEncoderCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	err := DefaultStreamResponseEncoder(w, r, make(chan int))
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestServerStreamResponse(t *testing.T) {
+	srv := NewServer(StreamResponseEncoder(DefaultStreamResponseEncoder))
+	if _, err := srv.Endpoint(); err != nil {
+		t.Fatal(err)
+	}
+	srv.Route("/").GET("/stream", func(ctx Context) error {
+		ch := make(chan int, 3)
+		for i := 1; i <= 3; i++ {
+			ch <- i
+		}
+		close(ch)
+		return ctx.Result(http.StatusOK, ch)
+	})
+	srv.Route("/").GET("/plain", func(ctx Context) error {
+		return ctx.Result(http.StatusOK, map[string]string{"a": "b"})
+	})
+
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stream", nil))
+	assert.Equal(t, "1\n2\n3\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/plain", nil))
+	assert.Equal(t, "{\"a\":\"b\"}", w.Body.String())
+}
@@ -0,0 +1,50 @@
+package http
+
+import (
+	"hash/fnv"
+	"os"
+	"sort"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+// subset returns a deterministic subset of the nodes for the client id.
+// It uses rendezvous hashing, so adding or removing a node changes at most
+// one member of the subset, which avoids reconnect storms on node changes.
+func subset(clientID string, nodes []*registry.ServiceInstance, size int) []*registry.ServiceInstance {
+	if size <= 0 || len(nodes) <= size {
+		return nodes
+	}
+	type scored struct {
+		node  *registry.ServiceInstance
+		score uint64
+	}
+	scores := make([]scored, 0, len(nodes))
+	for _, node := range nodes {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(clientID))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(subsetKey(node)))
+		scores = append(scores, scored{node: node, score: h.Sum64()})
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		return scores[i].score > scores[j].score
+	})
+	picked := make([]*registry.ServiceInstance, 0, size)
+	for _, s := range scores[:size] {
+		picked = append(picked, s.node)
+	}
+	return picked
+}
+
+func subsetKey(node *registry.ServiceInstance) string {
+	if node.ID != "" || len(node.Endpoints) == 0 {
+		return node.ID
+	}
+	return node.Endpoints[0]
+}
+
+func defaultSubsetClientID() string {
+	id, _ := os.Hostname()
+	return id
+}
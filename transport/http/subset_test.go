@@ -0,0 +1,45 @@
+package http
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSubsetNodes(n int) []*registry.ServiceInstance {
+	nodes := make([]*registry.ServiceInstance, 0, n)
+	for i := 0; i < n; i++ {
+		nodes = append(nodes, &registry.ServiceInstance{ID: strconv.Itoa(i)})
+	}
+	return nodes
+}
+
+func TestSubset(t *testing.T) {
+	nodes := newSubsetNodes(100)
+	assert.Equal(t, nodes, subset("client", nodes, 0))
+	assert.Equal(t, nodes[:3], subset("client", nodes[:3], 10))
+
+	picked := subset("client", nodes, 10)
+	assert.Len(t, picked, 10)
+	// deterministic regardless of the order.
+	reversed := make([]*registry.ServiceInstance, 0, len(nodes))
+	for i := len(nodes) - 1; i >= 0; i-- {
+		reversed = append(reversed, nodes[i])
+	}
+	assert.Equal(t, picked, subset("client", reversed, 10))
+	assert.NotEqual(t, picked, subset("other", nodes, 10))
+
+	// adding a node changes at most one member.
+	grown := subset("client", append(newSubsetNodes(100), &registry.ServiceInstance{ID: "new"}), 10)
+	same := 0
+	for _, a := range picked {
+		for _, b := range grown {
+			if a.ID == b.ID {
+				same++
+			}
+		}
+	}
+	assert.GreaterOrEqual(t, same, 9)
+}
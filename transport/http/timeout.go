@@ -0,0 +1,93 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// TimeoutHeader is the header of the remaining request timeout, which is formatted
+// as the grpc-timeout header, e.g. 500m. The relative duration is immune to the clock skew of the hosts.
+const TimeoutHeader = "X-Kratos-Timeout"
+
+// DeadlineExceededReason is the error reason when the client call is not done before its deadline,
+// the "phase" metadata reports which phase of the call exceeded it.
+const DeadlineExceededReason = "DEADLINE_EXCEEDED"
+
+// the phases of a client call.
+const (
+	phasePick      = "pick"
+	phaseRoundTrip = "round_trip"
+	phaseDecode    = "decode"
+)
+
+const maxTimeoutValue int64 = 100000000 - 1
+
+var timeoutUnits = []struct {
+	unit byte
+	d    time.Duration
+}{
+	{'n', time.Nanosecond},
+	{'u', time.Microsecond},
+	{'m', time.Millisecond},
+	{'S', time.Second},
+	{'M', time.Minute},
+	{'H', time.Hour},
+}
+
+// encodeTimeout encodes the timeout with the smallest unit which keeps the value within 8 digits,
+// the value is rounded up.
+func encodeTimeout(t time.Duration) string {
+	if t <= 0 {
+		return "0n"
+	}
+	for _, u := range timeoutUnits {
+		if v := (int64(t) + int64(u.d) - 1) / int64(u.d); v <= maxTimeoutValue {
+			return strconv.FormatInt(v, 10) + string(u.unit)
+		}
+	}
+	return strconv.FormatInt(maxTimeoutValue, 10) + "H"
+}
+
+func decodeTimeout(s string) (time.Duration, error) {
+	if len(s) < 2 || len(s) > 9 {
+		return 0, fmt.Errorf("invalid timeout: %q", s)
+	}
+	v, err := strconv.ParseInt(s[:len(s)-1], 10, 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid timeout: %q", s)
+	}
+	for _, u := range timeoutUnits {
+		if u.unit == s[len(s)-1] {
+			return time.Duration(v) * u.d, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid timeout unit: %q", s)
+}
+
+// requestTimeout returns the timeout carried by the request header.
+func requestTimeout(req *http.Request) (time.Duration, bool) {
+	v := req.Header.Get(TimeoutHeader)
+	if v == "" {
+		return 0, false
+	}
+	d, err := decodeTimeout(v)
+	if err != nil {
+		return 0, false
+	}
+	return d, true
+}
+
+// deadlineError returns a gateway timeout error of the phase, which keeps context.DeadlineExceeded as its cause.
+func deadlineError(phase string) error {
+	return &resolveError{
+		err: errors.GatewayTimeout(DeadlineExceededReason, "deadline exceeded during "+phase).WithMetadata(map[string]string{
+			"phase": phase,
+		}),
+		cause: context.DeadlineExceeded,
+	}
+}
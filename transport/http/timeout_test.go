@@ -0,0 +1,210 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/transport/http/balancer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeTimeout(t *testing.T) {
+	tests := []struct {
+		d time.Duration
+		s string
+	}{
+		{0, "0n"},
+		{time.Nanosecond, "1n"},
+		{500 * time.Millisecond, "500000u"},
+		{time.Minute, "60000000u"},
+		{2 * time.Minute, "120000m"},
+		{1500 * time.Microsecond, "1500000n"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.s, encodeTimeout(test.d))
+		if test.d > 0 {
+			d, err := decodeTimeout(test.s)
+			assert.NoError(t, err)
+			assert.Equal(t, test.d, d)
+		}
+	}
+}
+
+func TestDecodeTimeout(t *testing.T) {
+	for _, s := range []string{"", "1", "1x", "-1m", "123456789m", "am"} {
+		_, err := decodeTimeout(s)
+		assert.Error(t, err, s)
+	}
+	d, err := decodeTimeout("2H")
+	assert.NoError(t, err)
+	assert.Equal(t, 2*time.Hour, d)
+}
+
+func TestServerTimeoutHeader(t *testing.T) {
+	srv := NewServer(Timeout(time.Second))
+	if _, err := srv.Endpoint(); err != nil {
+		t.Fatal(err)
+	}
+	var remaining time.Duration
+	srv.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		deadline, _ := r.Context().Deadline()
+		remaining = time.Until(deadline)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(TimeoutHeader, "100m")
+	srv.ServeHTTP(httptest.NewRecorder(), req)
+	assert.True(t, remaining <= 100*time.Millisecond)
+
+	// the budget is capped by the server timeout.
+	req.Header.Set(TimeoutHeader, "10S")
+	srv.ServeHTTP(httptest.NewRecorder(), req)
+	assert.True(t, remaining <= time.Second && remaining > 500*time.Millisecond)
+}
+
+func TestClientTimeoutHeader(t *testing.T) {
+	var header string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get(TimeoutHeader)
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer srv.Close()
+	client, err := NewClient(context.Background(), WithEndpoint(strings.TrimPrefix(srv.URL, "http://")), WithTimeout(time.Second))
+	assert.NoError(t, err)
+
+	reply := make(map[string]string)
+	assert.NoError(t, client.Invoke(context.Background(), http.MethodGet, "/", nil, &reply))
+	d, err := decodeTimeout(header)
+	assert.NoError(t, err)
+	// the timeout elapses from the beginning of the call.
+	assert.True(t, d <= time.Second && d > 500*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	assert.NoError(t, client.Invoke(ctx, http.MethodGet, "/", nil, &reply))
+	d, err = decodeTimeout(header)
+	assert.NoError(t, err)
+	assert.True(t, d <= 200*time.Millisecond)
+}
+
+// waitBalancer picks no node until the context is done.
+type waitBalancer struct{}
+
+func (waitBalancer) Pick(ctx context.Context) (*registry.ServiceInstance, func(context.Context, balancer.DoneInfo), error) {
+	<-ctx.Done()
+	return nil, nil, ctx.Err()
+}
+
+func (waitBalancer) Update([]*registry.ServiceInstance) {}
+
+func TestClientTimeoutPhase(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/decode" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"a":`))
+			w.(http.Flusher).Flush()
+		}
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	client, err := NewClient(context.Background(), WithEndpoint(strings.TrimPrefix(srv.URL, "http://")), WithTimeout(50*time.Millisecond))
+	assert.NoError(t, err)
+
+	assertPhase := func(err error, phase string) {
+		t.Helper()
+		assert.True(t, errors.IsGatewayTimeout(err))
+		assert.Equal(t, DeadlineExceededReason, errors.Reason(err))
+		assert.Equal(t, phase, errors.FromError(err).Metadata["phase"])
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	}
+	reply := make(map[string]string)
+	assertPhase(client.Invoke(context.Background(), http.MethodGet, "/", nil, &reply), phaseRoundTrip)
+	assertPhase(client.Invoke(context.Background(), http.MethodGet, "/decode", nil, &reply), phaseDecode)
+
+	// the earlier deadline of the call context applies.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	assertPhase(client.Invoke(ctx, http.MethodGet, "/", nil, &reply), phaseRoundTrip)
+	assert.Less(t, int64(time.Since(start)), int64(50*time.Millisecond))
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	assert.NoError(t, err)
+	_, err = client.Do(req)
+	assertPhase(err, phaseRoundTrip)
+
+	w := newMockWatcher()
+	w.push(&registry.ServiceInstance{ID: "1", Endpoints: []string{srv.URL}})
+	client, err = NewClient(context.Background(),
+		WithEndpoint("discovery:///demo"),
+		WithDiscovery(&mockWatchDiscovery{w: w}),
+		WithBalancer(waitBalancer{}),
+		WithTimeout(50*time.Millisecond),
+	)
+	assert.NoError(t, err)
+	defer client.Close()
+	assertPhase(client.Invoke(context.Background(), http.MethodGet, "/", nil, &reply), phasePick)
+}
+
+func TestClientDoTimeout(t *testing.T) {
+	release, stall := make(chan struct{}), make(chan struct{})
+	defer close(stall)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello "))
+		w.(http.Flusher).Flush()
+		wait := release
+		if r.URL.Path == "/stall" {
+			wait = stall
+		}
+		select {
+		case <-wait:
+			_, _ = w.Write([]byte("kratos"))
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+
+	// the default timeout does not bound the Do calls, whose body is read by the caller.
+	var deadline bool
+	client, err := NewClient(context.Background(),
+		WithEndpoint(strings.TrimPrefix(srv.URL, "http://")),
+		WithRequestInterceptor(func(req *http.Request) error {
+			_, deadline = req.Context().Deadline()
+			return nil
+		}),
+	)
+	assert.NoError(t, err)
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	assert.NoError(t, err)
+	res, err := client.Do(req)
+	assert.NoError(t, err)
+	assert.False(t, deadline)
+	time.AfterFunc(100*time.Millisecond, func() { close(release) })
+	body, err := ioutil.ReadAll(res.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello kratos", string(body))
+	assert.NoError(t, res.Body.Close())
+
+	// the timeout set by WithTimeout bounds the reading of the body.
+	client, err = NewClient(context.Background(), WithEndpoint(strings.TrimPrefix(srv.URL, "http://")), WithTimeout(50*time.Millisecond))
+	assert.NoError(t, err)
+	req, err = http.NewRequest(http.MethodGet, srv.URL+"/stall", nil)
+	assert.NoError(t, err)
+	res, err = client.Do(req)
+	assert.NoError(t, err)
+	_, err = ioutil.ReadAll(res.Body)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NoError(t, res.Body.Close())
+}
@@ -56,6 +56,17 @@ func (tr *Transport) PathTemplate() string {
 	return tr.pathTemplate
 }
 
+// RoutePattern returns the route pattern matched by the server request in ctx, e.g. "/users/{id}" for "/users/123",
+// which keeps the cardinality of the metric labels bounded unlike the request path.
+func RoutePattern(ctx context.Context) (string, bool) {
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		if tr, ok := tr.(*Transport); ok && tr.pathTemplate != "" {
+			return tr.pathTemplate, true
+		}
+	}
+	return "", false
+}
+
 // SetOperation sets the transport operation.
 func SetOperation(ctx context.Context, op string) {
 	if tr, ok := transport.FromServerContext(ctx); ok {
@@ -1,7 +1,9 @@
 package http
 
 import (
+	"context"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/go-kratos/kratos/v2/transport"
@@ -57,3 +59,24 @@ func TestHeaderCarrier_Keys(t *testing.T) {
 	v.Set("bcc", "2")
 	assert.ElementsMatch(t, []string{"Abb", "Bcc"}, v.Keys())
 }
+
+func TestRoutePattern(t *testing.T) {
+	srv := NewServer()
+	_, err := srv.Endpoint()
+	assert.NoError(t, err)
+	defer srv.lis.Close()
+	var (
+		pattern string
+		ok      bool
+	)
+	srv.Route("/v1").GET("/users/{id}", func(ctx Context) error {
+		pattern, ok = RoutePattern(ctx)
+		return nil
+	})
+	srv.Handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/users/123", nil))
+	assert.True(t, ok)
+	assert.Equal(t, "/v1/users/{id}", pattern)
+
+	_, ok = RoutePattern(context.Background())
+	assert.False(t, ok)
+}
@@ -0,0 +1,36 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// unixAuthority is the host of the requests over a Unix domain socket.
+const unixAuthority = "localhost"
+
+// unixTarget returns the target of the requests over the Unix domain socket of the unix target,
+// the socket is dialed by the transport, so the registry resolver is bypassed.
+func unixTarget(target *Target, insecure bool) *Target {
+	scheme := "https"
+	if insecure {
+		scheme = "http"
+	}
+	return &Target{Scheme: scheme, Authority: unixAuthority, Endpoint: target.Endpoint}
+}
+
+// unixTransport returns a copy of the transport which dials the Unix domain socket for every connection,
+// the TLS config and the connection pool settings of the transport are kept.
+func unixTransport(rt http.RoundTripper, socket string) (http.RoundTripper, error) {
+	tr, ok := rt.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("[http client] unix target requires an *http.Transport, got %T", rt)
+	}
+	tr = tr.Clone()
+	var dialer net.Dialer
+	tr.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return dialer.DialContext(ctx, "unix", socket)
+	}
+	return tr, nil
+}
@@ -0,0 +1,39 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnixTarget(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kratos")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "app.sock")
+	lis, err := net.Listen("unix", socket)
+	assert.NoError(t, err)
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"host":"` + r.Host + `","path":"` + r.URL.Path + `"}`))
+	})}
+	go srv.Serve(lis)
+	defer srv.Close()
+
+	client, err := NewClient(context.Background(), WithEndpoint("unix://"+socket), WithDiscovery(&mockDiscovery{}))
+	assert.NoError(t, err)
+	assert.Nil(t, client.r)
+	assert.NotEqual(t, http.DefaultTransport, client.cc.Transport)
+	reply := map[string]string{}
+	assert.NoError(t, client.Invoke(context.Background(), http.MethodGet, "/hello", nil, &reply))
+	assert.Equal(t, map[string]string{"host": unixAuthority, "path": "/hello"}, reply)
+
+	_, err = NewClient(context.Background(), WithEndpoint("unix://"+socket), WithTransport(&mockRoundTripper{}))
+	assert.Error(t, err)
+}
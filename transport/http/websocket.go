@@ -0,0 +1,123 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	"golang.org/x/net/websocket"
+)
+
+// ErrWebSocketUnsupported is returned by Upgrade if the response writer can not be hijacked.
+var ErrWebSocketUnsupported = errors.New("http: websocket upgrade is not supported by the response writer")
+
+// WebSocketHandler handles an upgraded WebSocket connection.
+type WebSocketHandler func(*WebSocket) error
+
+// WebSocket is an upgraded WebSocket connection.
+type WebSocket struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	conn   *websocket.Conn
+}
+
+// Context returns the context of the connection, it carries the values set by the middleware,
+// and it is canceled when the request is canceled, the connection is closed or a read fails.
+func (ws *WebSocket) Context() context.Context {
+	return ws.ctx
+}
+
+// ReadMessage reads the payload of the next text or binary message.
+func (ws *WebSocket) ReadMessage() ([]byte, error) {
+	var data []byte
+	if err := websocket.Message.Receive(ws.conn, &data); err != nil {
+		ws.cancel()
+		return nil, err
+	}
+	return data, nil
+}
+
+// WriteMessage writes the data as a binary message.
+func (ws *WebSocket) WriteMessage(data []byte) error {
+	return websocket.Message.Send(ws.conn, data)
+}
+
+// ReadJSON reads the next message and decodes it as JSON into v.
+func (ws *WebSocket) ReadJSON(v interface{}) error {
+	data, err := ws.ReadMessage()
+	if err != nil {
+		return err
+	}
+	return encoding.GetCodec("json").Unmarshal(data, v)
+}
+
+// WriteJSON encodes v as JSON and writes it as a text message.
+func (ws *WebSocket) WriteJSON(v interface{}) error {
+	data, err := encoding.GetCodec("json").Marshal(v)
+	if err != nil {
+		return err
+	}
+	return websocket.Message.Send(ws.conn, string(data))
+}
+
+// Close closes the connection and cancels its context.
+func (ws *WebSocket) Close() error {
+	ws.cancel()
+	return ws.conn.Close()
+}
+
+// Upgrade runs the server and route middleware, then upgrades the connection to a WebSocket
+// and serves it by the handler, e.g. r.GET("/ws", func(ctx Context) error { return Upgrade(ctx, h) }).
+// The error of the middleware is returned to be encoded as usual, but since the response is
+// taken over once upgraded, the error of the handler is only logged, and the response encoder
+// must not be used afterwards. The connection is closed when the handler returns.
+func Upgrade(ctx Context, h WebSocketHandler) error {
+	res := ctx.Response()
+	if _, ok := res.(http.Hijacker); !ok {
+		return ErrWebSocketUnsupported
+	}
+	next := ctx.Middleware(func(c context.Context, _ interface{}) (interface{}, error) {
+		websocket.Server{Handler: func(conn *websocket.Conn) {
+			// the connection outlives the server timeout, which bounds the handshake only.
+			c, cancel := context.WithCancel(connContext(c))
+			ws := &WebSocket{ctx: c, cancel: cancel, conn: conn}
+			defer cancel()
+			if err := h(ws); err != nil {
+				logWebSocket(ctx, err)
+			}
+		}}.ServeHTTP(res, ctx.Request())
+		return nil, nil
+	})
+	_, err := next(ctx, nil)
+	return err
+}
+
+// requestContextKey is the key of the request context without the deadlines of the server.
+type requestContextKey struct{}
+
+// connContext returns the context which carries the values of the middleware context,
+// and is canceled with the request, but not by the deadlines of the server timeout.
+func connContext(c context.Context) context.Context {
+	base, ok := c.Value(requestContextKey{}).(context.Context)
+	if !ok {
+		return c
+	}
+	return valuesContext{Context: base, values: c}
+}
+
+// valuesContext is the Context whose values are read from the values context.
+type valuesContext struct {
+	context.Context
+	values context.Context
+}
+
+func (c valuesContext) Value(key interface{}) interface{} {
+	return c.values.Value(key)
+}
+
+func logWebSocket(ctx Context, err error) {
+	if w, ok := ctx.(*wrapper); ok {
+		w.router.srv.log.Errorf("[HTTP] websocket %s: %v", ctx.Request().URL.Path, err)
+	}
+}
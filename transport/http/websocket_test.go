@@ -0,0 +1,128 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+)
+
+type wsKey struct{}
+
+func TestUpgrade(t *testing.T) {
+	auth := func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if ctx.(Context).Request().URL.Query().Get("token") != "secret" {
+				return nil, errors.Unauthorized("UNAUTHORIZED", "invalid token")
+			}
+			return handler(context.WithValue(ctx, wsKey{}, "alice"), req)
+		}
+	}
+	srv := NewServer(Middleware(auth))
+	_, err := srv.Endpoint()
+	assert.NoError(t, err)
+	defer srv.lis.Close()
+	srv.Route("/").GET("/ws", func(ctx Context) error {
+		return Upgrade(ctx, func(ws *WebSocket) error {
+			var msg map[string]string
+			if err := ws.ReadJSON(&msg); err != nil {
+				return err
+			}
+			msg["user"] = ws.Context().Value(wsKey{}).(string)
+			return ws.WriteJSON(msg)
+		})
+	})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/ws")
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	conn, err := websocket.Dial("ws"+ts.URL[len("http"):]+"/ws?token=secret", "", ts.URL)
+	assert.NoError(t, err)
+	defer conn.Close()
+	assert.NoError(t, websocket.JSON.Send(conn, map[string]string{"hello": "world"}))
+	var reply map[string]string
+	assert.NoError(t, websocket.JSON.Receive(conn, &reply))
+	assert.Equal(t, map[string]string{"hello": "world", "user": "alice"}, reply)
+}
+
+func TestUpgradeUnsupported(t *testing.T) {
+	srv := NewServer()
+	_, err := srv.Endpoint()
+	assert.NoError(t, err)
+	defer srv.lis.Close()
+	srv.Route("/").GET("/ws", func(ctx Context) error {
+		err = Upgrade(ctx, func(ws *WebSocket) error { return nil })
+		return err
+	})
+	srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ws", nil))
+	assert.Equal(t, ErrWebSocketUnsupported, err)
+}
+
+func TestUpgradeCompression(t *testing.T) {
+	srv := NewServer(Compression(0, 1))
+	_, err := srv.Endpoint()
+	assert.NoError(t, err)
+	defer srv.lis.Close()
+	srv.Route("/").GET("/ws", func(ctx Context) error {
+		return Upgrade(ctx, func(ws *WebSocket) error {
+			var msg string
+			if err := ws.ReadJSON(&msg); err != nil {
+				return err
+			}
+			return ws.WriteJSON(msg)
+		})
+	}, ETag())
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	cfg, err := websocket.NewConfig("ws"+ts.URL[len("http"):]+"/ws", ts.URL)
+	assert.NoError(t, err)
+	cfg.Header.Set("Accept-Encoding", "gzip")
+	conn, err := websocket.DialConfig(cfg)
+	assert.NoError(t, err)
+	defer conn.Close()
+	assert.NoError(t, websocket.JSON.Send(conn, "kratos"))
+	var reply string
+	assert.NoError(t, websocket.JSON.Receive(conn, &reply))
+	assert.Equal(t, "kratos", reply)
+}
+
+func TestUpgradeTimeout(t *testing.T) {
+	srv := NewServer(Timeout(50 * time.Millisecond))
+	_, err := srv.Endpoint()
+	assert.NoError(t, err)
+	defer srv.lis.Close()
+	srv.Route("/").GET("/ws", func(ctx Context) error {
+		return Upgrade(ctx, func(ws *WebSocket) error {
+			var msg string
+			if err := ws.ReadJSON(&msg); err != nil {
+				return err
+			}
+			if err := ws.Context().Err(); err != nil {
+				return ws.WriteJSON(err.Error())
+			}
+			return ws.WriteJSON(msg)
+		})
+	})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	conn, err := websocket.Dial("ws"+ts.URL[len("http"):]+"/ws", "", ts.URL)
+	assert.NoError(t, err)
+	defer conn.Close()
+	time.Sleep(150 * time.Millisecond)
+	assert.NoError(t, websocket.JSON.Send(conn, "kratos"))
+	var reply string
+	assert.NoError(t, websocket.JSON.Receive(conn, &reply))
+	assert.Equal(t, "kratos", reply)
+}